@@ -7,18 +7,30 @@ import (
 	"os"
 
 	"github.com/BabyLev/Umka-1/internal/clients/r4uab"
+	"github.com/BabyLev/Umka-1/internal/clients/webhook"
 	"github.com/BabyLev/Umka-1/internal/config"
+	"github.com/BabyLev/Umka-1/internal/ephemeris"
 	"github.com/BabyLev/Umka-1/internal/jobs"
+	geofencesRepo "github.com/BabyLev/Umka-1/internal/repo/geofences"
 	locationsRepo "github.com/BabyLev/Umka-1/internal/repo/locations"
+	rotatorsRepo "github.com/BabyLev/Umka-1/internal/repo/rotators"
 	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
+	tleHistoryRepo "github.com/BabyLev/Umka-1/internal/repo/tlehistory"
 	"github.com/BabyLev/Umka-1/internal/router"
 	"github.com/BabyLev/Umka-1/internal/service"
 	"github.com/BabyLev/Umka-1/internal/storage"
+	"github.com/BabyLev/Umka-1/internal/tracking"
+	"github.com/BabyLev/Umka-1/satellite"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
+	// umka validate - контрольные проверки расчетов без запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate())
+	}
+
 	ctx := context.Background()
 
 	cfg, err := config.New()
@@ -26,6 +38,11 @@ func main() {
 		log.Fatal().Err(err).Msg("Config load err")
 	}
 
+	profile, err := satellite.ParseProfile(cfg.SGP4Profile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("SGP4_PROFILE parse err")
+	}
+
 	storage := storage.New()
 
 	pool, err := pgxpool.New(ctx, cfg.PgConnStr)
@@ -45,12 +62,17 @@ func main() {
 
 	repoSats := satellitesRepo.New(pool)
 	repoLocs := locationsRepo.New(pool)
+	repoRots := rotatorsRepo.New(pool)
+	repoTLEs := tleHistoryRepo.New(pool)
+	repoFences := geofencesRepo.New(pool)
 
 	r4uabClient := r4uab.New(cfg.R4uabURL)
-	service := service.New(r4uabClient, repoSats, repoLocs)
+	tracker := tracking.New()
+	ephemerisCache := ephemeris.New()
+	service := service.New(r4uabClient, repoSats, repoLocs, repoRots, repoTLEs, repoFences, tracker, ephemerisCache, profile)
 	router := router.SetupRouter(service)
 
-	jobs := jobs.New(storage, r4uabClient, repoSats)
+	jobs := jobs.New(storage, r4uabClient, webhook.New(), repoSats, repoFences, profile)
 	go jobs.Start(ctx)
 
 	fmt.Printf("Server running on localhost:%d\n", cfg.HTTPPort)
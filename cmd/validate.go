@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// validate выполняет контрольные проверки пакета satellite (SGP4-VER, преобразование координат, пролеты)
+// и печатает расхождения. Возвращает код завершения: 1, если хотя бы одна проверка не прошла.
+func validate() int {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CASE\tQUANTITY\tDELTA\tTOLERANCE\tUNIT\tRESULT")
+
+	failed := 0
+	for _, c := range satellite.Verify() {
+		result := "ok"
+		if !c.Passed() {
+			result = "FAIL"
+			failed++
+		}
+		if c.Error != "" {
+			result += ": " + c.Error
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%.3e\t%.0e\t%s\t%s\n", c.Name, c.Quantity, c.Delta, c.Tolerance, c.Unit, result)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		fmt.Printf("не прошло проверок: %d\n", failed)
+		return 1
+	}
+
+	fmt.Println("все проверки прошли")
+
+	return 0
+}
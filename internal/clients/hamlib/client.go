@@ -0,0 +1,141 @@
+package hamlib
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Клиент текстового протокола демонов Hamlib (rotctld, rigctld): команда - строка, ответ - одна или
+// несколько строк либо код "RPRT n"
+// https://hamlib.sourceforge.net/html/rotctld.1.html
+
+type Client struct {
+	name    string // имя демона для сообщений об ошибках: "rotctld", "rigctld"
+	addr    string // "localhost:4533"
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func New(name, addr string) *Client {
+	return &Client{
+		name:    name,
+		addr:    addr,
+		timeout: 5 * time.Second,
+	}
+}
+
+// Connect устанавливает TCP соединение с демоном
+func (c *Client) Connect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		return nil
+	}
+
+	dialer := net.Dialer{Timeout: c.timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к %s %s: %w", c.name, c.addr, err)
+	}
+
+	c.conn = conn
+	c.rd = bufio.NewReader(conn)
+
+	return nil
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+
+	// q - закрыть соединение со стороны демона, ответ не ожидается
+	c.conn.Write([]byte("q\n"))
+	err := c.conn.Close()
+	c.conn = nil
+	c.rd = nil
+
+	return err
+}
+
+// Command отправляет команду и читает n строк ответа.
+// Если первой строкой пришел код ошибки RPRT, остальные строки не читаются.
+func (c *Client) Command(ctx context.Context, cmd string, n int) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil, fmt.Errorf("нет соединения с %s", c.name)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.timeout)
+	}
+	c.conn.SetDeadline(deadline)
+
+	_, err := c.conn.Write([]byte(cmd + "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отправки команды %q: %w", cmd, err)
+	}
+
+	lines := make([]string, 0, n)
+	for len(lines) < n {
+		line, err := c.rd.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения ответа на команду %q: %w", cmd, err)
+		}
+
+		line = strings.TrimSpace(line)
+		lines = append(lines, line)
+
+		if IsReport(line) {
+			break
+		}
+	}
+
+	return lines, nil
+}
+
+// Set отправляет команду, на которую демон отвечает только кодом "RPRT n"
+func (c *Client) Set(ctx context.Context, cmd string) error {
+	lines, err := c.Command(ctx, cmd, 1)
+	if err != nil {
+		return err
+	}
+
+	return c.CheckReport(lines[0])
+}
+
+// CheckReport разбирает строку "RPRT n", где n = 0 - успех, n < 0 - код ошибки Hamlib
+func (c *Client) CheckReport(line string) error {
+	code, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "RPRT")))
+	if err != nil {
+		return fmt.Errorf("неожиданный ответ %s: %q", c.name, line)
+	}
+
+	if code != 0 {
+		return fmt.Errorf("%s вернул ошибку RPRT %d", c.name, code)
+	}
+
+	return nil
+}
+
+// IsReport - строка ответа является кодом "RPRT n", а не значением
+func IsReport(line string) bool {
+	return strings.HasPrefix(line, "RPRT")
+}
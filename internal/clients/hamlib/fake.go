@@ -0,0 +1,118 @@
+package hamlib
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Handler отвечает на команду fake-сервера: fields - команда и ее аргументы, возвращает ответ
+// целиком (строки с переводом строки). ok = false - команда не поддерживается.
+type Handler func(fields []string) (reply string, ok bool)
+
+// FakeServer - встроенный сервер протокола Hamlib для тестов и отладки без настоящего оборудования.
+// Сам сервер принимает соединения, записывает команды, обрабатывает q и имитирует ошибку (SetError),
+// остальные команды передаются в Handler.
+type FakeServer struct {
+	ln      net.Listener
+	handler Handler
+
+	mu       sync.Mutex
+	errCode  int
+	commands []string
+}
+
+// NewFakeServer запускает сервер на addr, например "127.0.0.1:0" (свободный порт)
+func NewFakeServer(addr string, handler Handler) (*FakeServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FakeServer{
+		ln:      ln,
+		handler: handler,
+	}
+
+	go f.serve()
+
+	return f, nil
+}
+
+func (f *FakeServer) Addr() string {
+	return f.ln.Addr().String()
+}
+
+func (f *FakeServer) Close() error {
+	return f.ln.Close()
+}
+
+// SetError заставляет сервер отвечать на все команды "RPRT code" (код ошибки Hamlib, например -5 -
+// RIG_ETIMEOUT). 0 - отвечать как обычно.
+func (f *FakeServer) SetError(code int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errCode = code
+}
+
+// Commands возвращает все полученные сервером команды
+func (f *FakeServer) Commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.commands...)
+}
+
+func (f *FakeServer) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go f.handle(conn)
+	}
+}
+
+func (f *FakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	rd := bufio.NewReader(conn)
+
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.TrimSpace(line))
+		errCode := f.errCode
+		f.mu.Unlock()
+
+		if fields[0] == "q" || fields[0] == "Q" {
+			return
+		}
+
+		if errCode != 0 {
+			fmt.Fprintf(conn, "RPRT %d\n", errCode)
+			continue
+		}
+
+		reply, ok := f.handler(fields)
+		if !ok {
+			// -4 - RIG_ENIMPL, команда не реализована
+			reply = "RPRT -4\n"
+		}
+
+		fmt.Fprint(conn, reply)
+	}
+}
@@ -0,0 +1,46 @@
+package rigctld
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/BabyLev/Umka-1/internal/clients/hamlib"
+)
+
+// Клиент для управления радиостанцией по протоколу Hamlib rigctld (TCP, текстовые команды)
+// https://hamlib.sourceforge.net/html/rigctld.1.html
+
+type Client struct {
+	*hamlib.Client
+}
+
+func New(addr string) *Client {
+	return &Client{
+		Client: hamlib.New("rigctld", addr), // "localhost:4532"
+	}
+}
+
+// SetFrequency отправляет команду "F hz" и ждет ответ "RPRT 0"
+func (c *Client) SetFrequency(ctx context.Context, hz int64) error {
+	return c.Set(ctx, fmt.Sprintf("F %d", hz))
+}
+
+// GetFrequency отправляет команду "f" и возвращает текущую частоту, Гц
+func (c *Client) GetFrequency(ctx context.Context) (int64, error) {
+	lines, err := c.Command(ctx, "f", 1)
+	if err != nil {
+		return 0, err
+	}
+
+	if hamlib.IsReport(lines[0]) {
+		return 0, c.CheckReport(lines[0])
+	}
+
+	hz, err := strconv.ParseFloat(lines[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("некорректная частота в ответе rigctld %q: %w", lines[0], err)
+	}
+
+	return int64(hz), nil
+}
@@ -0,0 +1,90 @@
+package rigctld
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func connect(t *testing.T) (*FakeServer, *Client) {
+	t.Helper()
+
+	fake, err := NewFakeServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fake.Close() })
+
+	client := New(fake.Addr())
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return fake, client
+}
+
+func TestSetGetFrequency(t *testing.T) {
+	fake, client := connect(t)
+	ctx := context.Background()
+
+	if err := client.SetFrequency(ctx, 145_800_000); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetFrequency(ctx, 145_803_512); err != nil {
+		t.Fatal(err)
+	}
+
+	if hz := fake.Frequency(); hz != 145_803_512 {
+		t.Fatalf("частота радиостанции %d, ожидалась 145803512", hz)
+	}
+
+	hz, err := client.GetFrequency(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hz != 145_803_512 {
+		t.Fatalf("прочитана частота %d, ожидалась 145803512", hz)
+	}
+
+	want := []string{"F 145800000", "F 145803512", "f"}
+	if got := fake.Commands(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("команды %q, ожидались %q", got, want)
+	}
+}
+
+func TestSetFrequencyInvalid(t *testing.T) {
+	_, client := connect(t)
+
+	// сервер отвечает RPRT -1 на неположительную частоту
+	err := client.SetFrequency(context.Background(), 0)
+	if err == nil || !strings.Contains(err.Error(), "RPRT -1") {
+		t.Fatalf("ошибка %v, ожидалась RPRT -1", err)
+	}
+}
+
+func TestReportError(t *testing.T) {
+	fake, client := connect(t)
+	ctx := context.Background()
+
+	fake.SetError(-5)
+
+	err := client.SetFrequency(ctx, 437_500_000)
+	if err == nil || !strings.Contains(err.Error(), "RPRT -5") {
+		t.Fatalf("SetFrequency: ошибка %v, ожидалась RPRT -5", err)
+	}
+
+	_, err = client.GetFrequency(ctx)
+	if err == nil || !strings.Contains(err.Error(), "RPRT -5") {
+		t.Fatalf("GetFrequency: ошибка %v, ожидалась RPRT -5", err)
+	}
+
+	// после ошибки соединение остается рабочим
+	fake.SetError(0)
+	if err := client.SetFrequency(ctx, 437_500_000); err != nil {
+		t.Fatal(err)
+	}
+	if hz := fake.Frequency(); hz != 437_500_000 {
+		t.Fatalf("частота радиостанции %d, ожидалась 437500000", hz)
+	}
+}
@@ -0,0 +1,66 @@
+package rigctld
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/BabyLev/Umka-1/internal/clients/hamlib"
+)
+
+// FakeServer - встроенный rigctld сервер для тестов и отладки без настоящей радиостанции.
+// Поддерживает команды F, f, q и их длинные формы \set_freq, \get_freq. SetError имитирует ошибку
+// радиостанции.
+type FakeServer struct {
+	*hamlib.FakeServer
+
+	mu   sync.Mutex
+	freq int64
+}
+
+// NewFakeServer запускает сервер на addr, например "127.0.0.1:0" (свободный порт)
+func NewFakeServer(addr string) (*FakeServer, error) {
+	f := &FakeServer{}
+
+	srv, err := hamlib.NewFakeServer(addr, f.command)
+	if err != nil {
+		return nil, err
+	}
+
+	f.FakeServer = srv
+
+	return f, nil
+}
+
+// Frequency возвращает текущую частоту радиостанции, Гц
+func (f *FakeServer) Frequency() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.freq
+}
+
+func (f *FakeServer) command(fields []string) (string, bool) {
+	switch fields[0] {
+	case "F", `\set_freq`:
+		if len(fields) != 2 {
+			return "RPRT -1\n", true
+		}
+
+		hz, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || hz <= 0 {
+			return "RPRT -1\n", true
+		}
+
+		f.mu.Lock()
+		f.freq = int64(hz)
+		f.mu.Unlock()
+
+		return "RPRT 0\n", true
+
+	case "f", `\get_freq`:
+		return fmt.Sprintf("%d\n", f.Frequency()), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,56 @@
+package rotctld
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/BabyLev/Umka-1/internal/clients/hamlib"
+)
+
+// Клиент для управления ротатором по протоколу Hamlib rotctld (TCP, текстовые команды)
+// https://hamlib.sourceforge.net/html/rotctld.1.html
+
+type Client struct {
+	*hamlib.Client
+}
+
+func New(addr string) *Client {
+	return &Client{
+		Client: hamlib.New("rotctld", addr), // "localhost:4533"
+	}
+}
+
+// SetPosition отправляет команду "P az el" и ждет ответ "RPRT 0"
+func (c *Client) SetPosition(ctx context.Context, az, el float64) error {
+	return c.Set(ctx, fmt.Sprintf("P %.2f %.2f", az, el))
+}
+
+// GetPosition отправляет команду "p" и возвращает текущее положение ротатора
+func (c *Client) GetPosition(ctx context.Context) (float64, float64, error) {
+	lines, err := c.Command(ctx, "p", 2)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if hamlib.IsReport(lines[0]) {
+		return 0, 0, c.CheckReport(lines[0])
+	}
+
+	az, err := strconv.ParseFloat(lines[0], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("некорректный азимут в ответе rotctld %q: %w", lines[0], err)
+	}
+
+	el, err := strconv.ParseFloat(lines[1], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("некорректный угол места в ответе rotctld %q: %w", lines[1], err)
+	}
+
+	return az, el, nil
+}
+
+// Stop останавливает вращение ротатора
+func (c *Client) Stop(ctx context.Context) error {
+	return c.Set(ctx, "S")
+}
@@ -0,0 +1,114 @@
+package rotctld
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func connect(t *testing.T) (*FakeServer, *Client) {
+	t.Helper()
+
+	fake, err := NewFakeServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fake.Close() })
+
+	client := New(fake.Addr())
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return fake, client
+}
+
+func TestSetGetPosition(t *testing.T) {
+	fake, client := connect(t)
+	ctx := context.Background()
+
+	if err := client.SetPosition(ctx, 123.456, 45.5); err != nil {
+		t.Fatal(err)
+	}
+
+	az, el, err := client.GetPosition(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// команда отправляется с точностью до сотых
+	if az != 123.46 || el != 45.5 {
+		t.Fatalf("положение %.2f/%.2f, ожидалось 123.46/45.50", az, el)
+	}
+
+	if err := client.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"P 123.46 45.50", "p", "S"}
+	if got := fake.Commands(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("команды %q, ожидались %q", got, want)
+	}
+}
+
+func TestSlewRate(t *testing.T) {
+	fake, client := connect(t)
+	fake.SlewRate = 1000
+	ctx := context.Background()
+
+	if err := client.SetPosition(ctx, 90, 30); err != nil {
+		t.Fatal(err)
+	}
+
+	// сразу после команды ротатор еще в пути, через 200 мс - на месте
+	az, el, err := client.GetPosition(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if az >= 90 {
+		t.Fatalf("ротатор повернулся мгновенно: азимут %.2f", az)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	az, el, err = client.GetPosition(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if az != 90 || el != 30 {
+		t.Fatalf("положение %.2f/%.2f, ожидалось 90/30", az, el)
+	}
+}
+
+func TestReportError(t *testing.T) {
+	fake, client := connect(t)
+	ctx := context.Background()
+
+	fake.SetError(-5)
+
+	err := client.SetPosition(ctx, 10, 10)
+	if err == nil || !strings.Contains(err.Error(), "RPRT -5") {
+		t.Fatalf("SetPosition: ошибка %v, ожидалась RPRT -5", err)
+	}
+
+	// на p вместо двух строк положения приходит одна строка RPRT
+	_, _, err = client.GetPosition(ctx)
+	if err == nil || !strings.Contains(err.Error(), "RPRT -5") {
+		t.Fatalf("GetPosition: ошибка %v, ожидалась RPRT -5", err)
+	}
+
+	// после ошибки соединение остается рабочим
+	fake.SetError(0)
+	if err := client.SetPosition(ctx, 10, 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNotConnected(t *testing.T) {
+	client := New("127.0.0.1:1")
+
+	if err := client.SetPosition(context.Background(), 0, 0); err == nil {
+		t.Fatal("SetPosition без соединения должен вернуть ошибку")
+	}
+}
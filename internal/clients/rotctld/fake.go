@@ -0,0 +1,113 @@
+package rotctld
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/clients/hamlib"
+)
+
+// FakeServer - встроенный rotctld сервер для тестов и отладки без настоящего ротатора.
+// Поддерживает команды P, p, S, q и их длинные формы \set_pos, \get_pos, \stop.
+// Если задана скорость SlewRate, ротатор поворачивается к цели постепенно. SetError имитирует
+// ошибку ротатора.
+type FakeServer struct {
+	*hamlib.FakeServer
+
+	SlewRate float64 // град/с, 0 - мгновенный поворот
+
+	mu       sync.Mutex
+	az, el   float64
+	targetAz float64
+	targetEl float64
+	moved    time.Time
+}
+
+// NewFakeServer запускает сервер на addr, например "127.0.0.1:0" (свободный порт)
+func NewFakeServer(addr string) (*FakeServer, error) {
+	f := &FakeServer{
+		moved: time.Now(),
+	}
+
+	srv, err := hamlib.NewFakeServer(addr, f.command)
+	if err != nil {
+		return nil, err
+	}
+
+	f.FakeServer = srv
+
+	return f, nil
+}
+
+// Position возвращает текущее положение ротатора
+func (f *FakeServer) Position() (float64, float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.update()
+
+	return f.az, f.el
+}
+
+func (f *FakeServer) command(fields []string) (string, bool) {
+	switch fields[0] {
+	case "P", `\set_pos`:
+		if len(fields) != 3 {
+			return "RPRT -1\n", true
+		}
+
+		az, errAz := strconv.ParseFloat(fields[1], 64)
+		el, errEl := strconv.ParseFloat(fields[2], 64)
+		if errAz != nil || errEl != nil {
+			return "RPRT -1\n", true
+		}
+
+		f.mu.Lock()
+		f.update()
+		f.targetAz, f.targetEl = az, el
+		f.mu.Unlock()
+
+		return "RPRT 0\n", true
+
+	case "p", `\get_pos`:
+		az, el := f.Position()
+		return fmt.Sprintf("%.6f\n%.6f\n", az, el), true
+
+	case "S", `\stop`:
+		f.mu.Lock()
+		f.update()
+		f.targetAz, f.targetEl = f.az, f.el
+		f.mu.Unlock()
+
+		return "RPRT 0\n", true
+	}
+
+	return "", false
+}
+
+// update передвигает ротатор к цели с учетом прошедшего времени. Вызывать под мьютексом.
+func (f *FakeServer) update() {
+	now := time.Now()
+	dt := now.Sub(f.moved).Seconds()
+	f.moved = now
+
+	if f.SlewRate <= 0 {
+		f.az, f.el = f.targetAz, f.targetEl
+		return
+	}
+
+	step := f.SlewRate * dt
+	f.az = approach(f.az, f.targetAz, step)
+	f.el = approach(f.el, f.targetEl, step)
+}
+
+func approach(current, target, step float64) float64 {
+	if math.Abs(target-current) <= step {
+		return target
+	}
+
+	return current + math.Copysign(step, target-current)
+}
@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Client отправляет уведомления POST-запросом с JSON на адреса, заданные пользователем.
+// Адреса задаются пользователем, поэтому подключения к внутренним адресам (loopback, частные сети,
+// link-local) запрещены на уровне соединения - это защищает и от перенаправлений, и от DNS-записей,
+// которые после проверки адреса стали указывать во внутреннюю сеть.
+type Client struct {
+	client *http.Client
+}
+
+func New() *Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || !publicIP(ip) {
+				return fmt.Errorf("подключение к внутреннему адресу %s запрещено", host)
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Client{
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		},
+	}
+}
+
+// ValidateURL проверяет адрес для уведомлений: схема http или https, а имя хоста разрешается только
+// в публичные адреса
+func ValidateURL(ctx context.Context, raw string) error {
+	u, err := parseURL(raw)
+	if err != nil {
+		return err
+	}
+
+	host := u.Hostname()
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicIP(ip) {
+			return fmt.Errorf("адрес %s - внутренний", host)
+		}
+
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("ошибка разрешения имени %s: %w", host, err)
+	}
+
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return fmt.Errorf("имя %s разрешается во внутренний адрес %s", host, addr.IP)
+		}
+	}
+
+	return nil
+}
+
+func parseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный адрес: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("поддерживаются только адреса http и https")
+	}
+
+	if u.Hostname() == "" {
+		return nil, errors.New("в адресе не указан хост")
+	}
+
+	return u, nil
+}
+
+// publicIP - адрес не loopback, не из частных сетей, не link-local и не групповой
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// Send отправляет payload в формате JSON на url. Ответ с кодом не 2xx считается ошибкой.
+func (c *Client) Send(ctx context.Context, url string, payload any) error {
+	if _, err := parseURL(url); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации уведомления: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ошибка составления запроса: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("expected status 2xx, but got: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	ctx := context.Background()
+
+	for _, raw := range []string{
+		"https://93.184.216.34/hook",
+		"http://8.8.8.8:8080/hook",
+		"https://[2606:4700:4700::1111]/hook",
+	} {
+		if err := ValidateURL(ctx, raw); err != nil {
+			t.Errorf("ValidateURL(%q): %v", raw, err)
+		}
+	}
+
+	for _, raw := range []string{
+		"ftp://93.184.216.34/hook",
+		"file:///etc/passwd",
+		"93.184.216.34/hook",
+		"http:///hook",
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.1/hook",
+		"http://172.16.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[fe80::1]/hook",
+	} {
+		if err := ValidateURL(ctx, raw); err == nil {
+			t.Errorf("ValidateURL(%q): ожидалась ошибка", raw)
+		}
+	}
+}
+
+func TestSendRejectsInternalAddress(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	err := New().Send(context.Background(), srv.URL, map[string]int{"geofenceId": 1})
+	if err == nil {
+		t.Fatal("отправка на loopback: ожидалась ошибка")
+	}
+
+	if called {
+		t.Error("запрос дошел до сервера на loopback")
+	}
+}
@@ -10,6 +10,8 @@ type Config struct {
 	PgConnStr string `env:"POSTGRES_CONN_STR,required"`
 	R4uabURL  string `env:"R4UAB_URL,required"`
 	HTTPPort  int    `env:"HTTP_PORT,required"`
+	// профиль расчета SGP4 по умолчанию: "gravity/opsmode", например "wgs72/improved"
+	SGP4Profile string `env:"SGP4_PROFILE" envDefault:"wgs84/improved"`
 }
 
 func New() (*Config, error) {
@@ -0,0 +1,204 @@
+package czml
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// пакет формирует документ CZML для Cesium: положение спутников во времени
+// и линии связи станция-спутник на время пролетов
+
+const (
+	// степень интерполяции Лагранжа для положения спутника
+	interpolationDegree = 5
+	// ускорение часов в Cesium по умолчанию
+	clockMultiplier = 60
+)
+
+type Satellite struct {
+	ID     string
+	Name   string
+	States []satellite.StateVector
+	Color  [4]int // rgba
+}
+
+type Station struct {
+	ID   string
+	Name string
+	Lat  float64
+	Lon  float64
+	Alt  float64 // км
+}
+
+// Link - видимость спутника со станции: линия показывается только во время пролетов
+type Link struct {
+	SatelliteID string
+	StationID   string
+	Passes      []satellite.TimeRange
+}
+
+type Document struct {
+	Name       string
+	From       time.Time
+	To         time.Time
+	Satellites []Satellite
+	Stations   []Station
+	Links      []Link
+}
+
+// Encode возвращает документ CZML (массив пакетов, первый - описание документа)
+func (d Document) Encode() ([]byte, error) {
+	interval := formatInterval(d.From, d.To)
+
+	packets := []packet{{
+		"id":      "document",
+		"name":    d.Name,
+		"version": "1.0",
+		"clock": map[string]interface{}{
+			"interval":    interval,
+			"currentTime": formatTime(d.From),
+			"multiplier":  clockMultiplier,
+			"range":       "LOOP_STOP",
+			"step":        "SYSTEM_CLOCK_MULTIPLIER",
+		},
+	}}
+
+	for _, sat := range d.Satellites {
+		if len(sat.States) == 0 {
+			continue
+		}
+
+		epoch := sat.States[0].Time
+
+		// положение во вращающейся системе (FIXED), метры; время - секунды от epoch
+		cartesian := make([]float64, 0, len(sat.States)*4)
+		for _, sv := range sat.States {
+			x, y, z := sv.ECEF()
+			cartesian = append(cartesian, sv.Time.Sub(epoch).Seconds(), x*1000, y*1000, z*1000)
+		}
+
+		color := map[string]interface{}{"rgba": sat.Color[:]}
+
+		packets = append(packets, packet{
+			"id":           sat.ID,
+			"name":         sat.Name,
+			"availability": formatInterval(epoch, sat.States[len(sat.States)-1].Time),
+			"position": map[string]interface{}{
+				"epoch":                  formatTime(epoch),
+				"referenceFrame":         "FIXED",
+				"interpolationAlgorithm": "LAGRANGE",
+				"interpolationDegree":    interpolationDegree,
+				"cartesian":              cartesian,
+			},
+			"point": map[string]interface{}{
+				"pixelSize": 8,
+				"color":     color,
+			},
+			"label": map[string]interface{}{
+				"text":             sat.Name,
+				"pixelOffset":      map[string]interface{}{"cartesian2": []int{12, 0}},
+				"horizontalOrigin": "LEFT",
+				"fillColor":        color,
+			},
+			"path": map[string]interface{}{
+				"width":      1,
+				"leadTime":   0,
+				"resolution": 60,
+				"material": map[string]interface{}{
+					"solidColor": map[string]interface{}{"color": color},
+				},
+			},
+		})
+	}
+
+	for _, st := range d.Stations {
+		packets = append(packets, packet{
+			"id":   st.ID,
+			"name": st.Name,
+			"position": map[string]interface{}{
+				"cartographicDegrees": []float64{st.Lon, st.Lat, st.Alt * 1000},
+			},
+			"point": map[string]interface{}{
+				"pixelSize": 6,
+				"color":     map[string]interface{}{"rgba": []int{255, 255, 255, 255}},
+			},
+			"label": map[string]interface{}{
+				"text":             st.Name,
+				"pixelOffset":      map[string]interface{}{"cartesian2": []int{10, 0}},
+				"horizontalOrigin": "LEFT",
+			},
+		})
+	}
+
+	for _, link := range d.Links {
+		packets = append(packets, packet{
+			"id":           fmt.Sprintf("link-%s-%s", link.StationID, link.SatelliteID),
+			"availability": interval,
+			"polyline": map[string]interface{}{
+				"show": showIntervals(d.From, d.To, link.Passes),
+				"positions": map[string]interface{}{
+					"references": []string{link.StationID + "#position", link.SatelliteID + "#position"},
+				},
+				"width": 1,
+				"material": map[string]interface{}{
+					"solidColor": map[string]interface{}{
+						"color": map[string]interface{}{"rgba": []int{0, 255, 0, 255}},
+					},
+				},
+			},
+		})
+	}
+
+	res, err := json.Marshal(packets)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования CZML: %w", err)
+	}
+
+	return res, nil
+}
+
+type packet map[string]interface{}
+
+// showIntervals строит непересекающиеся интервалы видимости линии на [from, to]
+func showIntervals(from, to time.Time, passes []satellite.TimeRange) []map[string]interface{} {
+	var res []map[string]interface{}
+
+	add := func(a, b time.Time, show bool) {
+		if !b.After(a) {
+			return
+		}
+		res = append(res, map[string]interface{}{"interval": formatInterval(a, b), "boolean": show})
+	}
+
+	cur := from
+	for _, p := range passes {
+		start, end := p.From, p.To
+		if start.Before(cur) {
+			start = cur
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		add(cur, start, false)
+		add(start, end, true)
+		cur = end
+	}
+	add(cur, to, false)
+
+	return res
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatInterval(from, to time.Time) string {
+	return formatTime(from) + "/" + formatTime(to)
+}
@@ -0,0 +1,174 @@
+package ephemeris
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// пакет хранит заранее рассчитанные таблицы эфемерид спутников, чтобы частые запросы положения
+// (например, карта, которая опрашивает спутники каждую секунду) не гоняли SGP4 каждый раз.
+// таблицы хранятся в памяти (то есть, до окончания работы программы)
+
+const (
+	// длина окна, на которое строится таблица
+	defaultWindow = 6 * time.Hour
+	// насколько раньше запрошенного момента начинается окно
+	defaultLead = 30 * time.Minute
+	// допустимая погрешность интерполяции положения, км
+	defaultMaxErrorKm = 0.01
+)
+
+// шаги таблицы: следующий берется, если погрешность на предыдущем больше допустимой
+var steps = []time.Duration{time.Minute, 30 * time.Second, 10 * time.Second, 2 * time.Second}
+
+// сколько таблиц одного спутника (по разным TLE и профилям) хранится; лишние вытесняются по давности
+const maxEntriesPerSatellite = 4
+
+type Cache struct {
+	// mu защищает только карту таблиц, таблица строится под блокировкой своей записи
+	mu      sync.Mutex
+	entries map[Key]*entry
+}
+
+// Key - таблица строится по одному набору элементов с одним профилем SGP4: исторический и текущий
+// TLE спутника хранятся отдельно и не вытесняют друг друга
+type Key struct {
+	SatelliteID int
+	Line1       string
+	Line2       string
+	Profile     satellite.Profile
+}
+
+type entry struct {
+	// mu держится, пока таблица строится: одновременные запросы той же таблицы ждут ее, а не строят заново
+	mu  sync.Mutex
+	sat *satellite.Satellite
+	eph satellite.Ephemeris
+	// последнее обращение, для вытеснения; защищено Cache.mu
+	used time.Time
+}
+
+func New() *Cache {
+	return &Cache{
+		entries: make(map[Key]*entry),
+	}
+}
+
+// StateVector возвращает интерполированный вектор состояния спутника по набору элементов k на момент t
+// и оценку погрешности положения, км. Спутник (SGP4) создается и таблица строится, только если
+// момент t вне окна уже построенной таблицы.
+func (c *Cache) StateVector(k Key, t time.Time) (satellite.StateVector, float64, error) {
+	e := c.entry(k)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.eph.Covers(t) {
+		if e.sat == nil {
+			sat, err := satellite.NewWithProfile(k.Line1, k.Line2, k.Profile)
+			if err != nil {
+				return satellite.StateVector{}, 0, fmt.Errorf("satellite.NewWithProfile: %w", err)
+			}
+
+			e.sat = &sat
+		}
+
+		eph, err := build(*e.sat, t)
+		if err != nil {
+			return satellite.StateVector{}, 0, err
+		}
+
+		e.eph = eph
+	}
+
+	sv, err := e.eph.StateVector(t)
+	if err != nil {
+		return satellite.StateVector{}, 0, err
+	}
+
+	return sv, e.eph.MaxErrorKm, nil
+}
+
+// entry возвращает запись таблицы k, создавая ее при необходимости
+func (c *Cache) entry(k Key) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[k]
+	if !ok {
+		e = &entry{}
+		c.entries[k] = e
+	}
+	e.used = time.Now()
+
+	if !ok {
+		c.evict(k.SatelliteID)
+	}
+
+	return e
+}
+
+// evict оставляет у спутника id не больше maxEntriesPerSatellite таблиц, выбрасывая самые давние.
+// Вызывается под c.mu.
+func (c *Cache) evict(id int) {
+	for {
+		var (
+			oldest Key
+			count  int
+		)
+
+		for k, e := range c.entries {
+			if k.SatelliteID != id {
+				continue
+			}
+
+			if count == 0 || e.used.Before(c.entries[oldest].used) {
+				oldest = k
+			}
+			count++
+		}
+
+		if count <= maxEntriesPerSatellite {
+			return
+		}
+
+		delete(c.entries, oldest)
+	}
+}
+
+// Invalidate выбрасывает таблицы спутника id (например, после обновления TLE)
+func (c *Cache) Invalidate(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.entries {
+		if k.SatelliteID == id {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// build строит таблицу на окно вокруг t, уменьшая шаг, пока погрешность больше допустимой.
+// Если не помогает и наименьший шаг, возвращается таблица с наименьшим шагом и ее погрешностью.
+func build(sat satellite.Satellite, t time.Time) (satellite.Ephemeris, error) {
+	from := t.Add(-defaultLead)
+	to := from.Add(defaultWindow)
+
+	var eph satellite.Ephemeris
+	for _, step := range steps {
+		var err error
+		eph, err = sat.Ephemeris(from, to, step)
+		if err != nil {
+			return satellite.Ephemeris{}, fmt.Errorf("sat.Ephemeris: %w", err)
+		}
+
+		if eph.MaxErrorKm <= defaultMaxErrorKm {
+			break
+		}
+	}
+
+	return eph, nil
+}
@@ -0,0 +1,98 @@
+package ephemeris
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+const (
+	issLine1 = "1 25544U 98067A   24183.58373848  .00018876  00000+0  34701-3 0  9997"
+	issLine2 = "2 25544  51.6418 101.3576 0006878  64.9182 295.2226 15.49597969458107"
+)
+
+func TestCacheKeepsEntryPerTLE(t *testing.T) {
+	c := New()
+	at := time.Date(2024, 7, 2, 12, 0, 0, 0, time.UTC)
+
+	// второй набор элементов того же спутника - те же элементы с эпохой на сутки раньше
+	elements, err := satellite.ParseTLE(issLine1, issLine2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elements.Epoch = elements.Epoch.Add(-24 * time.Hour)
+	oldLine1, oldLine2, err := elements.TLE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := Key{SatelliteID: 1, Line1: issLine1, Line2: issLine2, Profile: satellite.DefaultProfile}
+	old := Key{SatelliteID: 1, Line1: oldLine1, Line2: oldLine2, Profile: satellite.DefaultProfile}
+
+	if _, _, err := c.StateVector(current, at); err != nil {
+		t.Fatal(err)
+	}
+	first := c.entries[current].eph
+
+	// запрос по историческому TLE не вытесняет таблицу текущего
+	if _, _, err := c.StateVector(old, at); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.StateVector(current, at.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.entries) != 2 {
+		t.Fatalf("таблиц %d, ожидалось 2", len(c.entries))
+	}
+	if !c.entries[current].eph.From().Equal(first.From()) {
+		t.Fatal("таблица текущего TLE построена заново")
+	}
+
+	c.Invalidate(1)
+	if len(c.entries) != 0 {
+		t.Fatalf("после Invalidate осталось таблиц: %d", len(c.entries))
+	}
+}
+
+func TestCacheMatchesSGP4(t *testing.T) {
+	c := New()
+	key := Key{SatelliteID: 1, Line1: issLine1, Line2: issLine2, Profile: satellite.DefaultProfile}
+	sat, err := satellite.NewWithProfile(issLine1, issLine2, satellite.DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2024, 7, 2, 12, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			at := from.Add(time.Duration(i) * 17 * time.Minute)
+
+			got, errKm, err := c.StateVector(key, at)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			want, err := sat.StateVector(at)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			d := math.Sqrt((got.X-want.X)*(got.X-want.X) + (got.Y-want.Y)*(got.Y-want.Y) + (got.Z-want.Z)*(got.Z-want.Z))
+			if d > 0.05 || errKm > defaultMaxErrorKm {
+				t.Errorf("%s: расхождение с SGP4 %.4f км, оценка %.4f км", at, d, errKm)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// пакет формирует календарь в формате iCalendar (RFC 5545)
+
+// формат даты и времени UTC в iCalendar
+const timeLayout = "20060102T150405Z"
+
+// максимальная длина строки в октетах без перевода строки (RFC 5545, 3.1)
+const maxLineOctets = 75
+
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+}
+
+type Calendar struct {
+	Name string
+	// как часто клиенту обновлять подписку, 0 - не указывать
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Encode возвращает календарь в формате text/calendar
+func (c Calendar) Encode(now time.Time) []byte {
+	var buf bytes.Buffer
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:-//UMKA//Satellite passes//RU")
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	if c.RefreshInterval > 0 {
+		minutes := int(c.RefreshInterval / time.Minute)
+		writeLine(&buf, "REFRESH-INTERVAL;VALUE=DURATION:PT"+strconv.Itoa(minutes)+"M")
+		writeLine(&buf, "X-PUBLISHED-TTL:PT"+strconv.Itoa(minutes)+"M")
+	}
+
+	stamp := now.UTC().Format(timeLayout)
+
+	for _, e := range c.Events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+e.UID)
+		writeLine(&buf, "DTSTAMP:"+stamp)
+		writeLine(&buf, "DTSTART:"+e.Start.UTC().Format(timeLayout))
+		writeLine(&buf, "DTEND:"+e.End.UTC().Format(timeLayout))
+		writeLine(&buf, "SUMMARY:"+escapeText(e.Summary))
+
+		if e.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Location != "" {
+			writeLine(&buf, "LOCATION:"+escapeText(e.Location))
+		}
+
+		writeLine(&buf, "TRANSP:TRANSPARENT")
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+// escapeText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+
+	return r.Replace(s)
+}
+
+// writeLine пишет строку с переносом длинных строк (folding) и окончанием CRLF.
+// Строка разрывается только между символами UTF-8.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+
+		// продолжение начинается с пробела, который тоже занимает октет
+		limit = maxLineOctets - 1
+	}
+
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/BabyLev/Umka-1/internal/repo/geofences"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// Задача: раз в час рассчитывать входы спутников в области и выходы из них на следующий час
+// и отправлять их на адреса уведомлений областей. Окна идут подряд, поэтому каждое событие
+// отправляется один раз (пока сервис не перезапускался).
+
+// период рассылки уведомлений об областях и длина окна, на которое рассчитываются события
+const geofenceNotifyPeriod = time.Hour
+
+// GeofenceNotification - тело уведомления о событиях одной области
+type GeofenceNotification struct {
+	GeofenceID    int                       `json:"geofenceId"`
+	GeofenceName  string                    `json:"geofenceName"`
+	SatelliteID   int                       `json:"satelliteId"`
+	SatelliteName string                    `json:"satelliteName"`
+	From          time.Time                 `json:"from"`
+	To            time.Time                 `json:"to"`
+	InsideAtStart bool                      `json:"insideAtStart"`
+	Events        []satellite.GeofenceEvent `json:"events"`
+}
+
+func (j *Jobs) NotifyGeofenceEvents(ctx context.Context, from, to time.Time) {
+	fences, err := j.repoFences.FindGeofence(ctx, geofences.FilterGeofence{
+		WithWebhook: lo.ToPtr(true),
+	})
+	if err != nil {
+		log.Default().Printf("j.repoFences.FindGeofence: %s", err.Error())
+		return
+	}
+
+	for _, fence := range fences {
+		sat, err := j.repoSats.GetSatellite(ctx, *fence.SatelliteID)
+		if err != nil {
+			log.Default().Printf("j.repoSats.GetSatellite(%d): %s", *fence.SatelliteID, err.Error())
+			continue
+		}
+
+		polygons, err := satellite.ParseGeoJSON(fence.GeoJSON)
+		if err != nil {
+			log.Default().Printf("satellite.ParseGeoJSON(%d): %s", fence.ID, err.Error())
+			continue
+		}
+
+		s, err := satellite.NewWithProfile(sat.Line1, sat.Line2, j.profile)
+		if err != nil {
+			log.Default().Printf("satellite.NewWithProfile(%d): %s", sat.ID, err.Error())
+			continue
+		}
+
+		reports, err := s.GeofenceEvents(from, to, []satellite.Geofence{{ID: fence.ID, Name: fence.Name, Polygons: polygons}})
+		if err != nil {
+			log.Default().Printf("s.GeofenceEvents(%d): %s", fence.ID, err.Error())
+			continue
+		}
+
+		report := reports[0]
+		if len(report.Events) == 0 {
+			continue
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+
+		err = j.webhookClient.Send(reqCtx, *fence.WebhookURL, GeofenceNotification{
+			GeofenceID:    fence.ID,
+			GeofenceName:  fence.Name,
+			SatelliteID:   sat.ID,
+			SatelliteName: sat.SatName,
+			From:          from,
+			To:            to,
+			InsideAtStart: report.InsideAtStart,
+			Events:        report.Events,
+		})
+		cancel()
+		if err != nil {
+			log.Default().Printf("j.webhookClient.Send(%d): %s", fence.ID, err.Error())
+		}
+	}
+}
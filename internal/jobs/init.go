@@ -5,21 +5,31 @@ import (
 	"time"
 
 	"github.com/BabyLev/Umka-1/internal/clients/r4uab"
+	"github.com/BabyLev/Umka-1/internal/clients/webhook"
+	geofencesRepo "github.com/BabyLev/Umka-1/internal/repo/geofences"
 	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
 	"github.com/BabyLev/Umka-1/internal/storage"
+	"github.com/BabyLev/Umka-1/satellite"
 )
 
 type Jobs struct {
-	storage     *storage.Storage
-	r4uabClient *r4uab.Client
-	repoSats    *satellitesRepo.Repo
+	storage       *storage.Storage
+	r4uabClient   *r4uab.Client
+	webhookClient *webhook.Client
+	repoSats      *satellitesRepo.Repo
+	repoFences    *geofencesRepo.Repo
+	// профиль SGP4, с которым рассчитываются события для уведомлений
+	profile satellite.Profile
 }
 
-func New(storage *storage.Storage, r4uabClient *r4uab.Client, repo *satellitesRepo.Repo) *Jobs {
+func New(storage *storage.Storage, r4uabClient *r4uab.Client, webhookClient *webhook.Client, repo *satellitesRepo.Repo, repoFences *geofencesRepo.Repo, profile satellite.Profile) *Jobs {
 	return &Jobs{
-		storage:     storage,
-		r4uabClient: r4uabClient,
-		repoSats:    repo,
+		storage:       storage,
+		r4uabClient:   r4uabClient,
+		webhookClient: webhookClient,
+		repoSats:      repo,
+		repoFences:    repoFences,
+		profile:       profile,
 	}
 }
 
@@ -27,6 +37,13 @@ func (j *Jobs) Start(ctx context.Context) {
 	ticker := time.NewTicker(24 * time.Hour)
 	defer ticker.Stop()
 
+	geofenceTicker := time.NewTicker(geofenceNotifyPeriod)
+	defer geofenceTicker.Stop()
+
+	// уведомления об областях рассылаются на окно вперед, окна идут подряд
+	notifyFrom := time.Now().UTC()
+	go j.NotifyGeofenceEvents(ctx, notifyFrom, notifyFrom.Add(geofenceNotifyPeriod))
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -34,6 +51,10 @@ func (j *Jobs) Start(ctx context.Context) {
 
 		case <-ticker.C:
 			go j.UpdateSatellitesInfo(ctx)
+
+		case <-geofenceTicker.C:
+			notifyFrom = notifyFrom.Add(geofenceNotifyPeriod)
+			go j.NotifyGeofenceEvents(ctx, notifyFrom, notifyFrom.Add(geofenceNotifyPeriod))
 		}
 	}
 }
@@ -48,6 +48,7 @@ func (j *Jobs) UpdateSatellitesInfo(ctx context.Context) {
 			NoradID: &noradID,
 			Line1:   sat.Line1,
 			Line2:   sat.Line2,
+			OMM:     sat.OMM,
 		})
 		if err != nil {
 			log.Default().Printf("j.storage.UpdateSatellite: %s", err.Error())
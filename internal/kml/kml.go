@@ -0,0 +1,174 @@
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// пакет формирует документ KML для Google Earth: трассы спутников, зоны видимости и станции
+
+type Satellite struct {
+	Name     string
+	Track    []satellite.GeoPoint // подспутниковые точки за интервал
+	Position satellite.SatelliteCoords
+	// граница зоны радиовидимости в момент Position
+	Footprint []satellite.GeoPoint
+	Color     string // aabbggrr, как принято в KML
+}
+
+type Station struct {
+	Name string
+	Lat  float64
+	Lon  float64
+	Alt  float64 // км
+}
+
+type Document struct {
+	Name       string
+	Satellites []Satellite
+	Stations   []Station
+}
+
+// Encode возвращает документ KML 2.2
+func (d Document) Encode() ([]byte, error) {
+	doc := kmlRoot{
+		XMLNS: "http://www.opengis.net/kml/2.2",
+	}
+	doc.Document.Name = d.Name
+
+	for i, sat := range d.Satellites {
+		styleID := fmt.Sprintf("sat-%d", i)
+
+		doc.Document.Styles = append(doc.Document.Styles, kmlStyle{
+			ID:        styleID,
+			LineStyle: &kmlLineStyle{Color: sat.Color, Width: 2},
+			IconStyle: &kmlIconStyle{Color: sat.Color},
+		})
+
+		folder := kmlFolder{Name: sat.Name}
+
+		folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+			Name:          "Трасса",
+			StyleURL:      "#" + styleID,
+			MultiGeometry: lines(satellite.SplitAtAntimeridian(sat.Track)),
+		})
+
+		folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+			Name:          "Зона видимости",
+			StyleURL:      "#" + styleID,
+			MultiGeometry: lines(satellite.SplitAtAntimeridian(sat.Footprint)),
+		})
+
+		folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+			Name:        sat.Name,
+			Description: fmt.Sprintf("%s, высота %.1f км", sat.Position.String(), sat.Position.Alt),
+			StyleURL:    "#" + styleID,
+			Point: &kmlPoint{
+				AltitudeMode: "absolute",
+				Coordinates:  fmt.Sprintf("%.6f,%.6f,%.0f", sat.Position.Lon, sat.Position.Lat, sat.Position.Alt*1000),
+			},
+		})
+
+		doc.Document.Folders = append(doc.Document.Folders, folder)
+	}
+
+	if len(d.Stations) > 0 {
+		folder := kmlFolder{Name: "Станции"}
+
+		for _, st := range d.Stations {
+			folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+				Name: st.Name,
+				Point: &kmlPoint{
+					AltitudeMode: "clampToGround",
+					Coordinates:  fmt.Sprintf("%.6f,%.6f,%.0f", st.Lon, st.Lat, st.Alt*1000),
+				},
+			})
+		}
+
+		doc.Document.Folders = append(doc.Document.Folders, folder)
+	}
+
+	res, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования KML: %w", err)
+	}
+
+	return append([]byte(xml.Header), res...), nil
+}
+
+func lines(parts [][]satellite.GeoPoint) *kmlMultiGeometry {
+	res := &kmlMultiGeometry{}
+
+	for _, part := range parts {
+		if len(part) < 2 {
+			continue
+		}
+
+		coords := make([]string, 0, len(part))
+		for _, p := range part {
+			coords = append(coords, fmt.Sprintf("%.6f,%.6f,0", p.Lon, p.Lat))
+		}
+
+		res.LineStrings = append(res.LineStrings, kmlLineString{
+			Tessellate:  1,
+			Coordinates: strings.Join(coords, " "),
+		})
+	}
+
+	return res
+}
+
+type kmlRoot struct {
+	XMLName  xml.Name `xml:"kml"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Document struct {
+		Name    string      `xml:"name"`
+		Styles  []kmlStyle  `xml:"Style"`
+		Folders []kmlFolder `xml:"Folder"`
+	} `xml:"Document"`
+}
+
+type kmlStyle struct {
+	ID        string        `xml:"id,attr"`
+	LineStyle *kmlLineStyle `xml:"LineStyle,omitempty"`
+	IconStyle *kmlIconStyle `xml:"IconStyle,omitempty"`
+}
+
+type kmlLineStyle struct {
+	Color string `xml:"color,omitempty"`
+	Width int    `xml:"width"`
+}
+
+type kmlIconStyle struct {
+	Color string `xml:"color,omitempty"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name          string            `xml:"name"`
+	Description   string            `xml:"description,omitempty"`
+	StyleURL      string            `xml:"styleUrl,omitempty"`
+	Point         *kmlPoint         `xml:"Point,omitempty"`
+	MultiGeometry *kmlMultiGeometry `xml:"MultiGeometry,omitempty"`
+}
+
+type kmlPoint struct {
+	AltitudeMode string `xml:"altitudeMode"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+type kmlMultiGeometry struct {
+	LineStrings []kmlLineString `xml:"LineString"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
@@ -0,0 +1,278 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// пакет кодирует ответы ручек в формат, выбранный по заголовку Accept:
+// JSON (по умолчанию), CSV, NDJSON (JSON Lines) и MessagePack.
+// Все форматы строятся из JSON-представления ответа, поэтому имена полей везде одинаковые.
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeCSV     = "text/csv; charset=utf-8"
+	ContentTypeNDJSON  = "application/x-ndjson"
+	ContentTypeMsgpack = "application/msgpack"
+)
+
+type format int
+
+const (
+	formatJSON format = iota
+	formatCSV
+	formatNDJSON
+	formatMsgpack
+)
+
+// соответствие MIME-типов из Accept форматам ответа
+var mediaTypes = map[string]format{
+	"application/json":        formatJSON,
+	"application/*":           formatJSON,
+	"*/*":                     formatJSON,
+	"text/csv":                formatCSV,
+	"text/*":                  formatCSV,
+	"application/x-ndjson":    formatNDJSON,
+	"application/ndjson":      formatNDJSON,
+	"application/jsonl":       formatNDJSON,
+	"application/x-jsonl":     formatNDJSON,
+	"application/msgpack":     formatMsgpack,
+	"application/x-msgpack":   formatMsgpack,
+	"application/vnd.msgpack": formatMsgpack,
+}
+
+// Write кодирует v в формат из заголовка Accept запроса и пишет ответ с нужным Content-Type.
+// Если клиент не принимает ни один из поддерживаемых форматов, отвечает 406.
+func Write(w http.ResponseWriter, r *http.Request, v interface{}) {
+	f, ok := negotiate(r.Header.Get("Accept"))
+	if !ok {
+		w.WriteHeader(http.StatusNotAcceptable)
+		w.Write([]byte("поддерживаются форматы application/json, text/csv, application/x-ndjson, application/msgpack"))
+		return
+	}
+
+	var (
+		res         []byte
+		contentType string
+		err         error
+	)
+
+	switch f {
+	case formatCSV:
+		res, err = encodeCSV(v)
+		contentType = ContentTypeCSV
+	case formatNDJSON:
+		res, err = encodeNDJSON(v)
+		contentType = ContentTypeNDJSON
+	case formatMsgpack:
+		res, err = encodeMsgpack(v)
+		contentType = ContentTypeMsgpack
+	default:
+		res, err = json.Marshal(v)
+		contentType = ContentTypeJSON
+	}
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("error marshalling: %w", err).Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Vary", "Accept")
+	w.Write(res)
+}
+
+// negotiate выбирает формат с наибольшим q среди поддерживаемых (при равенстве - первый по порядку)
+func negotiate(accept string) (format, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON, true
+	}
+
+	var (
+		best    format
+		bestQ   = -1.0
+		matched bool
+	)
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		f, ok := mediaTypes[mediaType]
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			fmt.Sscanf(qs, "%g", &q)
+		}
+
+		// при равном q конкретный тип важнее шаблона вида */*
+		if strings.HasSuffix(mediaType, "/*") {
+			q -= 0.0001
+		}
+
+		if q > 0 && q > bestQ {
+			best, bestQ, matched = f, q, true
+		}
+	}
+
+	return best, matched
+}
+
+func encodeNDJSON(v interface{}) ([]byte, error) {
+	value, err := toOrdered(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, row := range rows(value) {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeCSV(v interface{}) ([]byte, error) {
+	value, err := toOrdered(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var table []object
+	for _, row := range rows(value) {
+		obj, ok := row.(object)
+		if !ok {
+			obj = object{{key: "value", value: row}}
+		}
+
+		table = append(table, flattenRow(obj)...)
+	}
+
+	// колонки - объединение полей всех строк в порядке появления
+	var header []string
+	index := make(map[string]int)
+	for _, row := range table {
+		for _, f := range row {
+			if _, ok := index[f.key]; !ok {
+				index[f.key] = len(header)
+				header = append(header, f.key)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, row := range table {
+		record := make([]string, len(header))
+		for _, f := range row {
+			record[index[f.key]] = cellString(f.value)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+func encodeMsgpack(v interface{}) ([]byte, error) {
+	value, err := toOrdered(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.UseCompactInts(true)
+
+	if err := encodeMsgpackValue(enc, value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeMsgpackValue(enc *msgpack.Encoder, v interface{}) error {
+	switch val := v.(type) {
+	case object:
+		if err := enc.EncodeMapLen(len(val)); err != nil {
+			return err
+		}
+		for _, f := range val {
+			if err := enc.EncodeString(f.key); err != nil {
+				return err
+			}
+			if err := encodeMsgpackValue(enc, f.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if err := enc.EncodeArrayLen(len(val)); err != nil {
+			return err
+		}
+		for _, item := range val {
+			if err := encodeMsgpackValue(enc, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return enc.EncodeInt(i)
+		}
+		f, err := val.Float64()
+		if err != nil {
+			return err
+		}
+		return enc.EncodeFloat64(f)
+	default:
+		return enc.Encode(val)
+	}
+}
+
+// cellString переводит значение в текст ячейки CSV. Массивы записываются как JSON.
+func cellString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	default:
+		res, _ := json.Marshal(val)
+		return string(res)
+	}
+}
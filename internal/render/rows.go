@@ -0,0 +1,235 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// field и object - JSON-объект с сохранением порядка полей (map его теряет)
+type field struct {
+	key   string
+	value interface{}
+}
+
+type object []field
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// toOrdered переводит значение в дерево из object, []interface{}, json.Number, string, bool и nil
+func toOrdered(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := object{}
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, fmt.Errorf("неожиданный ключ JSON %v", keyToken)
+			}
+
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			obj = append(obj, field{key: key, value: value})
+		}
+
+		_, err = dec.Token()
+		return obj, err
+	case '[':
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, value)
+		}
+
+		_, err = dec.Token()
+		return arr, err
+	}
+
+	return nil, fmt.Errorf("неожиданный разделитель JSON %v", delim)
+}
+
+// rows выделяет из ответа строки для построчных форматов:
+//   - массив - каждый элемент;
+//   - объект с одним полем-массивом ({"satellites": [...]}) - элементы массива;
+//   - объект с одним полем-словарем объектов по ID ({"satellites": {"1": {...}}}) или сам такой словарь -
+//     объекты словаря с полем id в начале;
+//   - любое другое значение - одна строка.
+func rows(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		return val
+	case object:
+		if len(val) == 1 {
+			switch inner := val[0].value.(type) {
+			case []interface{}:
+				return inner
+			case object:
+				if res, ok := rowsByID(inner); ok {
+					return res
+				}
+			}
+		}
+
+		if res, ok := rowsByID(val); ok {
+			return res
+		}
+	}
+
+	return []interface{}{v}
+}
+
+// rowsByID разворачивает словарь {"<id>": {...}} в строки с полем id
+func rowsByID(obj object) ([]interface{}, bool) {
+	if len(obj) == 0 {
+		return nil, false
+	}
+
+	res := make([]interface{}, 0, len(obj))
+	for _, f := range obj {
+		inner, ok := f.value.(object)
+		if !ok {
+			return nil, false
+		}
+
+		id, err := strconv.ParseInt(f.key, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+
+		row := append(object{{key: "id", value: json.Number(strconv.FormatInt(id, 10))}}, inner...)
+		res = append(res, row)
+	}
+
+	return res, true
+}
+
+// flattenRow переводит объект в плоские строки CSV: вложенные объекты дают колонки "a.b",
+// первый найденный массив объектов разворачивается в несколько строк (например, точки
+// таблицы наведения), поля родителя в них повторяются. Остальные массивы остаются значениями.
+func flattenRow(obj object) []object {
+	var (
+		base      object
+		expandKey string
+		expand    []interface{}
+	)
+
+	var walk func(prefix string, obj object)
+	walk = func(prefix string, obj object) {
+		for _, f := range obj {
+			key := prefix + f.key
+
+			switch val := f.value.(type) {
+			case object:
+				walk(key+".", val)
+			case []interface{}:
+				if expandKey == "" && isObjectArray(val) {
+					expandKey, expand = key, val
+					continue
+				}
+				base = append(base, field{key: key, value: val})
+			default:
+				base = append(base, field{key: key, value: val})
+			}
+		}
+	}
+	walk("", obj)
+
+	if expandKey == "" {
+		return []object{base}
+	}
+
+	res := make([]object, 0, len(expand))
+	for _, item := range expand {
+		row := append(object{}, base...)
+
+		var walkItem func(prefix string, obj object)
+		walkItem = func(prefix string, obj object) {
+			for _, f := range obj {
+				if inner, ok := f.value.(object); ok {
+					walkItem(prefix+f.key+".", inner)
+					continue
+				}
+				row = append(row, field{key: prefix + f.key, value: f.value})
+			}
+		}
+		walkItem(expandKey+".", item.(object))
+
+		res = append(res, row)
+	}
+
+	return res
+}
+
+func isObjectArray(arr []interface{}) bool {
+	if len(arr) == 0 {
+		return false
+	}
+
+	for _, item := range arr {
+		if _, ok := item.(object); !ok {
+			return false
+		}
+	}
+
+	return true
+}
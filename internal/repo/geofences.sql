@@ -0,0 +1,9 @@
+--- схема таблицы для областей (geofence), вход в которые и выход из которых отслеживается
+
+create table geofences (
+    id bigserial primary key, --- первичный ключ, идентификаторы областей
+    fence_name text not null, --- имя области
+    geojson jsonb not null, --- многоугольники области в формате GeoJSON (Polygon, MultiPolygon, Feature, FeatureCollection)
+    satellite_id bigint references satellites(id) on delete set null, --- спутник, о котором присылаются уведомления
+    webhook_url text --- адрес, на который задача отправляет события входа и выхода (POST JSON)
+)
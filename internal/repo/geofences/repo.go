@@ -0,0 +1,114 @@
+package geofences
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	conn *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *Repo {
+	return &Repo{
+		conn: pool,
+	}
+}
+
+const geofenceColumns = "id, fence_name, geojson, satellite_id, webhook_url"
+
+// CRUD Geofences
+
+func (r *Repo) CreateGeofence(ctx context.Context, fence Geofence) (int, error) {
+	query := `
+	insert into geofences
+	 (fence_name, geojson, satellite_id, webhook_url)
+	 values ($1, $2, $3, $4) returning id;
+	 `
+
+	row := r.conn.QueryRow(ctx, query, fence.Name, fence.GeoJSON, fence.SatelliteID, fence.WebhookURL)
+	var id int
+	err := row.Scan(&id)
+
+	return id, err
+}
+
+func (r *Repo) GetGeofence(ctx context.Context, id int) (Geofence, error) {
+	fence := Geofence{}
+
+	err := r.conn.QueryRow(ctx, "select "+geofenceColumns+" from geofences where id=$1", id).
+		Scan(&fence.ID, &fence.Name, &fence.GeoJSON, &fence.SatelliteID, &fence.WebhookURL)
+	if err != nil {
+		return Geofence{}, err
+	}
+
+	return fence, nil
+}
+
+func (r *Repo) UpdateGeofence(ctx context.Context, fence Geofence) error {
+	query := `
+	 update geofences
+	 set fence_name = $1, geojson = $2, satellite_id = $3, webhook_url = $4
+	 where id=$5
+	`
+
+	_, err := r.conn.Exec(ctx, query, fence.Name, fence.GeoJSON, fence.SatelliteID, fence.WebhookURL, fence.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repo) DeleteGeofence(ctx context.Context, id int) error {
+	_, err := r.conn.Exec(ctx, "delete from geofences where id=$1", id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repo) FindGeofence(ctx context.Context, filter FilterGeofence) ([]Geofence, error) {
+	var args []interface{}
+	query := "select " + geofenceColumns + " from geofences where 1=1"
+
+	argId := 1
+
+	if filter.Name != nil && *filter.Name != "" {
+		query += fmt.Sprintf(" AND fence_name ilike $%d", argId)
+		args = append(args, "%"+*filter.Name+"%")
+		argId++
+	}
+
+	if filter.WithWebhook != nil && *filter.WithWebhook {
+		query += " AND satellite_id is not null AND webhook_url is not null"
+	}
+
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса FindGeofence: %w", err)
+	}
+	defer rows.Close()
+
+	var fences []Geofence
+
+	for rows.Next() {
+		var fence Geofence
+
+		err := rows.Scan(&fence.ID, &fence.Name, &fence.GeoJSON, &fence.SatelliteID, &fence.WebhookURL)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось вернуть область %w", err)
+		}
+
+		fences = append(fences, fence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по результату из бд: %w", err)
+	}
+
+	return fences, nil
+}
@@ -0,0 +1,15 @@
+package geofences
+
+type Geofence struct {
+	ID          int
+	Name        string
+	GeoJSON     []byte // многоугольники области в формате GeoJSON
+	SatelliteID *int   // спутник для уведомлений
+	WebhookURL  *string
+}
+
+type FilterGeofence struct {
+	Name *string
+	// только области с заданными спутником и адресом уведомлений
+	WithWebhook *bool
+}
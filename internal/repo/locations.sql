@@ -1,9 +1,18 @@
 --- схема таблицы для локаций
 
-create table locations (
+create table if not exists locations (
     id bigserial primary key, --- первичный ключ, идентификаторы локаций
     loc_name text not null, --- имя локации
     lat numeric(9,6) not null,
     lon numeric(9,6) not null,
-    alt numeric(9,6) not null
-)
\ No newline at end of file
+    alt numeric(9,6) not null,
+    refraction_model text, --- модель рефракции: none, bennett, bennett-pt
+    pressure numeric(7,2), --- давление у наблюдателя, гПа (для bennett-pt)
+    temperature numeric(5,2) --- температура у наблюдателя, °C (для bennett-pt)
+);
+
+--- миграция для баз, созданных до появления рефракции: файл можно применять повторно
+
+alter table locations add column if not exists refraction_model text;
+alter table locations add column if not exists pressure numeric(7,2);
+alter table locations add column if not exists temperature numeric(5,2);
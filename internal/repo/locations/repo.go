@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -22,11 +23,13 @@ func New(pool *pgxpool.Pool) *Repo {
 func (r *Repo) CreateLocation(ctx context.Context, loc Location) (int, error) {
 	query := `
 	insert into locations
-	 (loc_name, lon, lat, alt) 
-	 values ($1, $2, $3, $4) returning id;
+	 (loc_name, lon, lat, alt, refraction_model, pressure, temperature) 
+	 values ($1, $2, $3, $4, $5, $6, $7) returning id;
 	 `
 
-	row := r.conn.QueryRow(ctx, query, loc.Name, loc.Point.Lon, loc.Point.Lat, loc.Point.Alt)
+	model, pressure, temperature := refractionArgs(loc.Refraction)
+
+	row := r.conn.QueryRow(ctx, query, loc.Name, loc.Point.Lon, loc.Point.Lat, loc.Point.Alt, model, pressure, temperature)
 	var id int
 	err := row.Scan(&id)
 
@@ -34,10 +37,7 @@ func (r *Repo) CreateLocation(ctx context.Context, loc Location) (int, error) {
 }
 
 func (r *Repo) GetLocation(ctx context.Context, id int) (Location, error) {
-	loc := Location{}
-
-	err := r.conn.QueryRow(ctx, "select id, loc_name, lon, lat, alt from locations where id=$1", id).
-		Scan(&loc.ID, &loc.Name, &loc.Point.Lon, &loc.Point.Lat, &loc.Point.Alt)
+	loc, err := scanLocation(r.conn.QueryRow(ctx, "select "+locationColumns+" from locations where id=$1", id))
 	if err != nil {
 		return Location{}, err
 	}
@@ -48,11 +48,14 @@ func (r *Repo) GetLocation(ctx context.Context, id int) (Location, error) {
 func (r *Repo) UpdateLocation(ctx context.Context, loc Location) error {
 	query := `
 	 update locations 
-	 set loc_name = $1, lon = $2, lat = $3, alt = $4 
-	 where id=$5
+	 set loc_name = $1, lon = $2, lat = $3, alt = $4,
+	 refraction_model = $5, pressure = $6, temperature = $7
+	 where id=$8
 	`
 
-	_, err := r.conn.Exec(ctx, query, loc.Name, loc.Point.Lon, loc.Point.Lat, loc.Point.Alt, loc.ID)
+	model, pressure, temperature := refractionArgs(loc.Refraction)
+
+	_, err := r.conn.Exec(ctx, query, loc.Name, loc.Point.Lon, loc.Point.Lat, loc.Point.Alt, model, pressure, temperature, loc.ID)
 	if err != nil {
 		return err
 	}
@@ -71,7 +74,7 @@ func (r *Repo) DeleteLocation(ctx context.Context, id int) error {
 
 func (r *Repo) FindLocation(ctx context.Context, filter FilterLocation) ([]Location, error) {
 	query := `
-	select * from locations 
+	select ` + locationColumns + ` from locations 
 	where 1=1
 	AND CASE
 		WHEN $1::text IS NOT NULL THEN loc_name ilike '%' || $1 || '%'
@@ -88,9 +91,7 @@ func (r *Repo) FindLocation(ctx context.Context, filter FilterLocation) ([]Locat
 	var locs []Location
 
 	for rows.Next() {
-		var loc Location
-
-		err := rows.Scan(&loc.ID, &loc.Name, &loc.Point.Lon, &loc.Point.Lat, &loc.Point.Alt)
+		loc, err := scanLocation(rows)
 		if err != nil {
 			return nil, fmt.Errorf("не удалось вернуть локацию %w", err)
 		}
@@ -104,3 +105,39 @@ func (r *Repo) FindLocation(ctx context.Context, filter FilterLocation) ([]Locat
 
 	return locs, nil
 }
+
+const locationColumns = "id, loc_name, lon, lat, alt, refraction_model, pressure, temperature"
+
+func scanLocation(row pgx.Row) (Location, error) {
+	var (
+		loc         Location
+		model       *string
+		pressure    *float64
+		temperature *float64
+	)
+
+	err := row.Scan(&loc.ID, &loc.Name, &loc.Point.Lon, &loc.Point.Lat, &loc.Point.Alt, &model, &pressure, &temperature)
+	if err != nil {
+		return Location{}, err
+	}
+
+	if model != nil {
+		loc.Refraction = &Refraction{Model: *model}
+		if pressure != nil {
+			loc.Refraction.Pressure = *pressure
+		}
+		if temperature != nil {
+			loc.Refraction.Temperature = *temperature
+		}
+	}
+
+	return loc, nil
+}
+
+func refractionArgs(refr *Refraction) (*string, *float64, *float64) {
+	if refr == nil {
+		return nil, nil, nil
+	}
+
+	return &refr.Model, &refr.Pressure, &refr.Temperature
+}
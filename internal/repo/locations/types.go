@@ -1,9 +1,16 @@
 package locations
 
 type Location struct {
-	ID    int
-	Name  string
-	Point Point
+	ID         int
+	Name       string
+	Point      Point
+	Refraction *Refraction // nil - рефракция не учитывается
+}
+
+type Refraction struct {
+	Model       string
+	Pressure    float64 // гПа
+	Temperature float64 // °C
 }
 
 type Point struct {
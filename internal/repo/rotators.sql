@@ -0,0 +1,15 @@
+--- схема таблицы для профилей ротаторов
+
+create table rotators (
+    id bigserial primary key, --- первичный ключ, идентификаторы ротаторов
+    rot_name text not null, --- имя ротатора
+    location_id bigint not null references locations(id) on delete cascade, --- локация, на которой стоит ротатор
+    mode text not null default 'normal', --- механика: normal, az450, el180
+    min_az numeric(9,6) not null,
+    max_az numeric(9,6) not null,
+    min_el numeric(9,6) not null,
+    max_el numeric(9,6) not null,
+    az_slew_rate numeric(9,6) not null default 0, --- град/с, 0 - без ограничения
+    el_slew_rate numeric(9,6) not null default 0, --- град/с, 0 - без ограничения
+    rotctld_addr text --- адрес Hamlib rotctld (host:port) для сопровождения
+)
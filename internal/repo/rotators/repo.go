@@ -0,0 +1,121 @@
+package rotators
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	conn *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *Repo {
+	return &Repo{
+		conn: pool,
+	}
+}
+
+const rotatorColumns = "id, rot_name, location_id, mode, min_az, max_az, min_el, max_el, az_slew_rate, el_slew_rate, rotctld_addr"
+
+// CRUD Rotators
+
+func (r *Repo) CreateRotator(ctx context.Context, rot Rotator) (int, error) {
+	query := `
+	insert into rotators
+	 (rot_name, location_id, mode, min_az, max_az, min_el, max_el, az_slew_rate, el_slew_rate, rotctld_addr)
+	 values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) returning id;
+	 `
+
+	row := r.conn.QueryRow(ctx, query, rot.Name, rot.LocationID, rot.Mode,
+		rot.MinAz, rot.MaxAz, rot.MinEl, rot.MaxEl, rot.AzSlewRate, rot.ElSlewRate, rot.Address)
+	var id int
+	err := row.Scan(&id)
+
+	return id, err
+}
+
+func (r *Repo) GetRotator(ctx context.Context, id int) (Rotator, error) {
+	rot := Rotator{}
+
+	err := r.conn.QueryRow(ctx, "select "+rotatorColumns+" from rotators where id=$1", id).
+		Scan(&rot.ID, &rot.Name, &rot.LocationID, &rot.Mode, &rot.MinAz, &rot.MaxAz,
+			&rot.MinEl, &rot.MaxEl, &rot.AzSlewRate, &rot.ElSlewRate, &rot.Address)
+	if err != nil {
+		return Rotator{}, err
+	}
+
+	return rot, nil
+}
+
+func (r *Repo) UpdateRotator(ctx context.Context, rot Rotator) error {
+	query := `
+	 update rotators
+	 set rot_name = $1, location_id = $2, mode = $3, min_az = $4, max_az = $5,
+	 min_el = $6, max_el = $7, az_slew_rate = $8, el_slew_rate = $9, rotctld_addr = $10
+	 where id=$11
+	`
+
+	_, err := r.conn.Exec(ctx, query, rot.Name, rot.LocationID, rot.Mode, rot.MinAz, rot.MaxAz,
+		rot.MinEl, rot.MaxEl, rot.AzSlewRate, rot.ElSlewRate, rot.Address, rot.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repo) DeleteRotator(ctx context.Context, id int) error {
+	_, err := r.conn.Exec(ctx, "delete from rotators where id=$1", id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repo) FindRotator(ctx context.Context, filter FilterRotator) ([]Rotator, error) {
+	var args []interface{}
+	query := "select " + rotatorColumns + " from rotators where 1=1"
+
+	argId := 1
+
+	if filter.Name != nil && *filter.Name != "" {
+		query += fmt.Sprintf(" AND rot_name ilike $%d", argId)
+		args = append(args, "%"+*filter.Name+"%")
+		argId++
+	}
+
+	if filter.LocationID != nil {
+		query += fmt.Sprintf(" AND location_id = $%d", argId)
+		args = append(args, *filter.LocationID)
+		argId++
+	}
+
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса FindRotator: %w", err)
+	}
+	defer rows.Close()
+
+	var rots []Rotator
+
+	for rows.Next() {
+		var rot Rotator
+
+		err := rows.Scan(&rot.ID, &rot.Name, &rot.LocationID, &rot.Mode, &rot.MinAz, &rot.MaxAz,
+			&rot.MinEl, &rot.MaxEl, &rot.AzSlewRate, &rot.ElSlewRate, &rot.Address)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось вернуть ротатор %w", err)
+		}
+
+		rots = append(rots, rot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по результату из бд: %w", err)
+	}
+
+	return rots, nil
+}
@@ -0,0 +1,20 @@
+package rotators
+
+type Rotator struct {
+	ID         int
+	Name       string
+	LocationID int
+	Mode       string
+	MinAz      float64
+	MaxAz      float64
+	MinEl      float64
+	MaxEl      float64
+	AzSlewRate float64 // град/с
+	ElSlewRate float64 // град/с
+	Address    *string // адрес rotctld, host:port
+}
+
+type FilterRotator struct {
+	Name       *string
+	LocationID *int
+}
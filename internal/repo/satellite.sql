@@ -1,9 +1,14 @@
 --- схема таблицы для спутников
 
-create table satellites (
+create table if not exists satellites (
     id bigserial primary key, --- первичный ключ, идентификаторы спутников
     sat_name text not null, --- имя спутика
     norad_id int,
     line1 text not null,
-    line2 text not null
-)
\ No newline at end of file
+    line2 text not null,
+    omm jsonb --- исходные элементы в формате CCSDS OMM (JSON), если спутник задан через OMM
+);
+
+--- миграция для баз, созданных до появления OMM: файл можно применять повторно
+
+alter table satellites add column if not exists omm jsonb;
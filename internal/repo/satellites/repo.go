@@ -21,11 +21,11 @@ func New(pool *pgxpool.Pool) *Repo {
 func (r *Repo) CreateSatellite(ctx context.Context, sat Satellite) (int, error) {
 	query := `
 	insert into satellites
-	 (sat_name, norad_id, line1, line2) 
-	 values ($1, $2, $3, $4) returning id;
+	 (sat_name, norad_id, line1, line2, omm) 
+	 values ($1, $2, $3, $4, $5) returning id;
 	 `
 
-	row := r.conn.QueryRow(ctx, query, sat.SatName, sat.NoradID, sat.Line1, sat.Line2)
+	row := r.conn.QueryRow(ctx, query, sat.SatName, sat.NoradID, sat.Line1, sat.Line2, sat.OMM)
 	var id int
 	err := row.Scan(&id)
 
@@ -35,8 +35,8 @@ func (r *Repo) CreateSatellite(ctx context.Context, sat Satellite) (int, error)
 func (r *Repo) GetSatellite(ctx context.Context, id int) (Satellite, error) {
 	sat := Satellite{}
 
-	err := r.conn.QueryRow(ctx, "select id, sat_name, norad_id, line1, line2 from satellites where id=$1", id).
-		Scan(&sat.ID, &sat.SatName, &sat.NoradID, &sat.Line1, &sat.Line2)
+	err := r.conn.QueryRow(ctx, "select id, sat_name, norad_id, line1, line2, omm from satellites where id=$1", id).
+		Scan(&sat.ID, &sat.SatName, &sat.NoradID, &sat.Line1, &sat.Line2, &sat.OMM)
 	if err != nil {
 		return Satellite{}, err
 	}
@@ -46,7 +46,7 @@ func (r *Repo) GetSatellite(ctx context.Context, id int) (Satellite, error) {
 
 func (r *Repo) FindSatellite(ctx context.Context, filter FilterSatellite) ([]Satellite, error) {
 	var args []interface{}
-	query := "select id, sat_name, norad_id, line1, line2 from satellites where 1=1"
+	query := "select id, sat_name, norad_id, line1, line2, omm from satellites where 1=1"
 
 	argId := 1
 
@@ -91,7 +91,7 @@ func (r *Repo) FindSatellite(ctx context.Context, filter FilterSatellite) ([]Sat
 	for rows.Next() {
 		var sat Satellite
 
-		err := rows.Scan(&sat.ID, &sat.SatName, &sat.NoradID, &sat.Line1, &sat.Line2)
+		err := rows.Scan(&sat.ID, &sat.SatName, &sat.NoradID, &sat.Line1, &sat.Line2, &sat.OMM)
 		if err != nil {
 			return nil, fmt.Errorf("не удалось вернуть спутник %w", err)
 		}
@@ -109,11 +109,11 @@ func (r *Repo) FindSatellite(ctx context.Context, filter FilterSatellite) ([]Sat
 func (r *Repo) UpdateSatellite(ctx context.Context, sat Satellite) error {
 	query := `
 	 update satellites 
-	 set sat_name = $1, norad_id = $2, line1 = $3, line2 = $4 
-	 where id=$5
+	 set sat_name = $1, norad_id = $2, line1 = $3, line2 = $4, omm = $5 
+	 where id=$6
 	`
 
-	_, err := r.conn.Exec(ctx, query, sat.SatName, sat.NoradID, sat.Line1, sat.Line2, sat.ID)
+	_, err := r.conn.Exec(ctx, query, sat.SatName, sat.NoradID, sat.Line1, sat.Line2, sat.OMM, sat.ID)
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ type Satellite struct {
 	NoradID *int64
 	Line1   string
 	Line2   string
+	OMM     []byte // JSON, nil если спутник задан через TLE
 }
 
 type FilterSatellite struct {
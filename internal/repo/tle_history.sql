@@ -0,0 +1,13 @@
+--- схема таблицы для истории TLE спутников
+
+create table tle_history (
+    id bigserial primary key,
+    satellite_id bigint not null references satellites(id) on delete cascade, --- спутник из таблицы satellites
+    epoch timestamptz not null, --- эпоха элементов
+    line1 text not null,
+    line2 text not null,
+    created_at timestamptz not null default now(), --- когда набор элементов был сохранен
+    unique (satellite_id, line1, line2)
+);
+
+create index tle_history_satellite_epoch on tle_history (satellite_id, epoch);
@@ -0,0 +1,126 @@
+package tlehistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	conn *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *Repo {
+	return &Repo{
+		conn: pool,
+	}
+}
+
+// AddTLE сохраняет набор элементов спутника. Повторно тот же набор не сохраняется.
+func (r *Repo) AddTLE(ctx context.Context, tle TLE) error {
+	query := `
+	insert into tle_history
+	 (satellite_id, epoch, line1, line2)
+	 values ($1, $2, $3, $4)
+	 on conflict (satellite_id, line1, line2) do nothing;
+	 `
+
+	_, err := r.conn.Exec(ctx, query, tle.SatelliteID, tle.Epoch, tle.Line1, tle.Line2)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetTLE возвращает набор элементов из истории по id
+func (r *Repo) GetTLE(ctx context.Context, id int) (TLE, error) {
+	var tle TLE
+
+	err := r.conn.QueryRow(ctx, "select id, satellite_id, epoch, line1, line2, created_at from tle_history where id=$1", id).
+		Scan(&tle.ID, &tle.SatelliteID, &tle.Epoch, &tle.Line1, &tle.Line2, &tle.CreatedAt)
+	if err != nil {
+		return TLE{}, err
+	}
+
+	tle.Epoch = tle.Epoch.UTC()
+
+	return tle, nil
+}
+
+// FindTLE возвращает наборы элементов спутника по возрастанию эпохи
+func (r *Repo) FindTLE(ctx context.Context, filter FilterTLE) ([]TLE, error) {
+	args := []interface{}{filter.SatelliteID}
+	query := "select id, satellite_id, epoch, line1, line2, created_at from tle_history where satellite_id = $1"
+
+	argId := 2
+
+	if filter.From != nil {
+		query += fmt.Sprintf(" AND epoch >= $%d", argId)
+		args = append(args, *filter.From)
+		argId++
+	}
+
+	if filter.To != nil {
+		query += fmt.Sprintf(" AND epoch <= $%d", argId)
+		args = append(args, *filter.To)
+		argId++
+	}
+
+	query += " order by epoch, id"
+
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса FindTLE: %w", err)
+	}
+	defer rows.Close()
+
+	var res []TLE
+
+	for rows.Next() {
+		var tle TLE
+
+		err := rows.Scan(&tle.ID, &tle.SatelliteID, &tle.Epoch, &tle.Line1, &tle.Line2, &tle.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось вернуть TLE %w", err)
+		}
+
+		tle.Epoch = tle.Epoch.UTC()
+		res = append(res, tle)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации по результату из бд: %w", err)
+	}
+
+	return res, nil
+}
+
+// ClosestTLE возвращает набор элементов спутника с эпохой, ближайшей к t. Если история пуста - nil.
+func (r *Repo) ClosestTLE(ctx context.Context, satelliteID int, t time.Time) (*TLE, error) {
+	query := `
+	select id, satellite_id, epoch, line1, line2, created_at from tle_history
+	 where satellite_id = $1
+	 order by abs(extract(epoch from epoch - $2::timestamptz)), id desc
+	 limit 1;
+	 `
+
+	var tle TLE
+
+	err := r.conn.QueryRow(ctx, query, satelliteID, t).
+		Scan(&tle.ID, &tle.SatelliteID, &tle.Epoch, &tle.Line1, &tle.Line2, &tle.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса ClosestTLE: %w", err)
+	}
+
+	tle.Epoch = tle.Epoch.UTC()
+
+	return &tle, nil
+}
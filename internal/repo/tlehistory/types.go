@@ -0,0 +1,18 @@
+package tlehistory
+
+import "time"
+
+type TLE struct {
+	ID          int
+	SatelliteID int
+	Epoch       time.Time
+	Line1       string
+	Line2       string
+	CreatedAt   time.Time
+}
+
+type FilterTLE struct {
+	SatelliteID int
+	From        *time.Time // эпоха не раньше
+	To          *time.Time // эпоха не позже
+}
@@ -30,6 +30,48 @@ func SetupRouter(service *service.Service) *chi.Mux {
 	router.Route("/time-ranges", func(r chi.Router) {
 		r.Post("/", service.VisibleTimeRange)
 	})
+	router.Route("/oem", func(r chi.Router) {
+		r.Post("/", service.OEM)
+	})
+	router.Route("/ical", func(r chi.Router) {
+		r.Get("/{locationId}", service.PassCalendar)
+	})
+	router.Route("/kml", func(r chi.Router) {
+		r.Post("/", service.ExportKML)
+	})
+	router.Route("/czml", func(r chi.Router) {
+		r.Post("/", service.ExportCZML)
+	})
+	router.Route("/beta-angle", func(r chi.Router) {
+		r.Post("/", service.BetaAngle)
+	})
+	router.Route("/nodes", func(r chi.Router) {
+		r.Post("/", service.Nodes)
+	})
+	router.Route("/imaging", func(r chi.Router) {
+		r.Post("/", service.Imaging)
+	})
+	router.Route("/link-budget", func(r chi.Router) {
+		r.Post("/", service.LinkBudget)
+	})
+	router.Route("/data-volume", func(r chi.Router) {
+		r.Post("/", service.DataVolume)
+	})
+	router.Route("/tle-compare", func(r chi.Router) {
+		r.Post("/", service.CompareTLE)
+	})
+	router.Route("/orbit-fit", func(r chi.Router) {
+		r.Post("/", service.OrbitFit)
+	})
+	router.Route("/transits", func(r chi.Router) {
+		r.Post("/", service.Transits)
+	})
+	router.Route("/repeat-cycle", func(r chi.Router) {
+		r.Post("/", service.RepeatCycle)
+	})
+	router.Route("/crossovers", func(r chi.Router) {
+		r.Post("/", service.Crossovers)
+	})
 	router.Route("/satellite", func(r chi.Router) {
 		r.Put("/", service.AddSatellite)
 		r.Post("/", service.FindSatellite) // Keep POST for find as per service/readme
@@ -48,6 +90,41 @@ func SetupRouter(service *service.Service) *chi.Mux {
 			r.Get("/", service.GetLocation)
 		})
 	})
+	router.Route("/rotator", func(r chi.Router) {
+		r.Put("/", service.AddRotator)
+		r.Post("/", service.FindRotator)
+		r.Patch("/", service.UpdateRotator)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Delete("/", service.DeleteRotator)
+			r.Get("/", service.GetRotator)
+		})
+	})
+	router.Route("/geofence", func(r chi.Router) {
+		r.Put("/", service.AddGeofence)
+		r.Post("/", service.FindGeofence)
+		r.Patch("/", service.UpdateGeofence)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Delete("/", service.DeleteGeofence)
+			r.Get("/", service.GetGeofence)
+		})
+	})
+	router.Route("/geofence-events", func(r chi.Router) {
+		r.Post("/", service.GeofenceEvents)
+	})
+	router.Route("/tracking-table", func(r chi.Router) {
+		r.Post("/", service.TrackingTable)
+	})
+	router.Route("/tracking", func(r chi.Router) {
+		r.Put("/", service.StartTracking)
+		r.Post("/", service.ListTracking)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", service.GetTracking)
+			r.Delete("/", service.StopTracking)
+		})
+	})
+	router.Route("/doppler", func(r chi.Router) {
+		r.Put("/", service.StartDoppler)
+	})
 
 	// --- Static file serving for Vue SPA ---
 	workDir, _ := os.Getwd()
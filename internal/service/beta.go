@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+)
+
+// максимальная длительность интервала расчета угла бета, месяцев
+const maxBetaAngleMonths = 60
+
+// POST /beta-angle/
+// Угол бета по суткам, длительность тени на витке и сезоны без тени
+func (s *Service) BetaAngle(w http.ResponseWriter, r *http.Request) {
+	var req BetaAngleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	months := 12
+	if req.Months != nil {
+		months = *req.Months
+	}
+
+	if months <= 0 || months > maxBetaAngleMonths {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("количество месяцев должно быть от 1 до %d", maxBetaAngleMonths)))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, from)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	res, err := sat.BetaAngleReport(from, from.AddDate(0, months, 0))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.BetaAngleReport: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, res)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// максимальное количество точек в сравнении наборов элементов
+const maxCompareStates = 100000
+
+// POST /tle-compare/
+// Разница положений по двум наборам элементов одного объекта (радиальная, вдоль движения и по нормали к орбите)
+func (s *Service) CompareTLE(w http.ResponseWriter, r *http.Request) {
+	var req CompareTLERequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	duration := 24 * time.Hour
+	if req.DurationSeconds != nil {
+		duration = time.Duration(*req.DurationSeconds) * time.Second
+	}
+
+	step := time.Minute
+	if req.StepSeconds != nil {
+		step = time.Duration(*req.StepSeconds) * time.Second
+	}
+
+	if duration < 0 || step <= 0 || duration/step+1 > maxCompareStates {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("некорректный интервал или шаг (не более %d точек)", maxCompareStates)))
+		return
+	}
+
+	profile, err := s.requestProfile(r)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.requestProfile: %w", err).Error()))
+		return
+	}
+
+	reference, err := s.elementSetSatellite(r, req.Reference, profile)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("опорный набор: %w", err).Error()))
+		return
+	}
+
+	other, err := s.elementSetSatellite(r, req.Other, profile)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("сравниваемый набор: %w", err).Error()))
+		return
+	}
+
+	res, err := satellite.Compare(reference, other, from, from.Add(duration), step)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("satellite.Compare: %w", err).Error()))
+		return
+	}
+
+	w.Header().Set(profileHeader, profile.String())
+	render.Write(w, r, res)
+}
+
+// elementSetSatellite создает спутник по набору элементов из запроса сравнения
+func (s *Service) elementSetSatellite(r *http.Request, src ElementSetSource, profile satellite.Profile) (satellite.Satellite, error) {
+	line1, line2 := src.Line1, src.Line2
+
+	switch {
+	case line1 != "" || line2 != "":
+		if _, err := satellite.ParseTLE(line1, line2); err != nil {
+			return satellite.Satellite{}, fmt.Errorf("satellite.ParseTLE: %w", err)
+		}
+	case src.HistoryID != nil:
+		tle, err := s.repoTLEs.GetTLE(r.Context(), *src.HistoryID)
+		if err != nil {
+			return satellite.Satellite{}, fmt.Errorf("s.repoTLEs.GetTLE: %w", err)
+		}
+
+		line1, line2 = tle.Line1, tle.Line2
+	case src.SatelliteID != nil:
+		satRepo, err := s.repoSats.GetSatellite(r.Context(), *src.SatelliteID)
+		if err != nil {
+			return satellite.Satellite{}, fmt.Errorf("s.repo.GetSatellite: %w", err)
+		}
+
+		line1, line2 = satRepo.Line1, satRepo.Line2
+	default:
+		return satellite.Satellite{}, errors.New("нужно задать line1/line2, historyId или satelliteId")
+	}
+
+	return satellite.NewWithProfile(line1, line2, profile)
+}
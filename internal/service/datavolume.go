@@ -0,0 +1,114 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// максимальная длительность интервала расчета объема данных, суток
+const maxDataVolumeDays = 14
+
+// POST /data-volume/
+// Объем данных, который можно принять станциями, по пролетам и по суткам
+func (s *Service) DataVolume(w http.ResponseWriter, r *http.Request) {
+	var req DataVolumeRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	if len(req.LocationIDs) == 0 {
+		w.WriteHeader(400)
+		w.Write([]byte("не заданы станции"))
+		return
+	}
+
+	var rates satellite.BitrateTable
+
+	switch {
+	case req.BitrateBps != nil && len(req.BitrateTable) == 0 && *req.BitrateBps > 0:
+		rates = satellite.BitrateTable{{MinEl: req.MinElevation, Bps: *req.BitrateBps}}
+	case req.BitrateBps == nil && len(req.BitrateTable) > 0:
+		rates = req.BitrateTable
+	default:
+		w.WriteHeader(400)
+		w.Write([]byte("должна быть задана скорость bitrateBps или таблица bitrateTable"))
+		return
+	}
+
+	days := 1
+	if req.Days != nil {
+		days = *req.Days
+	}
+
+	if days < 1 || days > maxDataVolumeDays {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("days должен быть от 1 до %d", maxDataVolumeDays)))
+		return
+	}
+
+	var step time.Duration
+	if req.StepSeconds != nil {
+		step = time.Duration(*req.StepSeconds) * time.Second
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	var stations []satellite.ContactStation
+
+	for _, id := range req.LocationIDs {
+		loc, err := s.repoLocs.GetLocation(r.Context(), id)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation: %w", err).Error()))
+			return
+		}
+
+		stations = append(stations, satellite.ContactStation{
+			ID:   loc.ID,
+			Name: loc.Name,
+			Coords: satellite.ObserverCoords{
+				Lon: loc.Point.Lon,
+				Lat: loc.Point.Lat,
+				Alt: loc.Point.Alt,
+			},
+			Refraction: observerRefraction(loc, nil),
+		})
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, from)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	res, err := sat.DataVolume(stations, from, from.Add(time.Duration(days)*24*time.Hour), req.MinElevation, rates, step)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.DataVolume: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, res)
+}
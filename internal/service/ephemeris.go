@@ -0,0 +1,172 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/ephemeris"
+	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+const (
+	// отправитель в заголовке сообщений CCSDS
+	ccsdsOriginator = "UMKA"
+	// максимальное количество векторов состояния в одном OEM
+	maxOEMStates = 100000
+)
+
+// POST /oem/
+// Эфемериды спутника в формате CCSDS OEM (KVN или XML)
+func (s *Service) OEM(w http.ResponseWriter, r *http.Request) {
+	var req OEMRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	if req.Format != "" && req.Format != "kvn" && req.Format != "xml" {
+		w.WriteHeader(400)
+		w.Write([]byte("формат должен быть kvn или xml"))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	duration := 24 * time.Hour
+	if req.DurationSeconds != nil {
+		duration = time.Duration(*req.DurationSeconds) * time.Second
+	}
+
+	step := time.Minute
+	if req.StepSeconds != nil {
+		step = time.Duration(*req.StepSeconds) * time.Second
+	}
+
+	if duration < 0 || step <= 0 || duration/step+1 > maxOEMStates {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("некорректный интервал или шаг (не более %d точек)", maxOEMStates)))
+		return
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, from)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	states, err := sat.StateVectors(from, from.Add(duration), step)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.StateVectors: %w", err).Error()))
+		return
+	}
+
+	// международное обозначение берем из TLE
+	elements, err := satellite.ParseTLE(satRepo.Line1, satRepo.Line2)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("satellite.ParseTLE: %w", err).Error()))
+		return
+	}
+
+	objectID := elements.ObjectID
+	if objectID == "" {
+		objectID = "UNKNOWN"
+	}
+
+	oem := satellite.OEM{
+		Originator:   ccsdsOriginator,
+		CreationDate: time.Now().UTC(),
+		ObjectName:   satRepo.SatName,
+		ObjectID:     objectID,
+		States:       states,
+	}
+
+	var res []byte
+
+	if req.Format == "xml" {
+		res, err = oem.XML()
+		w.Header().Set("Content-Type", "application/xml")
+	} else {
+		res, err = oem.KVN()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	if err != nil {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("error encoding oem: %w", err).Error()))
+		return
+	}
+
+	w.Write(res)
+}
+
+// setInterpolationError сообщает оценку погрешности положения, полученного из кэша эфемерид, км
+func setInterpolationError(w http.ResponseWriter, errKm *float64) {
+	if errKm == nil {
+		return
+	}
+
+	w.Header().Set("X-Interpolation-Error-Km", strconv.FormatFloat(*errKm, 'g', 3, 64))
+}
+
+// satelliteState рассчитывает вектор состояния спутника на момент t. SGP4 берется из кэша эфемерид
+// (тогда возвращается и оценка погрешности интерполяции, км), другие пропагаторы считаются напрямую.
+// Набор элементов и профиль SGP4 выбираются как в newSatellite.
+func (s *Service) satelliteState(w http.ResponseWriter, r *http.Request, satRepo satellitesRepo.Satellite, t time.Time, kind satellite.PropagatorKind) (satellite.StateVector, *float64, error) {
+	if kind == "" || kind == satellite.PropagatorSGP4 {
+		set, profile, err := s.requestElementSet(w, r, satRepo, t)
+		if err != nil {
+			return satellite.StateVector{}, nil, err
+		}
+
+		sv, errKm, err := s.ephemeris.StateVector(ephemeris.Key{
+			SatelliteID: satRepo.ID,
+			Line1:       set.line1,
+			Line2:       set.line2,
+			Profile:     profile,
+		}, t)
+		if err != nil {
+			return satellite.StateVector{}, nil, err
+		}
+
+		return sv, &errKm, nil
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, t)
+	if err != nil {
+		return satellite.StateVector{}, nil, err
+	}
+
+	sat, err = sat.WithPropagatorKind(kind)
+	if err != nil {
+		return satellite.StateVector{}, nil, err
+	}
+
+	sv, err := sat.StateVector(t)
+	if err != nil {
+		return satellite.StateVector{}, nil, err
+	}
+
+	return sv, nil, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/czml"
+	"github.com/BabyLev/Umka-1/internal/kml"
+	locationsRepo "github.com/BabyLev/Umka-1/internal/repo/locations"
+	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+const (
+	defaultExportDuration = 3 * time.Hour
+	// максимальное количество точек на один спутник
+	maxExportPoints = 20000
+	// количество точек границы зоны видимости
+	footprintPoints = 90
+)
+
+// цвета спутников по порядку (rgba)
+var exportPalette = [][4]int{
+	{255, 200, 0, 255},
+	{0, 200, 255, 255},
+	{255, 80, 80, 255},
+	{120, 255, 120, 255},
+	{200, 120, 255, 255},
+	{255, 255, 255, 255},
+}
+
+// exportData - спутники и станции для выгрузки в KML/CZML
+type exportData struct {
+	from, to time.Time
+	step     time.Duration
+	sats     []satellitesRepo.Satellite
+	locs     []locationsRepo.Location
+}
+
+// loadExport читает запрос выгрузки и загружает спутники и станции.
+// При ошибке ответ уже записан и возвращается false.
+func (s *Service) loadExport(w http.ResponseWriter, r *http.Request) (exportData, bool) {
+	var req ExportRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return exportData{}, false
+	}
+
+	if len(req.SatelliteIDs) == 0 {
+		w.WriteHeader(400)
+		w.Write([]byte("не заданы спутники"))
+		return exportData{}, false
+	}
+
+	var data exportData
+
+	if req.Timestamp == nil {
+		data.from = time.Now().UTC().Truncate(time.Second)
+	} else {
+		data.from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	duration := defaultExportDuration
+	if req.DurationSeconds != nil {
+		duration = time.Duration(*req.DurationSeconds) * time.Second
+	}
+
+	data.step = time.Minute
+	if req.StepSeconds != nil {
+		data.step = time.Duration(*req.StepSeconds) * time.Second
+	}
+
+	if duration <= 0 || data.step <= 0 || duration/data.step+1 > maxExportPoints {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("некорректный интервал или шаг (не более %d точек)", maxExportPoints)))
+		return exportData{}, false
+	}
+
+	data.to = data.from.Add(duration)
+
+	data.sats, err = s.repoSats.FindSatellite(r.Context(), satellitesRepo.FilterSatellite{IDs: req.SatelliteIDs})
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repo.FindSatellite: %w", err).Error()))
+		return exportData{}, false
+	}
+
+	for _, id := range req.LocationIDs {
+		loc, err := s.repoLocs.GetLocation(r.Context(), id)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation: %w", err).Error()))
+			return exportData{}, false
+		}
+
+		data.locs = append(data.locs, loc)
+	}
+
+	return data, true
+}
+
+// POST /kml/
+// Трассы спутников, зоны видимости на начало интервала и станции в формате KML
+func (s *Service) ExportKML(w http.ResponseWriter, r *http.Request) {
+	data, ok := s.loadExport(w, r)
+	if !ok {
+		return
+	}
+
+	doc := kml.Document{
+		Name: "UMKA",
+	}
+
+	for i, satRepo := range data.sats {
+		sat, err := s.newSatellite(w, r, satRepo, data.from)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+			return
+		}
+
+		track, err := sat.GroundTrack(data.from, data.to, data.step)
+		if err != nil {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Errorf("sat.GroundTrack: %w", err).Error()))
+			return
+		}
+
+		position, err := sat.Calculate(data.from)
+		if err != nil {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Errorf("sat.Calculate: %w", err).Error()))
+			return
+		}
+
+		rgba := exportPalette[i%len(exportPalette)]
+
+		doc.Satellites = append(doc.Satellites, kml.Satellite{
+			Name:      satRepo.SatName,
+			Track:     track,
+			Position:  *position,
+			Footprint: satellite.Footprint(*position, footprintPoints),
+			Color:     fmt.Sprintf("%02x%02x%02x%02x", rgba[3], rgba[2], rgba[1], rgba[0]),
+		})
+	}
+
+	for _, loc := range data.locs {
+		doc.Stations = append(doc.Stations, kml.Station{
+			Name: loc.Name,
+			Lat:  loc.Point.Lat,
+			Lon:  loc.Point.Lon,
+			Alt:  loc.Point.Alt,
+		})
+	}
+
+	res, err := doc.Encode()
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.google-earth.kml+xml")
+	w.Header().Set("Content-Disposition", `attachment; filename="umka.kml"`)
+	w.Write(res)
+}
+
+// POST /czml/
+// Положение спутников во времени и линии связи со станциями во время пролетов в формате CZML
+func (s *Service) ExportCZML(w http.ResponseWriter, r *http.Request) {
+	data, ok := s.loadExport(w, r)
+	if !ok {
+		return
+	}
+
+	doc := czml.Document{
+		Name: "UMKA",
+		From: data.from,
+		To:   data.to,
+	}
+
+	for _, loc := range data.locs {
+		doc.Stations = append(doc.Stations, czml.Station{
+			ID:   fmt.Sprintf("location-%d", loc.ID),
+			Name: loc.Name,
+			Lat:  loc.Point.Lat,
+			Lon:  loc.Point.Lon,
+			Alt:  loc.Point.Alt,
+		})
+	}
+
+	for i, satRepo := range data.sats {
+		sat, err := s.newSatellite(w, r, satRepo, data.from)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+			return
+		}
+
+		states, err := sat.StateVectors(data.from, data.to, data.step)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("sat.StateVectors: %w", err).Error()))
+			return
+		}
+
+		satID := fmt.Sprintf("satellite-%d", satRepo.ID)
+
+		doc.Satellites = append(doc.Satellites, czml.Satellite{
+			ID:     satID,
+			Name:   satRepo.SatName,
+			States: states,
+			Color:  exportPalette[i%len(exportPalette)],
+		})
+
+		for _, loc := range data.locs {
+			coords := satellite.ObserverCoords{
+				Lon: loc.Point.Lon,
+				Lat: loc.Point.Lat,
+				Alt: loc.Point.Alt,
+			}
+
+			doc.Links = append(doc.Links, czml.Link{
+				SatelliteID: satID,
+				StationID:   fmt.Sprintf("location-%d", loc.ID),
+				Passes:      sat.WithRefraction(observerRefraction(loc, nil)).PassesBetween(data.from, data.to, coords),
+			})
+		}
+	}
+
+	res, err := doc.Encode()
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/clients/webhook"
+	"github.com/BabyLev/Umka-1/internal/render"
+	geofencesRepo "github.com/BabyLev/Umka-1/internal/repo/geofences"
+	"github.com/BabyLev/Umka-1/satellite"
+	"github.com/go-chi/chi/v5"
+)
+
+// максимальная длительность интервала поиска входов в области и выходов из них
+const maxGeofenceEventsDuration = 7 * 24 * time.Hour
+
+func geofenceFromRepo(fence geofencesRepo.Geofence) Geofence {
+	return Geofence{
+		Name:        fence.Name,
+		GeoJSON:     fence.GeoJSON,
+		SatelliteID: fence.SatelliteID,
+		WebhookURL:  fence.WebhookURL,
+	}
+}
+
+func geofenceToRepo(id int, fence Geofence) geofencesRepo.Geofence {
+	return geofencesRepo.Geofence{
+		ID:          id,
+		Name:        fence.Name,
+		GeoJSON:     fence.GeoJSON,
+		SatelliteID: fence.SatelliteID,
+		WebhookURL:  fence.WebhookURL,
+	}
+}
+
+func (s *Service) AddGeofence(w http.ResponseWriter, r *http.Request) {
+	var req AddGeofenceRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	_, err = satellite.ParseGeoJSON(req.GeoJSON)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("satellite.ParseGeoJSON: %w", err).Error()))
+		return
+	}
+
+	if req.WebhookURL != nil {
+		err = webhook.ValidateURL(r.Context(), *req.WebhookURL)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("некорректный адрес для уведомлений: %w", err).Error()))
+			return
+		}
+	}
+
+	fenceID, err := s.repoFences.CreateGeofence(r.Context(), geofenceToRepo(0, req.Geofence))
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoFences.CreateGeofence: %w", err).Error()))
+		return
+	}
+
+	res := AddGeofenceResponse{
+		ID: fenceID,
+	}
+
+	render.Write(w, r, res)
+}
+
+func (s *Service) GetGeofence(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("ID невозможно преобразовать в число: %w", err).Error()))
+		return
+	}
+
+	fence, err := s.repoFences.GetGeofence(r.Context(), idInt)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoFences.GetGeofence: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, geofenceFromRepo(fence))
+}
+
+func (s *Service) DeleteGeofence(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if i, err := strconv.Atoi(id); err == nil {
+		err := s.repoFences.DeleteGeofence(r.Context(), i)
+		if err != nil {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Errorf("s.repoFences.DeleteGeofence: %w", err).Error()))
+			return
+		}
+	} else {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("не удалось преобразовать ID к целому числу: %w", err).Error()))
+		return
+	}
+
+	w.WriteHeader(200)
+	w.Write([]byte(fmt.Sprintf("область успешно удалилась id = %s", id)))
+}
+
+func (s *Service) FindGeofence(w http.ResponseWriter, r *http.Request) {
+	var req FindGeofenceRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	fences, err := s.repoFences.FindGeofence(r.Context(), geofencesRepo.FilterGeofence{
+		Name: req.Name,
+	})
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoFences.FindGeofence: %w", err).Error()))
+		return
+	}
+
+	res := FindGeofenceResponse{
+		Geofences: make(map[int]Geofence, len(fences)),
+	}
+
+	for _, fence := range fences {
+		res.Geofences[fence.ID] = geofenceFromRepo(fence)
+	}
+
+	render.Write(w, r, res)
+}
+
+func (s *Service) UpdateGeofence(w http.ResponseWriter, r *http.Request) {
+	var req UpdateGeofenceRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	_, err = satellite.ParseGeoJSON(req.Geofence.GeoJSON)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("satellite.ParseGeoJSON: %w", err).Error()))
+		return
+	}
+
+	if req.Geofence.WebhookURL != nil {
+		err = webhook.ValidateURL(r.Context(), *req.Geofence.WebhookURL)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("некорректный адрес для уведомлений: %w", err).Error()))
+			return
+		}
+	}
+
+	err = s.repoFences.UpdateGeofence(r.Context(), geofenceToRepo(req.GeofenceID, req.Geofence))
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoFences.UpdateGeofence: %w", err).Error()))
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// POST /geofence-events/
+// Входы подспутниковой точки в области и выходы из них за интервал
+func (s *Service) GeofenceEvents(w http.ResponseWriter, r *http.Request) {
+	var req GeofenceEventsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	duration := 24 * time.Hour
+	if req.DurationSeconds != nil {
+		duration = time.Duration(*req.DurationSeconds) * time.Second
+	}
+
+	if duration <= 0 || duration > maxGeofenceEventsDuration {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("длительность интервала должна быть от 1 секунды до %d суток", int(maxGeofenceEventsDuration.Hours()/24))))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	var repoFences []geofencesRepo.Geofence
+
+	if len(req.GeofenceIDs) == 0 {
+		repoFences, err = s.repoFences.FindGeofence(r.Context(), geofencesRepo.FilterGeofence{})
+		if err != nil {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Errorf("s.repoFences.FindGeofence: %w", err).Error()))
+			return
+		}
+	}
+
+	for _, id := range req.GeofenceIDs {
+		fence, err := s.repoFences.GetGeofence(r.Context(), id)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("s.repoFences.GetGeofence(%d): %w", id, err).Error()))
+			return
+		}
+
+		repoFences = append(repoFences, fence)
+	}
+
+	fences := make([]satellite.Geofence, 0, len(repoFences))
+	for _, fence := range repoFences {
+		polygons, err := satellite.ParseGeoJSON(fence.GeoJSON)
+		if err != nil {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Errorf("satellite.ParseGeoJSON(%d): %w", fence.ID, err).Error()))
+			return
+		}
+
+		fences = append(fences, satellite.Geofence{ID: fence.ID, Name: fence.Name, Polygons: polygons})
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, from)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	reports, err := sat.GeofenceEvents(from, from.Add(duration), fences)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.GeofenceEvents: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, GeofenceEventsResponse{Geofences: reports})
+}
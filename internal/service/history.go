@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
+	tleHistoryRepo "github.com/BabyLev/Umka-1/internal/repo/tlehistory"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// saveTLEHistory сохраняет текущий TLE спутника в историю наборов элементов
+func (s *Service) saveTLEHistory(ctx context.Context, satID int, line1, line2 string) error {
+	elements, err := satellite.ParseTLE(line1, line2)
+	if err != nil {
+		return fmt.Errorf("satellite.ParseTLE: %w", err)
+	}
+
+	return s.repoTLEs.AddTLE(ctx, tleHistoryRepo.TLE{
+		SatelliteID: satID,
+		Epoch:       elements.Epoch,
+		Line1:       line1,
+		Line2:       line2,
+	})
+}
+
+// elementSet - набор элементов, которым считается спутник
+type elementSet struct {
+	// id в tle_history, 0 - TLE из таблицы satellites, которого нет в истории
+	historyID int
+	epoch     time.Time
+	line1     string
+	line2     string
+}
+
+// выбранный набор элементов запоминается на этот шаг по времени: частые запросы на близкие моменты
+// (карта опрашивает положение спутника раз в секунду) не ходят каждый раз в историю TLE
+const elementSetCacheStep = time.Minute
+
+// elementSetCache хранит последний выбранный набор элементов каждого спутника
+type elementSetCache struct {
+	mu   sync.Mutex
+	sets map[int]cachedElementSet
+}
+
+// cachedElementSet - набор элементов, выбранный для моментов из шага bucket при текущем TLE
+// спутника line1/line2
+type cachedElementSet struct {
+	line1  string
+	line2  string
+	bucket time.Time
+	set    elementSet
+}
+
+func newElementSetCache() *elementSetCache {
+	return &elementSetCache{
+		sets: make(map[int]cachedElementSet),
+	}
+}
+
+func (c *elementSetCache) get(satRepo satellitesRepo.Satellite, bucket time.Time) (elementSet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.sets[satRepo.ID]
+	if !ok || cached.line1 != satRepo.Line1 || cached.line2 != satRepo.Line2 || !cached.bucket.Equal(bucket) {
+		return elementSet{}, false
+	}
+
+	return cached.set, true
+}
+
+func (c *elementSetCache) put(satRepo satellitesRepo.Satellite, bucket time.Time, set elementSet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sets[satRepo.ID] = cachedElementSet{
+		line1:  satRepo.Line1,
+		line2:  satRepo.Line2,
+		bucket: bucket,
+		set:    set,
+	}
+}
+
+func (c *elementSetCache) invalidate(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.sets, id)
+}
+
+// invalidateSatellite сбрасывает выбранный набор элементов и таблицы эфемерид спутника id
+// (после обновления TLE и истории или удаления спутника)
+func (s *Service) invalidateSatellite(id int) {
+	s.elementSets.invalidate(id)
+	s.ephemeris.Invalidate(id)
+}
+
+// closestElementSet выбирает набор элементов спутника с эпохой, ближайшей к t: из истории
+// или текущий TLE спутника, если он ближе (или истории нет). Выбор запоминается на elementSetCacheStep.
+func (s *Service) closestElementSet(ctx context.Context, satRepo satellitesRepo.Satellite, t time.Time) (elementSet, error) {
+	bucket := t.Truncate(elementSetCacheStep)
+	if set, ok := s.elementSets.get(satRepo, bucket); ok {
+		return set, nil
+	}
+
+	current, err := satellite.ParseTLE(satRepo.Line1, satRepo.Line2)
+	if err != nil {
+		return elementSet{}, fmt.Errorf("satellite.ParseTLE: %w", err)
+	}
+
+	res := elementSet{
+		epoch: current.Epoch,
+		line1: satRepo.Line1,
+		line2: satRepo.Line2,
+	}
+
+	tle, err := s.repoTLEs.ClosestTLE(ctx, satRepo.ID, t)
+	if err != nil {
+		return elementSet{}, fmt.Errorf("s.repoTLEs.ClosestTLE: %w", err)
+	}
+
+	if tle != nil && (tle.Line1 == satRepo.Line1 && tle.Line2 == satRepo.Line2 || absDuration(t.Sub(tle.Epoch)) < absDuration(t.Sub(res.epoch))) {
+		res = elementSet{
+			historyID: tle.ID,
+			epoch:     tle.Epoch,
+			line1:     tle.Line1,
+			line2:     tle.Line2,
+		}
+	}
+
+	s.elementSets.put(satRepo, bucket, res)
+
+	return res, nil
+}
+
+// setElementSet сообщает в заголовках ответа, каким набором элементов посчитан спутник и насколько
+// его эпоха отстоит от момента расчета t (часы; отрицательный возраст - эпоха позже t)
+func setElementSet(w http.ResponseWriter, set elementSet, t time.Time) {
+	w.Header().Set("X-TLE-Epoch", set.epoch.Format(time.RFC3339Nano))
+	w.Header().Set("X-TLE-Age-Hours", strconv.FormatFloat(t.Sub(set.epoch).Hours(), 'f', 2, 64))
+
+	if set.historyID != 0 {
+		w.Header().Set("X-TLE-History-ID", strconv.Itoa(set.historyID))
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
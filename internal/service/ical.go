@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/ical"
+	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
+	"github.com/BabyLev/Umka-1/satellite"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// на сколько дней вперед по умолчанию строится календарь пролетов
+	defaultICalDays = 7
+	maxICalDays     = 30
+	// как часто календарным приложениям обновлять подписку
+	icalRefreshInterval = time.Hour
+)
+
+// GET /ical/{locationId}?satelliteIds=1,2&days=7
+// Календарь ближайших пролетов спутников над локацией в формате iCalendar (RFC 5545).
+// Без satelliteIds в календарь попадают все спутники из хранилища.
+func (s *Service) PassCalendar(w http.ResponseWriter, r *http.Request) {
+	locID, err := strconv.Atoi(chi.URLParam(r, "locationId"))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("ID локации невозможно преобразовать в число: %w", err).Error()))
+		return
+	}
+
+	var filter satellitesRepo.FilterSatellite
+
+	if ids := r.URL.Query().Get("satelliteIds"); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			idInt, err := strconv.Atoi(strings.TrimSpace(id))
+			if err != nil {
+				w.WriteHeader(400)
+				w.Write([]byte(fmt.Errorf("ID спутника невозможно преобразовать в число: %w", err).Error()))
+				return
+			}
+
+			filter.IDs = append(filter.IDs, idInt)
+		}
+	}
+
+	days := defaultICalDays
+	if d := r.URL.Query().Get("days"); d != "" {
+		days, err = strconv.Atoi(d)
+		if err != nil || days < 1 || days > maxICalDays {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Sprintf("days должен быть от 1 до %d", maxICalDays)))
+			return
+		}
+	}
+
+	obsLoc, err := s.repoLocs.GetLocation(r.Context(), locID)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation: %w", err).Error()))
+		return
+	}
+
+	sats, err := s.repoSats.FindSatellite(r.Context(), filter)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repo.FindSatellite: %w", err).Error()))
+		return
+	}
+
+	coords := satellite.ObserverCoords{
+		Lon: obsLoc.Point.Lon,
+		Lat: obsLoc.Point.Lat,
+		Alt: obsLoc.Point.Alt,
+	}
+
+	now := time.Now().UTC()
+	end := now.Add(time.Duration(days) * 24 * time.Hour)
+
+	cal := ical.Calendar{
+		Name:            fmt.Sprintf("Пролеты над %s", obsLoc.Name),
+		RefreshInterval: icalRefreshInterval,
+	}
+
+	for _, satRepo := range sats {
+		sat, err := s.newSatellite(w, r, satRepo, now)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+			return
+		}
+
+		sat = sat.WithRefraction(observerRefraction(obsLoc, nil))
+
+		// пролет, который уже идет, тоже попадает в календарь
+		for _, tr := range sat.PassesBetween(now, end, coords) {
+			pass := sat.PassDetails(tr, coords)
+
+			cal.Events = append(cal.Events, passEvent(sat, satRepo, locID, obsLoc.Name, pass))
+		}
+	}
+
+	sort.Slice(cal.Events, func(i, j int) bool {
+		return cal.Events[i].Start.Before(cal.Events[j].Start)
+	})
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="passes-%d.ics"`, locID))
+	w.Write(cal.Encode(now))
+}
+
+func passEvent(sat satellite.Satellite, satRepo satellitesRepo.Satellite, locID int, locName string, pass satellite.PassDetails) ical.Event {
+	// UID не зависит от времени восхода: при обновлении TLE и для пролета, который уже идет,
+	// событие изменится, а не продублируется. За виток над локацией бывает не больше одного пролета.
+	uid := fmt.Sprintf("%d-%d-%d@umka", satRepo.ID, locID, pass.MaxElTime.Unix())
+	if rev, ok := sat.RevNumber(pass.MaxElTime); ok {
+		uid = fmt.Sprintf("%d-%d-rev%d@umka", satRepo.ID, locID, rev)
+	}
+
+	return ical.Event{
+		UID:     uid,
+		Start:   pass.From,
+		End:     pass.To,
+		Summary: fmt.Sprintf("%s, %.0f°", satRepo.SatName, pass.MaxEl),
+		Description: fmt.Sprintf(
+			"Восход: %s UTC, азимут %.0f°\nКульминация: %s UTC, угол места %.1f°, азимут %.0f°\nЗаход: %s UTC, азимут %.0f°\nДлительность: %s",
+			pass.From.UTC().Format("15:04:05"), pass.AOSAz,
+			pass.MaxElTime.UTC().Format("15:04:05"), pass.MaxEl, pass.MaxElAz,
+			pass.To.UTC().Format("15:04:05"), pass.LOSAz,
+			pass.To.Sub(pass.From).Round(time.Second),
+		),
+		Location: locName,
+	}
+}
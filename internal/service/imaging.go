@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// максимальная длительность интервала поиска окон съемки
+const maxImagingDuration = 30 * 24 * time.Hour
+
+// POST /imaging/
+// Окна съемки наземной цели (точки или многоугольника) и статистика повторного доступа
+func (s *Service) Imaging(w http.ResponseWriter, r *http.Request) {
+	var req ImagingRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	var target []satellite.GeoPoint
+
+	switch {
+	case req.Target.Point != nil && len(req.Target.Polygon) == 0:
+		target = []satellite.GeoPoint{*req.Target.Point}
+	case req.Target.Point == nil && len(req.Target.Polygon) >= 3:
+		target = req.Target.Polygon
+	default:
+		w.WriteHeader(400)
+		w.Write([]byte("цель должна быть задана точкой point или многоугольником polygon из трех и более вершин"))
+		return
+	}
+
+	duration := 7 * 24 * time.Hour
+	if req.DurationSeconds != nil {
+		duration = time.Duration(*req.DurationSeconds) * time.Second
+	}
+
+	if duration <= 0 || duration > maxImagingDuration {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("длительность интервала должна быть от 1 секунды до %d суток", int(maxImagingDuration.Hours()/24))))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, from)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	accesses, err := sat.ImagingAccesses(from, from.Add(duration), target, satellite.Sensor{
+		HalfAngle:       req.HalfAngle,
+		MaxOffNadir:     req.MaxOffNadir,
+		MinSunElevation: req.MinSunElevation,
+	})
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.ImagingAccesses: %w", err).Error()))
+		return
+	}
+
+	res := ImagingResponse{
+		Accesses: accesses,
+		Revisit:  satellite.Revisit(accesses),
+	}
+
+	render.Write(w, r, res)
+}
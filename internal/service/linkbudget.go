@@ -0,0 +1,106 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// максимальное количество пролетов в одном расчете энергетики
+const maxLinkBudgetPasses = 20
+
+// POST /link-budget/
+// Энергетика радиолинии (потери, ОСШ или Eb/N0, запас) на ближайших пролетах спутника над станцией
+func (s *Service) LinkBudget(w http.ResponseWriter, r *http.Request) {
+	var req LinkBudgetRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	if err := req.Link.Valid(); err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("некорректные параметры радиолинии: %w", err).Error()))
+		return
+	}
+
+	if req.Refraction != nil && !req.Refraction.Valid() {
+		w.WriteHeader(400)
+		w.Write([]byte("некорректная модель рефракции"))
+		return
+	}
+
+	count := 1
+	if req.CountOfTimeRanges != nil {
+		count = *req.CountOfTimeRanges
+	}
+
+	if count < 1 || count > maxLinkBudgetPasses {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("количество пролетов должно быть от 1 до %d", maxLinkBudgetPasses)))
+		return
+	}
+
+	step := satellite.DefaultLinkBudgetStep
+	if req.StepSeconds != nil && *req.StepSeconds > 0 {
+		step = time.Duration(*req.StepSeconds) * time.Second
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	obsLoc, err := s.repoLocs.GetLocation(r.Context(), int(req.ObserverPositionID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation: %w", err).Error()))
+		return
+	}
+
+	var t time.Time
+
+	if req.Timestamp == nil {
+		t = time.Now().UTC()
+	} else {
+		t = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	coords := satellite.ObserverCoords{
+		Lon: obsLoc.Point.Lon,
+		Lat: obsLoc.Point.Lat,
+		Alt: obsLoc.Point.Alt,
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, t)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	sat = sat.WithRefraction(observerRefraction(obsLoc, req.Refraction))
+
+	timeRanges := sat.VisibleTimeRange(t, coords, count)
+
+	if passPoints(timeRanges, step) > maxPassPoints {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("слишком много точек (не более %d), увеличьте шаг или уменьшите количество пролетов", maxPassPoints)))
+		return
+	}
+
+	res := []satellite.LinkPass{}
+	for _, tr := range timeRanges {
+		res = append(res, sat.LinkBudget(tr, coords, req.Link, step))
+	}
+
+	render.Write(w, r, res)
+}
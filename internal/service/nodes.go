@@ -0,0 +1,159 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	tleHistoryRepo "github.com/BabyLev/Umka-1/internal/repo/tlehistory"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// максимальная длительность интервала поиска пересечений экватора
+const maxNodesDuration = 31 * 24 * time.Hour
+
+// POST /nodes/
+// Пересечения экватора, местное время восходящего узла (LTAN) и его дрейф по истории TLE
+func (s *Service) Nodes(w http.ResponseWriter, r *http.Request) {
+	var req NodesRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	duration := 24 * time.Hour
+	if req.DurationSeconds != nil {
+		duration = time.Duration(*req.DurationSeconds) * time.Second
+	}
+
+	if duration <= 0 || duration > maxNodesDuration {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("длительность интервала должна быть от 1 секунды до %d суток", int(maxNodesDuration.Hours()/24))))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, from)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	crossings, err := sat.NodeCrossings(from, from.Add(duration))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.NodeCrossings: %w", err).Error()))
+		return
+	}
+
+	history, err := s.repoTLEs.FindTLE(r.Context(), tleHistoryRepo.FilterTLE{SatelliteID: satRepo.ID})
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoTLEs.FindTLE: %w", err).Error()))
+		return
+	}
+
+	// спутники, созданные до появления истории, - только текущий TLE
+	if len(history) == 0 {
+		history = []tleHistoryRepo.TLE{{Line1: satRepo.Line1, Line2: satRepo.Line2}}
+	}
+
+	ltanHistory, err := ltanRecords(history)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ltanRecords: %w", err).Error()))
+		return
+	}
+
+	res := NodesResponse{
+		Crossings:   crossings,
+		LTANHistory: ltanHistory,
+	}
+
+	if ltan, ok := meanAscendingLocalTime(crossings); ok {
+		res.LTAN = &ltan
+		res.LTANString = satellite.FormatLocalTime(ltan)
+	}
+
+	render.Write(w, r, res)
+}
+
+// ltanRecords рассчитывает LTAN на эпоху каждого TLE и его изменение относительно предыдущего
+func ltanRecords(history []tleHistoryRepo.TLE) ([]LTANRecord, error) {
+	res := make([]LTANRecord, 0, len(history))
+
+	for i, tle := range history {
+		elements, err := satellite.ParseTLE(tle.Line1, tle.Line2)
+		if err != nil {
+			return nil, fmt.Errorf("satellite.ParseTLE: %w", err)
+		}
+
+		record := LTANRecord{
+			Epoch:      elements.Epoch,
+			LTAN:       elements.LTAN(),
+			LTANString: satellite.FormatLocalTime(elements.LTAN()),
+		}
+
+		if i > 0 {
+			prev := res[i-1]
+
+			// разница времени по кругу 24 ч, в минутах
+			drift := (math.Mod(record.LTAN-prev.LTAN+36, 24) - 12) * 60
+			record.DriftMinutes = &drift
+
+			if days := record.Epoch.Sub(prev.Epoch).Hours() / 24; days > 0 {
+				perDay := drift / days
+				record.DriftMinutesPerDay = &perDay
+			}
+		}
+
+		res = append(res, record)
+	}
+
+	return res, nil
+}
+
+// meanAscendingLocalTime возвращает среднее (по кругу 24 ч) местное время восходящих узлов
+func meanAscendingLocalTime(crossings []satellite.NodeCrossing) (float64, bool) {
+	var sin, cos float64
+	var n int
+
+	for _, c := range crossings {
+		if c.Node != "ascending" {
+			continue
+		}
+
+		angle := c.LocalTime / 24 * 2 * math.Pi
+		sin += math.Sin(angle)
+		cos += math.Cos(angle)
+		n++
+	}
+
+	if n == 0 {
+		return 0, false
+	}
+
+	mean := math.Atan2(sin, cos) / (2 * math.Pi) * 24
+
+	return math.Mod(mean+24, 24), true
+}
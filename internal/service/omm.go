@@ -0,0 +1,48 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// applyOMM разбирает поле omm спутника и заполняет по нему line1/line2.
+// OMM может прийти JSON-объектом (как у CelesTrak) или строкой в формате KVN, XML или JSON.
+// Имя и номер NORAD берутся из OMM, если не заданы в запросе.
+// В поле omm сохраняется нормализованный JSON.
+func applyOMM(sat *Satellite) error {
+	data := []byte(sat.OMM)
+
+	var text string
+	if json.Unmarshal(sat.OMM, &text) == nil {
+		data = []byte(text)
+	}
+
+	elements, err := satellite.ParseOMM(data)
+	if err != nil {
+		return err
+	}
+
+	// номер, который не помещается в TLE, хранится только в norad_id и omm
+	sat.Line1, sat.Line2, err = elements.PropagationTLE()
+	if err != nil {
+		return fmt.Errorf("ошибка преобразования OMM в TLE: %w", err)
+	}
+
+	if sat.Name == "" {
+		sat.Name = elements.ObjectName
+	}
+
+	if sat.NoradID == nil && elements.CatalogNumber > 0 {
+		noradID := int64(elements.CatalogNumber)
+		sat.NoradID = &noradID
+	}
+
+	sat.OMM, err = json.Marshal(elements.OMM())
+	if err != nil {
+		return fmt.Errorf("error marshalling omm: %w", err)
+	}
+
+	return nil
+}
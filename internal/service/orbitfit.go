@@ -0,0 +1,154 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	locationsRepo "github.com/BabyLev/Umka-1/internal/repo/locations"
+	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// POST /orbit-fit/
+// Определение орбиты по измерениям направлений со станций и векторам состояния из телеметрии:
+// подгонка TLE дифференциальной коррекцией и сохранение его спутнику
+func (s *Service) OrbitFit(w http.ResponseWriter, r *http.Request) {
+	var req OrbitFitRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	profile, err := s.requestProfile(r)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.requestProfile: %w", err).Error()))
+		return
+	}
+
+	// номер по каталогу, обозначение и номер витка берутся из текущих элементов спутника
+	var template satellite.Elements
+
+	if len(satRepo.OMM) > 0 {
+		template, err = satellite.ParseOMM(satRepo.OMM)
+	} else {
+		template, err = satellite.ParseTLE(satRepo.Line1, satRepo.Line2)
+	}
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка разбора элементов спутника: %w", err).Error()))
+		return
+	}
+
+	locs := make(map[int]locationsRepo.Location)
+	angles := make([]satellite.AnglesObservation, 0, len(req.Angles))
+
+	for _, o := range req.Angles {
+		loc, ok := locs[o.LocationID]
+		if !ok {
+			loc, err = s.repoLocs.GetLocation(r.Context(), o.LocationID)
+			if err != nil {
+				w.WriteHeader(400)
+				w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation(%d): %w", o.LocationID, err).Error()))
+				return
+			}
+
+			locs[o.LocationID] = loc
+		}
+
+		angles = append(angles, satellite.AnglesObservation{
+			Time: o.Time,
+			Observer: satellite.ObserverCoords{
+				Lon: loc.Point.Lon,
+				Lat: loc.Point.Lat,
+				Alt: loc.Point.Alt,
+			},
+			Refraction: observerRefraction(loc, nil),
+			Az:         o.Az,
+			El:         o.El,
+		})
+	}
+
+	opts := satellite.FitOptions{
+		Profile:       profile,
+		Template:      template,
+		FitBStar:      req.FitBStar,
+		AnglesSigma:   req.AnglesSigma,
+		PositionSigma: req.PositionSigma,
+		VelocitySigma: req.VelocitySigma,
+	}
+
+	if req.UseCurrentTLE {
+		opts.Initial = &template
+	}
+
+	opts.Template.ElementSetNo++
+
+	fit, err := satellite.FitOrbit(angles, req.States, opts)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("satellite.FitOrbit: %w", err).Error()))
+		return
+	}
+
+	w.Header().Set(profileHeader, profile.String())
+
+	res := OrbitFitResponse{
+		OrbitFit: fit,
+	}
+
+	if req.DryRun {
+		render.Write(w, r, res)
+		return
+	}
+
+	updated := satellitesRepo.Satellite{
+		ID:      satRepo.ID,
+		SatName: satRepo.SatName,
+		NoradID: satRepo.NoradID,
+		Line1:   fit.Line1,
+		Line2:   fit.Line2,
+	}
+
+	// спутник, заданный через OMM, хранит элементы и в OMM
+	if len(satRepo.OMM) > 0 {
+		updated.OMM, err = json.Marshal(fit.Elements.OMM())
+		if err != nil {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Errorf("error marshalling omm: %w", err).Error()))
+			return
+		}
+	}
+
+	err = s.repoSats.UpdateSatellite(r.Context(), updated)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repo.UpdateSatellite: %w", err).Error()))
+		return
+	}
+
+	// кэши сбрасываются и после того, как TLE попадет в историю
+	defer s.invalidateSatellite(satRepo.ID)
+
+	err = s.saveTLEHistory(r.Context(), satRepo.ID, fit.Line1, fit.Line2)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.saveTLEHistory: %w", err).Error()))
+		return
+	}
+
+	res.Saved = true
+
+	render.Write(w, r, res)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// заголовок, в котором можно передать профиль расчета SGP4 ("wgs84/afspc") и в котором
+// сообщается профиль, с которым сделан расчет
+const profileHeader = "X-Propagation-Profile"
+
+// requestProfile возвращает профиль SGP4 из заголовка запроса, а если он не задан - профиль из конфига
+func (s *Service) requestProfile(r *http.Request) (satellite.Profile, error) {
+	header := r.Header.Get(profileHeader)
+	if header == "" {
+		return s.profile, nil
+	}
+
+	profile, err := satellite.ParseProfile(header)
+	if err != nil {
+		return satellite.Profile{}, fmt.Errorf("заголовок %s: %w", profileHeader, err)
+	}
+
+	return profile, nil
+}
+
+// newSatellite создает спутник для расчета на момент t (для интервала - на его начало): с набором
+// элементов из истории, эпоха которого ближе всего к t, и с профилем SGP4 запроса. Набор элементов
+// и профиль сообщаются в заголовках ответа.
+func (s *Service) newSatellite(w http.ResponseWriter, r *http.Request, satRepo satellitesRepo.Satellite, t time.Time) (satellite.Satellite, error) {
+	set, profile, err := s.requestElementSet(w, r, satRepo, t)
+	if err != nil {
+		return satellite.Satellite{}, err
+	}
+
+	return satellite.NewWithProfile(set.line1, set.line2, profile)
+}
+
+// requestElementSet выбирает набор элементов и профиль SGP4 для расчета на момент t, как newSatellite,
+// но не создает спутник
+func (s *Service) requestElementSet(w http.ResponseWriter, r *http.Request, satRepo satellitesRepo.Satellite, t time.Time) (elementSet, satellite.Profile, error) {
+	profile, err := s.requestProfile(r)
+	if err != nil {
+		return elementSet{}, satellite.Profile{}, err
+	}
+
+	set, err := s.closestElementSet(r.Context(), satRepo, t)
+	if err != nil {
+		return elementSet{}, satellite.Profile{}, err
+	}
+
+	w.Header().Set(profileHeader, profile.String())
+	setElementSet(w, set, t)
+
+	return set, profile, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	locationsRepo "github.com/BabyLev/Umka-1/internal/repo/locations"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+func refractionToRepo(refr *satellite.Refraction) *locationsRepo.Refraction {
+	if refr == nil {
+		return nil
+	}
+
+	return &locationsRepo.Refraction{
+		Model:       string(refr.Model),
+		Pressure:    refr.Pressure,
+		Temperature: refr.Temperature,
+	}
+}
+
+func refractionFromRepo(refr *locationsRepo.Refraction) *satellite.Refraction {
+	if refr == nil {
+		return nil
+	}
+
+	return &satellite.Refraction{
+		Model:       satellite.RefractionModel(refr.Model),
+		Pressure:    refr.Pressure,
+		Temperature: refr.Temperature,
+	}
+}
+
+// observerRefraction выбирает модель рефракции: из запроса, если она передана, иначе из настроек локации
+func observerRefraction(loc locationsRepo.Location, override *satellite.Refraction) satellite.Refraction {
+	if override != nil {
+		return *override
+	}
+
+	if refr := refractionFromRepo(loc.Refraction); refr != nil {
+		return *refr
+	}
+
+	return satellite.Refraction{Model: satellite.RefractionNone}
+}
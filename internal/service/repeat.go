@@ -0,0 +1,122 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+)
+
+const (
+	// наибольший цикл повторения трассы, который ищется, узловых суток
+	maxRepeatCycleDays = 366
+	// максимальная длительность интервала поиска пересечений трасс, сут
+	maxCrossoverDays = 14
+)
+
+// POST /repeat-cycle/
+// Повторяемость трассы и расстояние между соседними трассами с учетом дрейфа из-за J2
+func (s *Service) RepeatCycle(w http.ResponseWriter, r *http.Request) {
+	var req RepeatCycleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	tolerance := 5.0
+	if req.ToleranceKm != nil {
+		tolerance = *req.ToleranceKm
+	}
+
+	maxDays := 60
+	if req.MaxDays != nil {
+		maxDays = *req.MaxDays
+	}
+
+	if maxDays < 1 || maxDays > maxRepeatCycleDays {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("наибольший цикл должен быть от 1 до %d суток", maxRepeatCycleDays)))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, time.Now().UTC())
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	res, err := sat.RepeatCycle(tolerance, maxDays)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.RepeatCycle: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, res)
+}
+
+// POST /crossovers/
+// Пересечения трасс восходящих и нисходящих витков внутри области
+func (s *Service) Crossovers(w http.ResponseWriter, r *http.Request) {
+	var req CrossoversRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	days := 3
+	if req.Days != nil {
+		days = *req.Days
+	}
+
+	if days < 1 || days > maxCrossoverDays {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("длительность интервала должна быть от 1 до %d суток", maxCrossoverDays)))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, from)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	crossovers, err := sat.Crossovers(from, from.AddDate(0, 0, days), req.Region)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.Crossovers: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, CrossoversResponse{Crossovers: crossovers})
+}
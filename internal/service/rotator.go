@@ -0,0 +1,283 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	rotatorsRepo "github.com/BabyLev/Umka-1/internal/repo/rotators"
+	"github.com/BabyLev/Umka-1/satellite"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// максимальное количество пролетов в одном запросе таблиц наведения
+	maxTrackingTablePasses = 20
+	// максимальное количество точек во всех таблицах одного запроса: пролет над станцией на
+	// геостационарной или высокоэллиптической орбите может длиться сутками
+	maxPassPoints = 100000
+)
+
+func rotatorFromRepo(rot rotatorsRepo.Rotator) Rotator {
+	return Rotator{
+		Name:       rot.Name,
+		LocationID: rot.LocationID,
+		Address:    rot.Address,
+		Profile: satellite.RotatorProfile{
+			Mode:       satellite.RotatorMode(rot.Mode),
+			MinAz:      rot.MinAz,
+			MaxAz:      rot.MaxAz,
+			MinEl:      rot.MinEl,
+			MaxEl:      rot.MaxEl,
+			AzSlewRate: rot.AzSlewRate,
+			ElSlewRate: rot.ElSlewRate,
+		},
+	}
+}
+
+func rotatorToRepo(id int, rot Rotator) rotatorsRepo.Rotator {
+	return rotatorsRepo.Rotator{
+		ID:         id,
+		Name:       rot.Name,
+		LocationID: rot.LocationID,
+		Address:    rot.Address,
+		Mode:       string(rot.Profile.Mode),
+		MinAz:      rot.Profile.MinAz,
+		MaxAz:      rot.Profile.MaxAz,
+		MinEl:      rot.Profile.MinEl,
+		MaxEl:      rot.Profile.MaxEl,
+		AzSlewRate: rot.Profile.AzSlewRate,
+		ElSlewRate: rot.Profile.ElSlewRate,
+	}
+}
+
+func (s *Service) AddRotator(w http.ResponseWriter, r *http.Request) {
+	var req AddRotatorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	req.Profile = req.Profile.WithDefaults()
+	if !req.Profile.Valid() {
+		w.WriteHeader(400)
+		w.Write([]byte("некорректный профиль ротатора"))
+		return
+	}
+
+	rotID, err := s.repoRots.CreateRotator(r.Context(), rotatorToRepo(0, req.Rotator))
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoRots.CreateRotator: %w", err).Error()))
+		return
+	}
+
+	res := AddRotatorResponse{
+		ID: rotID,
+	}
+
+	render.Write(w, r, res)
+}
+
+func (s *Service) GetRotator(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("ID невозможно преобразовать в число: %w", err).Error()))
+		return
+	}
+
+	rot, err := s.repoRots.GetRotator(r.Context(), idInt)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoRots.GetRotator: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, rotatorFromRepo(rot))
+}
+
+func (s *Service) DeleteRotator(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if i, err := strconv.Atoi(id); err == nil {
+		err := s.repoRots.DeleteRotator(r.Context(), i)
+		if err != nil {
+			w.WriteHeader(500)
+			w.Write([]byte(fmt.Errorf("s.repoRots.DeleteRotator: %w", err).Error()))
+			return
+		}
+	} else {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("не удалось преобразовать ID к целому числу: %w", err).Error()))
+		return
+	}
+
+	w.WriteHeader(200)
+	w.Write([]byte(fmt.Sprintf("ротатор успешно удалился id = %s", id)))
+}
+
+func (s *Service) FindRotator(w http.ResponseWriter, r *http.Request) {
+	var req FindRotatorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	rots, err := s.repoRots.FindRotator(r.Context(), rotatorsRepo.FilterRotator{
+		Name:       req.Name,
+		LocationID: req.LocationID,
+	})
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoRots.FindRotator: %w", err).Error()))
+		return
+	}
+
+	res := FindRotatorResponse{
+		Rotators: make(map[int]Rotator, len(rots)),
+	}
+
+	for _, rot := range rots {
+		res.Rotators[rot.ID] = rotatorFromRepo(rot)
+	}
+
+	render.Write(w, r, res)
+}
+
+func (s *Service) UpdateRotator(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRotatorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	req.Rotator.Profile = req.Rotator.Profile.WithDefaults()
+	if !req.Rotator.Profile.Valid() {
+		w.WriteHeader(400)
+		w.Write([]byte("некорректный профиль ротатора"))
+		return
+	}
+
+	err = s.repoRots.UpdateRotator(r.Context(), rotatorToRepo(req.RotatorID, req.Rotator))
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.repoRots.UpdateRotator: %w", err).Error()))
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// POST /tracking-table/
+// Таблица наведения ротатора на ближайшие пролеты спутника над локацией ротатора
+func (s *Service) TrackingTable(w http.ResponseWriter, r *http.Request) {
+	var req TrackingTableRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	rot, err := s.repoRots.GetRotator(r.Context(), int(req.RotatorID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repoRots.GetRotator: %w", err).Error()))
+		return
+	}
+
+	obsLoc, err := s.repoLocs.GetLocation(r.Context(), rot.LocationID)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation: %w", err).Error()))
+		return
+	}
+
+	var t time.Time
+
+	if req.Timestamp == nil {
+		t = time.Now().UTC()
+	} else {
+		t = time.Unix(*req.Timestamp, 0)
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, t)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	step := time.Second
+	if req.StepSeconds != nil && *req.StepSeconds > 0 {
+		step = time.Duration(*req.StepSeconds) * time.Second
+	}
+
+	countOfTimeRanges := 1
+	if req.CountOfTimeRanges != nil {
+		countOfTimeRanges = *req.CountOfTimeRanges
+	}
+
+	if countOfTimeRanges < 1 || countOfTimeRanges > maxTrackingTablePasses {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("количество пролетов должно быть от 1 до %d", maxTrackingTablePasses)))
+		return
+	}
+
+	coords := satellite.ObserverCoords{
+		Lon: obsLoc.Point.Lon,
+		Lat: obsLoc.Point.Lat,
+		Alt: obsLoc.Point.Alt,
+	}
+
+	profile := rotatorFromRepo(rot).Profile
+
+	sat = sat.WithRefraction(observerRefraction(obsLoc, nil))
+
+	timeRanges := sat.VisibleTimeRange(t, coords, countOfTimeRanges)
+
+	if passPoints(timeRanges, step) > maxPassPoints {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("слишком много точек (не более %d), увеличьте шаг или уменьшите количество пролетов", maxPassPoints)))
+		return
+	}
+
+	tables := make([]satellite.TrackingTable, 0, len(timeRanges))
+	for _, tr := range timeRanges {
+		tables = append(tables, sat.TrackingTable(tr, coords, step, profile))
+	}
+
+	render.Write(w, r, tables)
+}
+
+// passPoints - количество точек с шагом step во всех пролетах
+func passPoints(ranges []satellite.TimeRange, step time.Duration) int {
+	points := 0
+	for _, tr := range ranges {
+		points += int(tr.To.Sub(tr.From)/step) + 1
+	}
+
+	return points
+}
@@ -8,8 +8,14 @@ import (
 	"time"
 
 	"github.com/BabyLev/Umka-1/internal/clients/r4uab"
+	"github.com/BabyLev/Umka-1/internal/ephemeris"
+	"github.com/BabyLev/Umka-1/internal/render"
+	geofencesRepo "github.com/BabyLev/Umka-1/internal/repo/geofences"
 	locationsRepo "github.com/BabyLev/Umka-1/internal/repo/locations"
+	rotatorsRepo "github.com/BabyLev/Umka-1/internal/repo/rotators"
 	satellitesRepo "github.com/BabyLev/Umka-1/internal/repo/satellites"
+	tleHistoryRepo "github.com/BabyLev/Umka-1/internal/repo/tlehistory"
+	"github.com/BabyLev/Umka-1/internal/tracking"
 	"github.com/BabyLev/Umka-1/internal/types"
 	"github.com/BabyLev/Umka-1/satellite"
 	"github.com/go-chi/chi/v5"
@@ -18,14 +24,29 @@ import (
 type Service struct {
 	repoSats    *satellitesRepo.Repo
 	repoLocs    *locationsRepo.Repo
+	repoRots    *rotatorsRepo.Repo
+	repoTLEs    *tleHistoryRepo.Repo
+	repoFences  *geofencesRepo.Repo
 	r4uabClient *r4uab.Client
+	tracker     *tracking.Manager
+	ephemeris   *ephemeris.Cache
+	elementSets *elementSetCache
+	// профиль SGP4 по умолчанию, если в запросе не передан X-Propagation-Profile
+	profile satellite.Profile
 }
 
-func New(rClient *r4uab.Client, repoSats *satellitesRepo.Repo, repoLocs *locationsRepo.Repo) *Service {
+func New(rClient *r4uab.Client, repoSats *satellitesRepo.Repo, repoLocs *locationsRepo.Repo, repoRots *rotatorsRepo.Repo, repoTLEs *tleHistoryRepo.Repo, repoFences *geofencesRepo.Repo, tracker *tracking.Manager, ephemeris *ephemeris.Cache, profile satellite.Profile) *Service {
 	return &Service{
 		r4uabClient: rClient,
 		repoSats:    repoSats,
 		repoLocs:    repoLocs,
+		repoRots:    repoRots,
+		repoTLEs:    repoTLEs,
+		repoFences:  repoFences,
+		tracker:     tracker,
+		ephemeris:   ephemeris,
+		elementSets: newElementSetCache(),
+		profile:     profile,
 	}
 }
 
@@ -59,23 +80,15 @@ func (s *Service) Calculate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sat := satellite.New(satRepo.Line1, satRepo.Line2)
-
-	satCoords, err := sat.Calculate(t.UTC())
+	sv, errKm, err := s.satelliteState(w, r, satRepo, t.UTC(), req.Propagator)
 	if err != nil {
 		w.WriteHeader(400)
 		w.Write([]byte(fmt.Errorf("ошибка при подсчёте координат: %w", err).Error()))
 		return
 	}
 
-	res, err := json.Marshal(satCoords)
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling coords: %w", err).Error()))
-		return
-	}
-
-	w.Write(res)
+	setInterpolationError(w, errKm)
+	render.Write(w, r, sv.Coords())
 }
 
 // POST /look_angles
@@ -104,8 +117,6 @@ func (s *Service) LookAngles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sat := satellite.New(satRepo.Line1, satRepo.Line2)
-
 	var t time.Time
 
 	if req.Timestamp == nil {
@@ -121,22 +132,29 @@ func (s *Service) LookAngles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Refraction != nil && !req.Refraction.Valid() {
+		w.WriteHeader(400)
+		w.Write([]byte("некорректная модель рефракции"))
+		return
+	}
+
 	coords := satellite.ObserverCoords{
 		Lon: obsLoc.Point.Lon,
 		Lat: obsLoc.Point.Lat,
 		Alt: obsLoc.Point.Alt,
 	}
 
-	lookAngles := sat.LookAngles(t, coords)
-
-	res, err := json.Marshal(lookAngles)
+	sv, errKm, err := s.satelliteState(w, r, satRepo, t.UTC(), req.Propagator)
 	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling coords: %w", err).Error()))
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("ошибка при подсчёте координат: %w", err).Error()))
 		return
 	}
 
-	w.Write(res)
+	lookAngles := sv.LookAngles(coords, observerRefraction(obsLoc, req.Refraction))
+
+	setInterpolationError(w, errKm)
+	render.Write(w, r, lookAngles)
 }
 
 func (s *Service) VisibleTimeRange(w http.ResponseWriter, r *http.Request) {
@@ -155,8 +173,6 @@ func (s *Service) VisibleTimeRange(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sat := satellite.New(satRepo.Line1, satRepo.Line2)
-
 	var t time.Time
 
 	if req.Timestamp == nil {
@@ -165,25 +181,47 @@ func (s *Service) VisibleTimeRange(w http.ResponseWriter, r *http.Request) {
 		t = time.Unix(*req.Timestamp, 0)
 	}
 
+	sat, err := s.newSatellite(w, r, satRepo, t)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
 	countOfTimeRanges := 1
 	if req.CountOfTimeRanges != nil {
 		countOfTimeRanges = *req.CountOfTimeRanges
 	}
 
+	if req.Refraction != nil {
+		if !req.Refraction.Valid() {
+			w.WriteHeader(400)
+			w.Write([]byte("некорректная модель рефракции"))
+			return
+		}
+
+		sat = sat.WithRefraction(*req.Refraction)
+	}
+
+	sat, err = sat.WithPropagatorKind(req.Propagator)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.WithPropagatorKind: %w", err).Error()))
+		return
+	}
+
 	timeRanges := sat.VisibleTimeRange(t, satellite.ObserverCoords{
 		Lon: req.Lon,
 		Lat: req.Lat,
 		Alt: req.Alt,
 	}, countOfTimeRanges)
 
-	res, err := json.Marshal(timeRanges)
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling time ranges: %w", err).Error()))
-		return
+	// тип орбиты сообщается и тогда, когда пролетов нет
+	if regime, ok := sat.Regime(); ok {
+		w.Header().Set("X-Orbit-Regime", string(regime.Regime))
 	}
 
-	w.Write(res)
+	render.Write(w, r, timeRanges)
 }
 
 // HTTP Method: DELETE
@@ -200,6 +238,8 @@ func (s *Service) DeleteSatellite(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte(fmt.Errorf("s.Storage.DeleteSatellite: %w", err).Error()))
 			return
 		}
+
+		s.invalidateSatellite(i)
 	} else {
 		w.WriteHeader(500)
 		w.Write([]byte(fmt.Errorf("не удалось преобразовать ID к целому числу: %w", err).Error()))
@@ -246,19 +286,12 @@ func (s *Service) FindSatellite(w http.ResponseWriter, r *http.Request) {
 			Line2:   sat.Line2,
 			Name:    sat.SatName,
 			NoradID: sat.NoradID,
+			OMM:     sat.OMM,
 		}
 		res.Satellites[sat.ID] = satellite
 	}
 
-	resJSON, err := json.Marshal(res)
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling: %w", err).Error()))
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(resJSON)
+	render.Write(w, r, res)
 }
 
 func (s *Service) GetSatellite(w http.ResponseWriter, r *http.Request) {
@@ -283,15 +316,9 @@ func (s *Service) GetSatellite(w http.ResponseWriter, r *http.Request) {
 	res.Line2 = satRepo.Line2
 	res.Name = satRepo.SatName
 	res.NoradID = satRepo.NoradID
+	res.OMM = satRepo.OMM
 
-	resJSON, err := json.Marshal(res)
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling: %w", err).Error()))
-		return
-	}
-
-	w.Write(resJSON)
+	render.Write(w, r, res)
 }
 
 func (s *Service) UpdateSatellite(w http.ResponseWriter, r *http.Request) {
@@ -304,7 +331,14 @@ func (s *Service) UpdateSatellite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Satellite.NoradID != nil {
+	if len(req.Satellite.OMM) > 0 {
+		err = applyOMM(&req.Satellite)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("ошибка разбора OMM: %w", err).Error()))
+			return
+		}
+	} else if req.Satellite.NoradID != nil {
 		updatedSatInfo, err := s.r4uabClient.GetSatelliteInfo(r.Context(), *req.Satellite.NoradID)
 		if err != nil {
 			w.WriteHeader(500)
@@ -322,6 +356,7 @@ func (s *Service) UpdateSatellite(w http.ResponseWriter, r *http.Request) {
 		NoradID: req.Satellite.NoradID,
 		Line1:   req.Satellite.Line1,
 		Line2:   req.Satellite.Line2,
+		OMM:     req.Satellite.OMM,
 	}
 	err = s.repoSats.UpdateSatellite(r.Context(), satRepo)
 	if err != nil {
@@ -330,6 +365,16 @@ func (s *Service) UpdateSatellite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// кэши сбрасываются и после того, как TLE попадет в историю
+	defer s.invalidateSatellite(req.SatelliteID)
+
+	err = s.saveTLEHistory(r.Context(), req.SatelliteID, req.Satellite.Line1, req.Satellite.Line2)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("s.saveTLEHistory: %w", err).Error()))
+		return
+	}
+
 	w.WriteHeader(200)
 }
 
@@ -343,7 +388,14 @@ func (s *Service) AddSatellite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.NoradID != nil {
+	if len(req.OMM) > 0 {
+		err = applyOMM(&req.Satellite)
+		if err != nil {
+			w.WriteHeader(400)
+			w.Write([]byte(fmt.Errorf("ошибка разбора OMM: %w", err).Error()))
+			return
+		}
+	} else if req.NoradID != nil {
 		updatedSatInfo, err := s.r4uabClient.GetSatelliteInfo(r.Context(), *req.NoradID)
 		if err != nil {
 			w.WriteHeader(500)
@@ -360,6 +412,7 @@ func (s *Service) AddSatellite(w http.ResponseWriter, r *http.Request) {
 		NoradID: req.NoradID,
 		Line1:   req.Line1,
 		Line2:   req.Line2,
+		OMM:     req.OMM,
 	})
 	if err != nil {
 		w.WriteHeader(500)
@@ -367,18 +420,18 @@ func (s *Service) AddSatellite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := AddSatelliteResponse{
-		SatelliteID: int64(satID),
-	}
-
-	resJSON, err := json.Marshal(res)
+	err = s.saveTLEHistory(r.Context(), satID, req.Line1, req.Line2)
 	if err != nil {
 		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling: %w", err).Error()))
+		w.Write([]byte(fmt.Errorf("s.saveTLEHistory: %w", err).Error()))
 		return
 	}
 
-	w.Write(resJSON)
+	res := AddSatelliteResponse{
+		SatelliteID: int64(satID),
+	}
+
+	render.Write(w, r, res)
 }
 
 func (s *Service) AddLocation(w http.ResponseWriter, r *http.Request) {
@@ -391,6 +444,12 @@ func (s *Service) AddLocation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Refraction != nil && !req.Refraction.Valid() {
+		w.WriteHeader(400)
+		w.Write([]byte("некорректная модель рефракции"))
+		return
+	}
+
 	obs := locationsRepo.Location{
 		Name: req.ObserverLocation.Name,
 		Point: locationsRepo.Point{
@@ -398,6 +457,7 @@ func (s *Service) AddLocation(w http.ResponseWriter, r *http.Request) {
 			Lat: req.Location.Lat,
 			Alt: req.Location.Alt,
 		},
+		Refraction: refractionToRepo(req.Refraction),
 	}
 
 	locID, err := s.repoLocs.CreateLocation(r.Context(), obs)
@@ -411,14 +471,7 @@ func (s *Service) AddLocation(w http.ResponseWriter, r *http.Request) {
 		ID: locID,
 	}
 
-	resJSON, err := json.Marshal(res)
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling: %w", err).Error()))
-		return
-	}
-
-	w.Write(resJSON)
+	render.Write(w, r, res)
 }
 
 func (s *Service) GetLocation(w http.ResponseWriter, r *http.Request) {
@@ -444,16 +497,10 @@ func (s *Service) GetLocation(w http.ResponseWriter, r *http.Request) {
 			Lat: loc.Point.Lat,
 			Alt: loc.Point.Alt,
 		},
+		Refraction: refractionFromRepo(loc.Refraction),
 	}
 
-	resJSON, err := json.Marshal(res)
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling: %w", err).Error()))
-		return
-	}
-
-	w.Write(resJSON)
+	render.Write(w, r, res)
 }
 
 func (s *Service) DeleteLocation(w http.ResponseWriter, r *http.Request) {
@@ -507,6 +554,7 @@ func (s *Service) FindLocation(w http.ResponseWriter, r *http.Request) {
 				Lat: loc.Point.Lat,
 				Alt: loc.Point.Alt,
 			},
+			Refraction: refractionFromRepo(loc.Refraction),
 		}
 	}
 
@@ -514,14 +562,7 @@ func (s *Service) FindLocation(w http.ResponseWriter, r *http.Request) {
 		Locations: resLocations,
 	}
 
-	resJSON, err := json.Marshal(res)
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(fmt.Errorf("error marshalling: %w", err).Error()))
-		return
-	}
-
-	w.Write(resJSON)
+	render.Write(w, r, res)
 }
 
 func (s *Service) UpdateLocation(w http.ResponseWriter, r *http.Request) {
@@ -534,6 +575,12 @@ func (s *Service) UpdateLocation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Location.Refraction != nil && !req.Location.Refraction.Valid() {
+		w.WriteHeader(400)
+		w.Write([]byte("некорректная модель рефракции"))
+		return
+	}
+
 	resLocation := locationsRepo.Location{
 		ID:   req.LocationID,
 		Name: req.Location.Name,
@@ -542,6 +589,7 @@ func (s *Service) UpdateLocation(w http.ResponseWriter, r *http.Request) {
 			Lat: req.Location.Location.Lat,
 			Alt: req.Location.Location.Alt,
 		},
+		Refraction: refractionToRepo(req.Location.Refraction),
 	}
 
 	err = s.repoLocs.UpdateLocation(r.Context(), resLocation)
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	"github.com/BabyLev/Umka-1/internal/tracking"
+	"github.com/BabyLev/Umka-1/satellite"
+	"github.com/go-chi/chi/v5"
+)
+
+// PUT /tracking/
+// Запускает сопровождение ближайшего пролета спутника ротатором через rotctld
+func (s *Service) StartTracking(w http.ResponseWriter, r *http.Request) {
+	var req StartTrackingRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	rot, err := s.repoRots.GetRotator(r.Context(), int(req.RotatorID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repoRots.GetRotator: %w", err).Error()))
+		return
+	}
+
+	address := rot.Address
+	if req.Address != nil {
+		address = req.Address
+	}
+	if address == nil || *address == "" {
+		w.WriteHeader(400)
+		w.Write([]byte("не задан адрес rotctld ни в запросе, ни в профиле ротатора"))
+		return
+	}
+
+	obsLoc, err := s.repoLocs.GetLocation(r.Context(), rot.LocationID)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation: %w", err).Error()))
+		return
+	}
+
+	var t time.Time
+
+	if req.Timestamp == nil {
+		t = time.Now().UTC()
+	} else {
+		t = time.Unix(*req.Timestamp, 0)
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, t)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	coords := satellite.ObserverCoords{
+		Lon: obsLoc.Point.Lon,
+		Lat: obsLoc.Point.Lat,
+		Alt: obsLoc.Point.Alt,
+	}
+
+	sat = sat.WithRefraction(observerRefraction(obsLoc, nil))
+
+	timeRanges := sat.VisibleTimeRange(t, coords, 1)
+	if len(timeRanges) == 0 {
+		w.WriteHeader(400)
+		w.Write([]byte("не найдено ни одного пролета спутника над локацией ротатора"))
+		return
+	}
+
+	interval := time.Second
+	if req.IntervalMs != nil && *req.IntervalMs > 0 {
+		interval = time.Duration(*req.IntervalMs) * time.Millisecond
+	}
+
+	status := s.tracker.StartRotator(tracking.RotatorTask{
+		SatelliteID: satRepo.ID,
+		RotatorID:   rot.ID,
+		Address:     *address,
+		Satellite:   sat,
+		Observer:    coords,
+		Profile:     rotatorFromRepo(rot).Profile,
+		Pass:        timeRanges[0],
+		Interval:    interval,
+	})
+
+	render.Write(w, r, status)
+}
+
+// POST /tracking/
+// Список всех сессий сопровождения
+func (s *Service) ListTracking(w http.ResponseWriter, r *http.Request) {
+	render.Write(w, r, s.tracker.List())
+}
+
+// GET /tracking/{id}
+// Состояние сессии сопровождения и ошибка наведения
+func (s *Service) GetTracking(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("ID невозможно преобразовать в число: %w", err).Error()))
+		return
+	}
+
+	status, err := s.tracker.Status(idInt)
+	if err != nil {
+		w.WriteHeader(404)
+		w.Write([]byte(fmt.Errorf("s.tracker.Status: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, status)
+}
+
+// DELETE /tracking/{id}
+// Останавливает сессию сопровождения
+func (s *Service) StopTracking(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if i, err := strconv.Atoi(id); err == nil {
+		err := s.tracker.Stop(i)
+		if err != nil {
+			w.WriteHeader(404)
+			w.Write([]byte(fmt.Errorf("s.tracker.Stop: %w", err).Error()))
+			return
+		}
+	} else {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("не удалось преобразовать ID к целому числу: %w", err).Error()))
+		return
+	}
+
+	w.WriteHeader(200)
+	w.Write([]byte(fmt.Sprintf("сопровождение остановлено id = %s", id)))
+}
+
+// PUT /doppler/
+// Запускает коррекцию доплеровского сдвига частот приемника и передатчика через rigctld
+// на время ближайшего пролета. Состояние сессии доступно через /tracking/{id}.
+func (s *Service) StartDoppler(w http.ResponseWriter, r *http.Request) {
+	var req StartDopplerRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	task := tracking.DopplerTask{
+		LocationID: int(req.ObserverPositionID),
+		DownlinkHz: req.DownlinkHz,
+		UplinkHz:   req.UplinkHz,
+	}
+
+	if req.RxAddress != nil && req.DownlinkHz > 0 {
+		task.RxAddress = *req.RxAddress
+	}
+	if req.TxAddress != nil && req.UplinkHz > 0 {
+		task.TxAddress = *req.TxAddress
+	}
+	if task.RxAddress == "" && task.TxAddress == "" {
+		w.WriteHeader(400)
+		w.Write([]byte("нужно задать rxAddress и downlinkHz и/или txAddress и uplinkHz"))
+		return
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	obsLoc, err := s.repoLocs.GetLocation(r.Context(), int(req.ObserverPositionID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation: %w", err).Error()))
+		return
+	}
+
+	var t time.Time
+
+	if req.Timestamp == nil {
+		t = time.Now().UTC()
+	} else {
+		t = time.Unix(*req.Timestamp, 0)
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, t)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	coords := satellite.ObserverCoords{
+		Lon: obsLoc.Point.Lon,
+		Lat: obsLoc.Point.Lat,
+		Alt: obsLoc.Point.Alt,
+	}
+
+	sat = sat.WithRefraction(observerRefraction(obsLoc, nil))
+
+	timeRanges := sat.VisibleTimeRange(t, coords, 1)
+	if len(timeRanges) == 0 {
+		w.WriteHeader(400)
+		w.Write([]byte("не найдено ни одного пролета спутника над локацией"))
+		return
+	}
+
+	task.SatelliteID = satRepo.ID
+	task.Satellite = sat
+	task.Observer = coords
+	task.Pass = timeRanges[0]
+
+	if req.IntervalMs != nil && *req.IntervalMs > 0 {
+		task.Interval = time.Duration(*req.IntervalMs) * time.Millisecond
+	}
+
+	status := s.tracker.StartDoppler(task)
+
+	render.Write(w, r, status)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/render"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+const (
+	// максимальная длительность интервала поиска прохождений, сут
+	maxTransitDays = 30
+	// максимальный радиус поиска: дальше спутник может быть не виден из самой локации
+	maxTransitRadiusKm = 500.0
+)
+
+// POST /transits/
+// Прохождения спутника по диску Солнца или Луны рядом с локацией: центральные линии на земле,
+// длительность прохождения и угловой размер спутника
+func (s *Service) Transits(w http.ResponseWriter, r *http.Request) {
+	var req TransitsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Errorf("ошибка декодирования запроса: %w", err).Error()))
+		return
+	}
+
+	days := 7
+	if req.Days != nil {
+		days = *req.Days
+	}
+
+	if days < 1 || days > maxTransitDays {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("длительность интервала должна быть от 1 до %d суток", maxTransitDays)))
+		return
+	}
+
+	radius := 50.0
+	if req.RadiusKm != nil {
+		radius = *req.RadiusKm
+	}
+
+	if radius <= 0 || radius > maxTransitRadiusKm {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Sprintf("радиус поиска должен быть больше 0 и не больше %.0f км", maxTransitRadiusKm)))
+		return
+	}
+
+	size := 1.0
+	if req.SatelliteSizeM != nil {
+		size = *req.SatelliteSizeM
+	}
+
+	bodies := req.Bodies
+	if len(bodies) == 0 {
+		bodies = []satellite.TransitBody{satellite.TransitSun, satellite.TransitMoon}
+	}
+
+	satRepo, err := s.repoSats.GetSatellite(r.Context(), int(req.SatelliteID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repo.GetSatellite: %w", err).Error()))
+		return
+	}
+
+	obsLoc, err := s.repoLocs.GetLocation(r.Context(), int(req.ObserverPositionID))
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.repoLocs.GetLocation: %w", err).Error()))
+		return
+	}
+
+	var from time.Time
+
+	if req.Timestamp == nil {
+		from = time.Now().UTC()
+	} else {
+		from = time.Unix(*req.Timestamp, 0).UTC()
+	}
+
+	sat, err := s.newSatellite(w, r, satRepo, from)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("s.newSatellite: %w", err).Error()))
+		return
+	}
+
+	coords := satellite.ObserverCoords{
+		Lon: obsLoc.Point.Lon,
+		Lat: obsLoc.Point.Lat,
+		Alt: obsLoc.Point.Alt,
+	}
+
+	transits, err := sat.Transits(from, from.AddDate(0, 0, days), coords, radius, size, bodies)
+	if err != nil {
+		w.WriteHeader(400)
+		w.Write([]byte(fmt.Errorf("sat.Transits: %w", err).Error()))
+		return
+	}
+
+	render.Write(w, r, TransitsResponse{Transits: transits})
+}
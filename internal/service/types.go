@@ -1,10 +1,18 @@
 package service
 
-import "github.com/BabyLev/Umka-1/internal/types"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/types"
+	"github.com/BabyLev/Umka-1/satellite"
+)
 
 type CalculateRequest struct {
 	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
 	Timestamp   *int64 `json:"timestamp"`
+	// Пропагатор: sgp4 (по умолчанию) или numerical
+	Propagator satellite.PropagatorKind `json:"propagator"`
 }
 
 type LookAnglesRequest struct {
@@ -12,6 +20,10 @@ type LookAnglesRequest struct {
 	Timestamp   *int64 `json:"timestamp"`
 	// Координаты наблюдателя
 	ObserverPositionID int64 `json:"observerPositionId"`
+	// Модель рефракции, по умолчанию - из настроек локации
+	Refraction *satellite.Refraction `json:"refraction"`
+	// Пропагатор: sgp4 (по умолчанию) или numerical
+	Propagator satellite.PropagatorKind `json:"propagator"`
 }
 
 type VisibleTimeRangeRequest struct {
@@ -22,6 +34,10 @@ type VisibleTimeRangeRequest struct {
 	Lat               float64 `json:"lat"`
 	Alt               float64 `json:"alt"` // км
 	CountOfTimeRanges *int    `json:"countOfTimeRanges"`
+	// Модель рефракции, по умолчанию не учитывается
+	Refraction *satellite.Refraction `json:"refraction"`
+	// Пропагатор: sgp4 (по умолчанию) или numerical
+	Propagator satellite.PropagatorKind `json:"propagator"`
 }
 
 type AddSatelliteRequest struct {
@@ -52,6 +68,9 @@ type Satellite struct {
 	Line2   string `json:"line2"`
 	Name    string `json:"name"`
 	NoradID *int64 `json:"noradId"`
+	// Элементы в формате CCSDS OMM: JSON-объект или строка с OMM в формате KVN, XML или JSON.
+	// Если задан, используется вместо line1/line2
+	OMM json.RawMessage `json:"omm,omitempty"`
 }
 
 type AddLocationRequest struct {
@@ -74,3 +93,294 @@ type UpdateLocationRequest struct {
 	Location   types.ObserverLocation `json:"location"`
 	LocationID int                    `json:"locationId"`
 }
+
+type Rotator struct {
+	Name       string                   `json:"name"`
+	LocationID int                      `json:"locationId"` // id локации, на которой стоит ротатор
+	Address    *string                  `json:"address"`    // адрес Hamlib rotctld, host:port
+	Profile    satellite.RotatorProfile `json:"profile"`
+}
+
+type AddRotatorRequest struct {
+	Rotator
+}
+
+type AddRotatorResponse struct {
+	ID int `json:"rotatorId"`
+}
+
+type FindRotatorRequest struct {
+	Name       *string `json:"name"`
+	LocationID *int    `json:"locationId"`
+}
+
+type FindRotatorResponse struct {
+	Rotators map[int]Rotator `json:"rotators"` // int - id ротатора в хранилище
+}
+
+type UpdateRotatorRequest struct {
+	Rotator   Rotator `json:"rotator"`
+	RotatorID int     `json:"rotatorId"`
+}
+
+type TrackingTableRequest struct {
+	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
+	RotatorID   int64  `json:"rotatorId"`   // id ротатора, координаты берутся из его локации
+	Timestamp   *int64 `json:"timestamp"`
+	StepSeconds *int   `json:"stepSeconds"` // шаг таблицы, по умолчанию 1 секунда
+	// количество пролетов, по умолчанию 1
+	CountOfTimeRanges *int `json:"countOfTimeRanges"`
+}
+
+type StartTrackingRequest struct {
+	SatelliteID int64   `json:"satelliteId"` // id спутника из хранилища
+	RotatorID   int64   `json:"rotatorId"`   // id ротатора, координаты берутся из его локации
+	Address     *string `json:"address"`     // адрес rotctld, по умолчанию - из профиля ротатора
+	Timestamp   *int64  `json:"timestamp"`   // сопровождается первый пролет после этого момента
+	IntervalMs  *int    `json:"intervalMs"`  // период отправки команд, по умолчанию 1000 мс
+}
+
+type StartDopplerRequest struct {
+	SatelliteID        int64   `json:"satelliteId"`        // id спутника из хранилища
+	ObserverPositionID int64   `json:"observerPositionId"` // id локации станции
+	DownlinkHz         int64   `json:"downlinkHz"`         // частота передатчика спутника, Гц
+	UplinkHz           int64   `json:"uplinkHz"`           // частота приемника спутника, Гц
+	RxAddress          *string `json:"rxAddress"`          // адрес rigctld приемника
+	TxAddress          *string `json:"txAddress"`          // адрес rigctld передатчика
+	Timestamp          *int64  `json:"timestamp"`          // сопровождается первый пролет после этого момента
+	IntervalMs         *int    `json:"intervalMs"`         // период перестройки частот, по умолчанию 1000 мс
+}
+
+type OEMRequest struct {
+	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
+	Timestamp   *int64 `json:"timestamp"`   // начало интервала, по умолчанию текущее время
+	// длительность интервала, по умолчанию 1 сутки
+	DurationSeconds *int64 `json:"durationSeconds"`
+	StepSeconds     *int64 `json:"stepSeconds"` // шаг, по умолчанию 60 секунд
+	Format          string `json:"format"`      // kvn (по умолчанию) или xml
+}
+
+// запрос на выгрузку орбит в KML и CZML
+type ExportRequest struct {
+	SatelliteIDs []int  `json:"satelliteIds"` // id спутников из хранилища
+	LocationIDs  []int  `json:"locationIds"`  // id станций, опционально
+	Timestamp    *int64 `json:"timestamp"`    // начало интервала, по умолчанию текущее время
+	// длительность интервала, по умолчанию 3 часа
+	DurationSeconds *int64 `json:"durationSeconds"`
+	StepSeconds     *int64 `json:"stepSeconds"` // шаг, по умолчанию 60 секунд
+}
+
+// запрос на расчет угла бета и сезонов без тени
+type BetaAngleRequest struct {
+	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
+	Timestamp   *int64 `json:"timestamp"`   // начало интервала, по умолчанию текущее время
+	Months      *int   `json:"months"`      // длительность интервала в месяцах, по умолчанию 12
+}
+
+// запрос на расчет пересечений экватора и местного времени узлов
+type NodesRequest struct {
+	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
+	Timestamp   *int64 `json:"timestamp"`   // начало интервала, по умолчанию текущее время
+	// длительность интервала, по умолчанию сутки
+	DurationSeconds *int64 `json:"durationSeconds"`
+}
+
+type NodesResponse struct {
+	Crossings []satellite.NodeCrossing `json:"crossings"`
+	// среднее местное время восходящего узла за интервал, ч
+	LTAN       *float64 `json:"ltan"`
+	LTANString string   `json:"ltanString,omitempty"`
+	// местное время восходящего узла по сохраненным TLE
+	LTANHistory []LTANRecord `json:"ltanHistory"`
+}
+
+type LTANRecord struct {
+	Epoch      time.Time `json:"epoch"`
+	LTAN       float64   `json:"ltan"` // ч
+	LTANString string    `json:"ltanString"`
+	// изменение относительно предыдущего TLE, мин и мин/сут
+	DriftMinutes       *float64 `json:"driftMinutes"`
+	DriftMinutesPerDay *float64 `json:"driftMinutesPerDay"`
+}
+
+// запрос на поиск окон съемки цели
+type ImagingRequest struct {
+	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
+	Timestamp   *int64 `json:"timestamp"`   // начало интервала, по умолчанию текущее время
+	// длительность интервала, по умолчанию 7 суток
+	DurationSeconds *int64        `json:"durationSeconds"`
+	Target          ImagingTarget `json:"target"`
+	HalfAngle       float64       `json:"halfAngle"`   // половина угла поля зрения, град
+	MaxOffNadir     float64       `json:"maxOffNadir"` // максимальное отклонение от надира, град
+	// минимальная высота Солнца в точке цели, град, опционально
+	MinSunElevation *float64 `json:"minSunElevation"`
+}
+
+// цель съемки: точка или многоугольник
+type ImagingTarget struct {
+	Point   *satellite.GeoPoint  `json:"point"`
+	Polygon []satellite.GeoPoint `json:"polygon"`
+}
+
+type ImagingResponse struct {
+	Accesses []satellite.ImagingAccess `json:"accesses"`
+	Revisit  satellite.RevisitStats    `json:"revisit"`
+}
+
+// запрос на расчет энергетики радиолинии на пролетах
+type LinkBudgetRequest struct {
+	SatelliteID        int64  `json:"satelliteId"`        // id спутника из хранилища
+	ObserverPositionID int64  `json:"observerPositionId"` // id локации станции
+	Timestamp          *int64 `json:"timestamp"`          // считаются пролеты после этого момента
+	StepSeconds        *int   `json:"stepSeconds"`        // шаг, по умолчанию 10 секунд
+	// количество пролетов, по умолчанию 1
+	CountOfTimeRanges *int           `json:"countOfTimeRanges"`
+	Link              satellite.Link `json:"link"`
+	// Модель рефракции, по умолчанию - из настроек локации
+	Refraction *satellite.Refraction `json:"refraction"`
+}
+
+// запрос на расчет объема данных, принимаемых станциями
+type DataVolumeRequest struct {
+	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
+	LocationIDs []int  `json:"locationIds"` // id станций
+	Timestamp   *int64 `json:"timestamp"`   // начало интервала, по умолчанию текущее время
+	Days        *int   `json:"days"`        // длительность интервала в сутках, по умолчанию 1
+	// минимальный угол места для связи, град
+	MinElevation float64 `json:"minElevation"`
+	// постоянная скорость передачи, бит/с, или зависимость скорости от угла места
+	BitrateBps   *float64               `json:"bitrateBps"`
+	BitrateTable satellite.BitrateTable `json:"bitrateTable"`
+	StepSeconds  *int                   `json:"stepSeconds"` // шаг интегрирования, по умолчанию 10 секунд
+}
+
+// набор элементов для сравнения: строки TLE, набор из истории или текущий TLE спутника из хранилища
+type ElementSetSource struct {
+	Line1       string `json:"line1"`
+	Line2       string `json:"line2"`
+	HistoryID   *int   `json:"historyId"`   // id набора в истории TLE
+	SatelliteID *int   `json:"satelliteId"` // id спутника из хранилища
+}
+
+// запрос на сравнение двух наборов элементов одного объекта
+type CompareTLERequest struct {
+	Reference ElementSetSource `json:"reference"` // опорный набор
+	Other     ElementSetSource `json:"other"`     // сравниваемый набор
+	Timestamp *int64           `json:"timestamp"` // начало интервала, по умолчанию текущее время
+	// длительность интервала, по умолчанию 1 сутки
+	DurationSeconds *int64 `json:"durationSeconds"`
+	StepSeconds     *int64 `json:"stepSeconds"` // шаг, по умолчанию 60 секунд
+}
+
+// измерение направления на спутник со станции
+type AnglesObservation struct {
+	LocationID int       `json:"locationId"` // id локации станции
+	Time       time.Time `json:"time"`
+	Az         float64   `json:"az"` // град
+	El         float64   `json:"el"` // град, с рефракцией (модель - из настроек локации)
+}
+
+// запрос на определение орбиты по измерениям
+type OrbitFitRequest struct {
+	SatelliteID int64                        `json:"satelliteId"` // id спутника из хранилища
+	Angles      []AnglesObservation          `json:"angles"`
+	States      []satellite.StateObservation `json:"states"`
+	// начальное приближение - текущий TLE спутника, иначе - по измерениям
+	UseCurrentTLE bool `json:"useCurrentTle"`
+	FitBStar      bool `json:"fitBStar"`
+	// погрешности измерений, опционально
+	AnglesSigma   float64 `json:"anglesSigma"`   // град
+	PositionSigma float64 `json:"positionSigma"` // км
+	VelocitySigma float64 `json:"velocitySigma"` // км/с
+	// только рассчитать TLE, не сохраняя его спутнику
+	DryRun bool `json:"dryRun"`
+}
+
+type OrbitFitResponse struct {
+	satellite.OrbitFit
+	Saved bool `json:"saved"`
+}
+
+// запрос на поиск прохождений спутника по диску Солнца или Луны
+type TransitsRequest struct {
+	SatelliteID        int64  `json:"satelliteId"`        // id спутника из хранилища
+	ObserverPositionID int64  `json:"observerPositionId"` // id локации наблюдателя
+	Timestamp          *int64 `json:"timestamp"`          // начало интервала, по умолчанию текущее время
+	Days               *int   `json:"days"`               // длительность интервала в сутках, по умолчанию 7
+	// радиус поиска центральной линии вокруг локации, км, по умолчанию 50
+	RadiusKm *float64 `json:"radiusKm"`
+	// светила: "sun" и/или "moon", по умолчанию оба
+	Bodies []satellite.TransitBody `json:"bodies"`
+	// размер спутника, м, по умолчанию 1
+	SatelliteSizeM *float64 `json:"satelliteSizeM"`
+}
+
+type TransitsResponse struct {
+	Transits []satellite.Transit `json:"transits"`
+}
+
+// запрос на расчет повторяемости трассы
+type RepeatCycleRequest struct {
+	SatelliteID int64 `json:"satelliteId"` // id спутника из хранилища
+	// допуск повторения трассы по экватору, км, по умолчанию 5
+	ToleranceKm *float64 `json:"toleranceKm"`
+	// наибольший цикл, узловых суток, по умолчанию 60
+	MaxDays *int `json:"maxDays"`
+}
+
+// запрос на поиск пересечений трасс восходящих и нисходящих витков
+type CrossoversRequest struct {
+	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
+	Timestamp   *int64 `json:"timestamp"`   // начало интервала, по умолчанию текущее время
+	Days        *int   `json:"days"`        // длительность интервала в сутках, по умолчанию 3
+	// область - многоугольник из трех и более вершин
+	Region []satellite.GeoPoint `json:"region"`
+}
+
+type CrossoversResponse struct {
+	Crossovers []satellite.Crossover `json:"crossovers"`
+}
+
+// область, вход в которую и выход из которой отслеживаются по подспутниковой точке
+type Geofence struct {
+	Name string `json:"name"`
+	// многоугольники области: GeoJSON Polygon, MultiPolygon, Feature или FeatureCollection
+	GeoJSON json.RawMessage `json:"geojson"`
+	// спутник и адрес для уведомлений о входе и выходе, опционально
+	SatelliteID *int    `json:"satelliteId"`
+	WebhookURL  *string `json:"webhookUrl"`
+}
+
+type AddGeofenceRequest struct {
+	Geofence
+}
+
+type AddGeofenceResponse struct {
+	ID int `json:"geofenceId"`
+}
+
+type FindGeofenceRequest struct {
+	Name *string `json:"name"`
+}
+
+type FindGeofenceResponse struct {
+	Geofences map[int]Geofence `json:"geofences"` // int - id области в хранилище
+}
+
+type UpdateGeofenceRequest struct {
+	Geofence   Geofence `json:"geofence"`
+	GeofenceID int      `json:"geofenceId"`
+}
+
+// запрос на поиск входов подспутниковой точки в области и выходов из них
+type GeofenceEventsRequest struct {
+	SatelliteID int64  `json:"satelliteId"` // id спутника из хранилища
+	GeofenceIDs []int  `json:"geofenceIds"` // id областей, по умолчанию все
+	Timestamp   *int64 `json:"timestamp"`   // начало интервала, по умолчанию текущее время
+	// длительность интервала, по умолчанию сутки
+	DurationSeconds *int64 `json:"durationSeconds"`
+}
+
+type GeofenceEventsResponse struct {
+	Geofences []satellite.GeofenceReport `json:"geofences"`
+}
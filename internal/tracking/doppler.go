@@ -0,0 +1,173 @@
+package tracking
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/clients/rigctld"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+type DopplerTask struct {
+	SatelliteID int
+	LocationID  int
+	Satellite   satellite.Satellite
+	Observer    satellite.ObserverCoords
+	DownlinkHz  int64  // частота передатчика спутника, 0 - приемник не перестраивается
+	UplinkHz    int64  // частота приемника спутника, 0 - передатчик не перестраивается
+	RxAddress   string // адрес rigctld приемника, "host:port"
+	TxAddress   string // адрес rigctld передатчика, "host:port"
+	Pass        satellite.TimeRange
+	Interval    time.Duration
+}
+
+// DopplerSample - одна перестройка частот
+type DopplerSample struct {
+	Time      time.Time `json:"time"`
+	RangeRate float64   `json:"rangeRate"` // км/с
+	RxHz      int64     `json:"rxHz,omitempty"`
+	TxHz      int64     `json:"txHz,omitempty"`
+	ShiftHz   float64   `json:"shiftHz"` // доплеровский сдвиг на частоте приема (или передачи, если приема нет)
+}
+
+// StartDoppler запускает коррекцию доплеровского сдвига на время пролета и возвращает статус новой сессии
+func (m *Manager) StartDoppler(task DopplerTask) Status {
+	if task.Interval <= 0 {
+		task.Interval = defaultInterval
+	}
+
+	sess := m.add(Status{
+		Kind:        KindDoppler,
+		SatelliteID: task.SatelliteID,
+		LocationID:  task.LocationID,
+		Address:     task.RxAddress,
+		TxAddress:   task.TxAddress,
+		Pass:        task.Pass,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sess.cancel = cancel
+
+	go sess.runDoppler(ctx, task)
+
+	return sess.snapshot()
+}
+
+func (sess *session) runDoppler(ctx context.Context, task DopplerTask) {
+	if !sleepUntil(ctx, task.Pass.From.Add(-prepositionLead)) {
+		return
+	}
+
+	var rx, tx *rigctld.Client
+
+	if task.RxAddress != "" && task.DownlinkHz > 0 {
+		rx = rigctld.New(task.RxAddress)
+
+		err := rx.Connect(ctx)
+		if err != nil {
+			sess.fail(err)
+			return
+		}
+		defer rx.Close()
+	}
+
+	if task.TxAddress != "" && task.UplinkHz > 0 {
+		tx = rigctld.New(task.TxAddress)
+
+		err := tx.Connect(ctx)
+		if err != nil {
+			sess.fail(err)
+			return
+		}
+		defer tx.Close()
+	}
+
+	// заранее настраиваем частоты на момент восхода
+	_, err := sess.tune(ctx, rx, tx, task, task.Pass.From)
+	if err != nil {
+		sess.fail(fmt.Errorf("ошибка начальной настройки частот: %w", err))
+		return
+	}
+
+	if !sleepUntil(ctx, task.Pass.From) {
+		return
+	}
+
+	sess.setState(StateTracking)
+
+	ticker := time.NewTicker(task.Interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		if now.After(task.Pass.To) {
+			break
+		}
+
+		sample, err := sess.tune(ctx, rx, tx, task, now)
+		if err != nil {
+			sess.fail(err)
+			return
+		}
+
+		sess.recordDoppler(sample)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	sess.setState(StateFinished)
+}
+
+// tune рассчитывает частоты с учетом эффекта Доплера на момент t и отправляет их радиостанциям
+func (sess *session) tune(ctx context.Context, rx, tx *rigctld.Client, task DopplerTask, t time.Time) (DopplerSample, error) {
+	la := task.Satellite.LookAngles(t, task.Observer)
+
+	sample := DopplerSample{
+		Time:      t,
+		RangeRate: la.RangeRate,
+	}
+
+	if rx != nil {
+		freq := satellite.DownlinkFrequency(float64(task.DownlinkHz), la.RangeRate)
+		sample.RxHz = int64(math.Round(freq))
+		sample.ShiftHz = freq - float64(task.DownlinkHz)
+
+		cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+		err := rx.SetFrequency(cmdCtx, sample.RxHz)
+		cancel()
+		if err != nil {
+			return DopplerSample{}, err
+		}
+	}
+
+	if tx != nil {
+		freq := satellite.UplinkFrequency(float64(task.UplinkHz), la.RangeRate)
+		sample.TxHz = int64(math.Round(freq))
+		if rx == nil {
+			sample.ShiftHz = freq - float64(task.UplinkHz)
+		}
+
+		cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+		err := tx.SetFrequency(cmdCtx, sample.TxHz)
+		cancel()
+		if err != nil {
+			return DopplerSample{}, err
+		}
+	}
+
+	return sample, nil
+}
+
+func (sess *session) recordDoppler(sample DopplerSample) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.status.LastDoppler = &sample
+	sess.status.Samples++
+}
@@ -0,0 +1,127 @@
+package tracking
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/clients/rigctld"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// frequencies возвращает частоты из команд F, полученных сервером
+func frequencies(t *testing.T, fake *rigctld.FakeServer) []int64 {
+	t.Helper()
+
+	var res []int64
+	for _, cmd := range fake.Commands() {
+		if !strings.HasPrefix(cmd, "F ") {
+			continue
+		}
+
+		hz, err := strconv.ParseInt(strings.TrimPrefix(cmd, "F "), 10, 64)
+		if err != nil {
+			t.Fatalf("команда %q: %v", cmd, err)
+		}
+		res = append(res, hz)
+	}
+
+	return res
+}
+
+func TestStartDoppler(t *testing.T) {
+	rx, err := rigctld.NewFakeServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rx.Close()
+
+	tx, err := rigctld.NewFakeServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	const (
+		downlinkHz = 437_500_000
+		uplinkHz   = 145_900_000
+	)
+
+	// станция под спутником в середине пролета: первую половину он приближается, вторую - удаляется
+	sat := testSatellite(t, 15.5)
+	from := time.Now().UTC().Add(500 * time.Millisecond)
+	closest := from.Add(time.Second)
+
+	sub, err := sat.Calculate(closest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := New()
+	status := m.StartDoppler(DopplerTask{
+		SatelliteID: 1,
+		LocationID:  1,
+		Satellite:   sat,
+		Observer:    satellite.ObserverCoords{Lat: sub.Lat, Lon: sub.Lon},
+		DownlinkHz:  downlinkHz,
+		UplinkHz:    uplinkHz,
+		RxAddress:   rx.Addr(),
+		TxAddress:   tx.Addr(),
+		Pass:        satellite.TimeRange{From: from, To: closest.Add(time.Second)},
+		Interval:    100 * time.Millisecond,
+	})
+
+	status = wait(t, m, status.ID)
+	if status.State != StateFinished {
+		t.Fatalf("состояние %s (%s), ожидалось %s", status.State, status.Error, StateFinished)
+	}
+
+	rxHz, txHz := frequencies(t, rx), frequencies(t, tx)
+	if len(rxHz) < 3 || len(rxHz) != len(txHz) || len(rxHz) != status.Samples+1 {
+		t.Fatalf("перестроек приемника %d, передатчика %d, отсчетов %d", len(rxHz), len(txHz), status.Samples)
+	}
+
+	// при сближении принимаемая частота выше номинала, а передавать нужно ниже; при удалении - наоборот
+	if first, last := rxHz[0], rxHz[len(rxHz)-1]; first <= downlinkHz || last >= downlinkHz {
+		t.Fatalf("приемник: %d в начале и %d в конце, номинал %d", first, last, downlinkHz)
+	}
+	if first, last := txHz[0], txHz[len(txHz)-1]; first >= uplinkHz || last <= uplinkHz {
+		t.Fatalf("передатчик: %d в начале и %d в конце, номинал %d", first, last, uplinkHz)
+	}
+
+	for i := 1; i < len(rxHz); i++ {
+		if rxHz[i] > rxHz[i-1] || txHz[i] < txHz[i-1] {
+			t.Fatalf("шаг %d: приемник %d -> %d, передатчик %d -> %d", i, rxHz[i-1], rxHz[i], txHz[i-1], txHz[i])
+		}
+	}
+
+	if status.LastDoppler == nil || status.LastDoppler.RangeRate <= 0 || status.LastDoppler.ShiftHz >= 0 {
+		t.Fatalf("последняя перестройка %+v: спутник должен удаляться", status.LastDoppler)
+	}
+}
+
+func TestStartDopplerReportError(t *testing.T) {
+	rx, err := rigctld.NewFakeServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rx.Close()
+
+	rx.SetError(-5)
+
+	from := time.Now().UTC().Add(300 * time.Millisecond)
+
+	m := New()
+	status := m.StartDoppler(DopplerTask{
+		Satellite:  testSatellite(t, 15.5),
+		DownlinkHz: 437_500_000,
+		RxAddress:  rx.Addr(),
+		Pass:       satellite.TimeRange{From: from, To: from.Add(time.Second)},
+	})
+
+	status = wait(t, m, status.ID)
+	if status.State != StateFailed || !strings.Contains(status.Error, "RPRT -5") {
+		t.Fatalf("состояние %s (%s), ожидалась ошибка RPRT -5", status.State, status.Error)
+	}
+}
@@ -0,0 +1,341 @@
+package tracking
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/clients/rotctld"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// пакет сопровождает спутник во время пролета: управляет ротатором и перестраивает
+// частоты радиостанций с учетом эффекта Доплера в реальном времени
+// сессии хранятся в памяти (то есть, до окончания работы программы)
+
+// Состояния сессии сопровождения
+const (
+	StateScheduled = "scheduled" // ждем начала пролета
+	StateTracking  = "tracking"  // идет сопровождение
+	StateFinished  = "finished"  // пролет закончился
+	StateStopped   = "stopped"   // остановлено пользователем
+	StateFailed    = "failed"    // ошибка связи с устройством
+)
+
+// Виды сессий сопровождения
+const (
+	KindRotator = "rotator" // наведение ротатора через rotctld
+	KindDoppler = "doppler" // коррекция частот через rigctld
+)
+
+const (
+	// интервал отправки команд по умолчанию
+	defaultInterval = time.Second
+	// за сколько до восхода подключаемся к ротатору и выводим антенну в точку восхода
+	prepositionLead = time.Minute
+	// таймаут одной команды устройству
+	commandTimeout = 5 * time.Second
+)
+
+type RotatorTask struct {
+	SatelliteID int
+	RotatorID   int
+	Address     string // адрес rotctld, "host:port"
+	Satellite   satellite.Satellite
+	Observer    satellite.ObserverCoords
+	Profile     satellite.RotatorProfile
+	Pass        satellite.TimeRange
+	Interval    time.Duration
+}
+
+// Sample - одна отправленная команда и прочитанное после нее положение ротатора
+type Sample struct {
+	Time     time.Time `json:"time"`
+	SatAz    float64   `json:"satAz"`
+	SatEl    float64   `json:"satEl"`
+	TargetAz float64   `json:"targetAz"` // отправленная команда
+	TargetEl float64   `json:"targetEl"`
+	ActualAz float64   `json:"actualAz"` // положение, прочитанное с ротатора
+	ActualEl float64   `json:"actualEl"`
+	Error    float64   `json:"error"` // угол между антенной и спутником, град
+}
+
+type Status struct {
+	ID          int                 `json:"id"`
+	Kind        string              `json:"kind"`
+	SatelliteID int                 `json:"satelliteId"`
+	RotatorID   int                 `json:"rotatorId,omitempty"`
+	LocationID  int                 `json:"locationId,omitempty"`
+	Address     string              `json:"address"`             // rotctld или rigctld приемника
+	TxAddress   string              `json:"txAddress,omitempty"` // rigctld передатчика
+	State       string              `json:"state"`
+	Pass        satellite.TimeRange `json:"pass"`
+	Last        *Sample             `json:"last,omitempty"`
+	LastDoppler *DopplerSample      `json:"lastDoppler,omitempty"`
+	Samples     int                 `json:"samples"`
+	MaxError    float64             `json:"maxError"` // град
+	RMSError    float64             `json:"rmsError"` // град
+	Error       string              `json:"error,omitempty"`
+}
+
+type Manager struct {
+	mu       sync.Mutex
+	lastID   int
+	sessions map[int]*session
+}
+
+type session struct {
+	mu       sync.Mutex
+	status   Status
+	errSqSum float64
+	cancel   context.CancelFunc
+}
+
+func New() *Manager {
+	return &Manager{
+		sessions: make(map[int]*session),
+	}
+}
+
+// StartRotator запускает сопровождение пролета ротатором в фоне и возвращает статус новой сессии
+func (m *Manager) StartRotator(task RotatorTask) Status {
+	if task.Interval <= 0 {
+		task.Interval = defaultInterval
+	}
+
+	sess := m.add(Status{
+		Kind:        KindRotator,
+		SatelliteID: task.SatelliteID,
+		RotatorID:   task.RotatorID,
+		Address:     task.Address,
+		Pass:        task.Pass,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sess.cancel = cancel
+
+	go sess.runRotator(ctx, task)
+
+	return sess.snapshot()
+}
+
+func (m *Manager) Status(id int) (Status, error) {
+	m.mu.Lock()
+	sess, ok := m.sessions[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return Status{}, fmt.Errorf("нет сессии сопровождения под таким ID")
+	}
+
+	return sess.snapshot(), nil
+}
+
+func (m *Manager) List() []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]Status, 0, len(m.sessions))
+	for _, sess := range m.sessions {
+		res = append(res, sess.snapshot())
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+
+	return res
+}
+
+// Stop останавливает сессию. Завершенные сессии остаются в списке со своим итоговым состоянием.
+func (m *Manager) Stop(id int) error {
+	m.mu.Lock()
+	sess, ok := m.sessions[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("нет сессии сопровождения под таким ID")
+	}
+
+	sess.mu.Lock()
+	if sess.status.State == StateScheduled || sess.status.State == StateTracking {
+		sess.status.State = StateStopped
+	}
+	sess.mu.Unlock()
+
+	sess.cancel()
+
+	return nil
+}
+
+func (m *Manager) add(status Status) *session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+	status.ID = m.lastID
+	status.State = StateScheduled
+
+	sess := &session{status: status}
+	m.sessions[m.lastID] = sess
+
+	return sess
+}
+
+func (sess *session) runRotator(ctx context.Context, task RotatorTask) {
+	// план пролета строится без ограничений скорости: ротатор сам отрабатывает команды,
+	// а таблица нужна только чтобы выбрать положение антенны (сдвиг азимута, переворот)
+	plan := task.Profile
+	plan.AzSlewRate, plan.ElSlewRate = 0, 0
+	table := task.Satellite.TrackingTable(task.Pass, task.Observer, task.Interval, plan)
+
+	if !sleepUntil(ctx, task.Pass.From.Add(-prepositionLead)) {
+		return
+	}
+
+	client := rotctld.New(task.Address)
+
+	err := client.Connect(ctx)
+	if err != nil {
+		sess.fail(err)
+		return
+	}
+	defer client.Close()
+
+	first := table.Points[0]
+	err = sess.sendPosition(ctx, client, first.Az, first.El)
+	if err != nil {
+		sess.fail(fmt.Errorf("ошибка вывода ротатора в точку восхода: %w", err))
+		return
+	}
+
+	if !sleepUntil(ctx, task.Pass.From) {
+		return
+	}
+
+	sess.setState(StateTracking)
+
+	ticker := time.NewTicker(task.Interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		if now.After(task.Pass.To) {
+			break
+		}
+
+		la := task.Satellite.LookAngles(now, task.Observer)
+		az, el := table.RotatorPosition(now, la, task.Profile)
+
+		err := sess.sendPosition(ctx, client, az, el)
+		if err != nil {
+			sess.fail(err)
+			return
+		}
+
+		cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+		actualAz, actualEl, err := client.GetPosition(cmdCtx)
+		cancel()
+		if err != nil {
+			sess.fail(err)
+			return
+		}
+
+		sess.record(Sample{
+			Time:     now,
+			SatAz:    la.Az,
+			SatEl:    la.El,
+			TargetAz: az,
+			TargetEl: el,
+			ActualAz: actualAz,
+			ActualEl: actualEl,
+			Error:    satellite.PointingError(actualAz, actualEl, la.Az, la.El),
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	sess.setState(StateFinished)
+}
+
+func (sess *session) sendPosition(ctx context.Context, client *rotctld.Client, az, el float64) error {
+	cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	return client.SetPosition(cmdCtx, az, el)
+}
+
+func (sess *session) record(sample Sample) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.status.Last = &sample
+	sess.status.Samples++
+	sess.errSqSum += sample.Error * sample.Error
+	sess.status.MaxError = math.Max(sess.status.MaxError, sample.Error)
+	sess.status.RMSError = math.Sqrt(sess.errSqSum / float64(sess.status.Samples))
+}
+
+func (sess *session) setState(state string) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	// остановленная пользователем сессия не меняет состояние
+	if sess.status.State != StateStopped {
+		sess.status.State = state
+	}
+}
+
+func (sess *session) fail(err error) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.status.State == StateStopped {
+		return
+	}
+
+	sess.status.State = StateFailed
+	sess.status.Error = err.Error()
+}
+
+func (sess *session) snapshot() Status {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	status := sess.status
+	if status.Last != nil {
+		last := *status.Last
+		status.Last = &last
+	}
+	if status.LastDoppler != nil {
+		last := *status.LastDoppler
+		status.LastDoppler = &last
+	}
+
+	return status
+}
+
+// sleepUntil ждет наступления момента t. Возвращает false, если контекст отменен раньше.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
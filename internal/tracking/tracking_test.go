@@ -0,0 +1,178 @@
+package tracking
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BabyLev/Umka-1/internal/clients/rotctld"
+	"github.com/BabyLev/Umka-1/satellite"
+)
+
+// testSatellite создает спутник по элементам с эпохой "сейчас": сессии сопровождения идут
+// по настоящим часам
+func testSatellite(t *testing.T, meanMotion float64) satellite.Satellite {
+	t.Helper()
+
+	line1, line2, err := satellite.Elements{
+		CatalogNumber:   99999,
+		Classification:  "U",
+		Epoch:           time.Now().UTC(),
+		MeanMotion:      meanMotion,
+		Eccentricity:    0.0001,
+		Inclination:     51.6,
+		ArgOfPericenter: 90,
+	}.TLE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return satellite.New(line1, line2)
+}
+
+// geoTask - сессия ротатора для геостационарного спутника: он виден все время, а направление
+// на него почти не меняется за время теста
+func geoTask(t *testing.T, addr string) RotatorTask {
+	t.Helper()
+
+	sat := testSatellite(t, 1.00273791)
+
+	sub, err := sat.Calculate(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now().UTC().Add(300 * time.Millisecond)
+
+	return RotatorTask{
+		SatelliteID: 1,
+		RotatorID:   1,
+		Address:     addr,
+		Satellite:   sat,
+		Observer:    satellite.ObserverCoords{Lat: sub.Lat + 30, Lon: sub.Lon},
+		Pass:        satellite.TimeRange{From: from, To: from.Add(time.Second)},
+		Interval:    100 * time.Millisecond,
+	}
+}
+
+// wait ждет окончания сессии
+func wait(t *testing.T, m *Manager, id int) Status {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := m.Status(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status.State != StateScheduled && status.State != StateTracking {
+			return status
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatal("сессия не закончилась за 5 с")
+
+	return Status{}
+}
+
+func TestStartRotator(t *testing.T) {
+	fake, err := rotctld.NewFakeServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+
+	m := New()
+	task := geoTask(t, fake.Addr())
+
+	status := wait(t, m, m.StartRotator(task).ID)
+	if status.State != StateFinished {
+		t.Fatalf("состояние %s (%s), ожидалось %s", status.State, status.Error, StateFinished)
+	}
+	if status.Samples == 0 || status.Last == nil {
+		t.Fatal("нет ни одного отсчета")
+	}
+
+	la := task.Satellite.LookAngles(time.Now(), task.Observer)
+
+	// команда вывода в точку восхода, затем на каждом шаге P и чтение положения p, в конце - q
+	commands := fake.Commands()
+	if len(commands) != 2*status.Samples+2 || commands[len(commands)-1] != "q" {
+		t.Fatalf("команды %q при %d отсчетах", commands, status.Samples)
+	}
+	commands = commands[:len(commands)-1]
+
+	for i, cmd := range commands {
+		if i > 0 && i%2 == 0 {
+			if cmd != "p" {
+				t.Fatalf("команда %d: %q, ожидалась p", i, cmd)
+			}
+			continue
+		}
+
+		var az, el float64
+		if _, err := fmt.Sscanf(cmd, "P %f %f", &az, &el); err != nil {
+			t.Fatalf("команда %d: %q: %v", i, cmd, err)
+		}
+
+		if satellite.PointingError(az, el, la.Az, la.El) > 0.05 {
+			t.Fatalf("команда %d: %q, спутник на %.2f/%.2f", i, cmd, la.Az, la.El)
+		}
+	}
+
+	// ротатор без ограничения скорости сразу в положении команды: ошибка - только округление команды
+	if status.MaxError > 0.02 {
+		t.Fatalf("ошибка наведения %.3f°, ожидалось не больше 0.02°", status.MaxError)
+	}
+}
+
+func TestStartRotatorPointingError(t *testing.T) {
+	fake, err := rotctld.NewFakeServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+
+	// медленный ротатор из положения 0/0 не успевает навестись за время пролета
+	fake.SlewRate = 5
+
+	m := New()
+	task := geoTask(t, fake.Addr())
+
+	status := wait(t, m, m.StartRotator(task).ID)
+	if status.State != StateFinished {
+		t.Fatalf("состояние %s (%s), ожидалось %s", status.State, status.Error, StateFinished)
+	}
+
+	last := status.Last
+	want := satellite.PointingError(last.ActualAz, last.ActualEl, last.SatAz, last.SatEl)
+	if math.Abs(last.Error-want) > 1e-9 {
+		t.Fatalf("ошибка наведения %.3f°, по прочитанному положению %.3f°", last.Error, want)
+	}
+
+	if last.Error < 10 || status.MaxError < last.Error || status.RMSError <= 0 {
+		t.Fatalf("ошибка %.1f°, максимальная %.1f°, СКО %.1f°", last.Error, status.MaxError, status.RMSError)
+	}
+}
+
+func TestStartRotatorReportError(t *testing.T) {
+	fake, err := rotctld.NewFakeServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+
+	fake.SetError(-5)
+
+	m := New()
+
+	status := wait(t, m, m.StartRotator(geoTask(t, fake.Addr())).ID)
+	if status.State != StateFailed || !strings.Contains(status.Error, "RPRT -5") {
+		t.Fatalf("состояние %s (%s), ожидалась ошибка RPRT -5", status.State, status.Error)
+	}
+}
@@ -11,8 +11,9 @@ type Satellite struct {
 
 // координаты наблюдателя
 type ObserverLocation struct {
-	Name     string   `json:"name"`
-	Location Location `json:"location"`
+	Name       string                `json:"name"`
+	Location   Location              `json:"location"`
+	Refraction *satellite.Refraction `json:"refraction,omitempty"` // модель рефракции по умолчанию для локации
 }
 
 type Location struct {
@@ -0,0 +1,173 @@
+package satellite
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	// точность границ сезонов без тени
+	eclipseSeasonPrecision = time.Minute
+)
+
+// BetaAngleDay - угол бета и тень на одном витке в начале суток
+type BetaAngleDay struct {
+	Date            time.Time `json:"date"`
+	Beta            float64   `json:"beta"`            // угол между плоскостью орбиты и направлением на Солнце, град
+	EclipseMinutes  float64   `json:"eclipseMinutes"`  // длительность тени на витке, мин
+	EclipseFraction float64   `json:"eclipseFraction"` // доля витка в тени
+}
+
+// EclipseSeason - интервал, в котором спутник не заходит в тень Земли
+type EclipseSeason struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	Days float64   `json:"days"`
+}
+
+// BetaAngleReport - изменение угла бета и теней за интервал
+type BetaAngleReport struct {
+	PeriodMinutes float64 `json:"periodMinutes"`
+	Altitude      float64 `json:"altitude"`     // средняя высота орбиты, км
+	CriticalBeta  float64 `json:"criticalBeta"` // при |beta| больше этого угла тени нет, град
+	RAANRate      float64 `json:"raanRate"`     // дрейф восходящего узла, град/сут
+
+	Days               []BetaAngleDay  `json:"days"`
+	EclipseFreeSeasons []EclipseSeason `json:"eclipseFreeSeasons"`
+}
+
+// BetaAngle возвращает угол бета на момент t, град.
+// Плоскость орбиты берется из TLE с вековым дрейфом узла из-за J2.
+func (e Elements) BetaAngle(t time.Time) float64 {
+	inc := e.Inclination * math.Pi / 180
+	raan := e.RAANAt(t) * math.Pi / 180
+
+	// нормаль к плоскости орбиты
+	hx := math.Sin(inc) * math.Sin(raan)
+	hy := -math.Sin(inc) * math.Cos(raan)
+	hz := math.Cos(inc)
+
+	sx, sy, sz := SunPosition(t)
+	r := math.Sqrt(sx*sx + sy*sy + sz*sz)
+
+	return math.Asin((hx*sx+hy*sy+hz*sz)/r) * 180 / math.Pi
+}
+
+// CriticalBetaAngle возвращает угол бета, начиная с которого виток полностью освещен, град
+func (e Elements) CriticalBetaAngle() float64 {
+	return math.Asin(equatorialRadiusKm/e.SemiMajorAxis()) * 180 / math.Pi
+}
+
+// EclipseFraction возвращает долю витка в тени Земли при угле бета beta (град).
+// Орбита считается круговой, тень - цилиндрической.
+func (e Elements) EclipseFraction(beta float64) float64 {
+	a := e.SemiMajorAxis()
+	h := a - equatorialRadiusKm
+
+	if math.Abs(beta) >= e.CriticalBetaAngle() {
+		return 0
+	}
+
+	cos := math.Sqrt(h*h+2*equatorialRadiusKm*h) / (a * math.Cos(beta*math.Pi/180))
+	if cos >= 1 {
+		return 0
+	}
+
+	return math.Acos(cos) / math.Pi
+}
+
+// BetaAngleReport рассчитывает угол бета и тень на витке в начале каждых суток от from до to,
+// а также сезоны без тени
+func (s Satellite) BetaAngleReport(from, to time.Time) (BetaAngleReport, error) {
+	if to.Before(from) {
+		return BetaAngleReport{}, errors.New("конец интервала раньше начала")
+	}
+
+	e, err := ParseTLE(s.line1, s.line2)
+	if err != nil {
+		return BetaAngleReport{}, err
+	}
+
+	if e.MeanMotion <= 0 {
+		return BetaAngleReport{}, errors.New("некорректное среднее движение в TLE")
+	}
+
+	period := e.Period()
+	critical := e.CriticalBetaAngle()
+
+	res := BetaAngleReport{
+		PeriodMinutes:      period.Minutes(),
+		Altitude:           e.SemiMajorAxis() - equatorialRadiusKm,
+		CriticalBeta:       critical,
+		RAANRate:           e.RAANRate(),
+		EclipseFreeSeasons: []EclipseSeason{},
+	}
+
+	for t := from; !t.After(to); t = t.Add(24 * time.Hour) {
+		beta := e.BetaAngle(t)
+		fraction := e.EclipseFraction(beta)
+
+		res.Days = append(res.Days, BetaAngleDay{
+			Date:            t,
+			Beta:            beta,
+			EclipseMinutes:  fraction * period.Minutes(),
+			EclipseFraction: fraction,
+		})
+	}
+
+	// сезоны без тени: |beta| >= critical, границы уточняются бисекцией между сутками
+	sunlit := func(t time.Time) bool {
+		return math.Abs(e.BetaAngle(t)) >= critical
+	}
+
+	var (
+		start  time.Time
+		inside bool
+	)
+
+	for i, day := range res.Days {
+		lit := day.EclipseFraction == 0
+
+		switch {
+		case lit && !inside:
+			start = day.Date
+			if i > 0 {
+				start = bisectCondition(res.Days[i-1].Date, day.Date, sunlit)
+			}
+			inside = true
+		case !lit && inside:
+			end := bisectCondition(res.Days[i-1].Date, day.Date, func(t time.Time) bool { return !sunlit(t) })
+			res.EclipseFreeSeasons = append(res.EclipseFreeSeasons, newEclipseSeason(start, end))
+			inside = false
+		}
+	}
+
+	if inside {
+		res.EclipseFreeSeasons = append(res.EclipseFreeSeasons, newEclipseSeason(start, to))
+	}
+
+	return res, nil
+}
+
+func newEclipseSeason(from, to time.Time) EclipseSeason {
+	return EclipseSeason{
+		From: from,
+		To:   to,
+		Days: to.Sub(from).Hours() / 24,
+	}
+}
+
+// bisectCondition находит момент на (lo, hi], когда cond становится истинным (cond(lo) ложно, cond(hi) истинно)
+func bisectCondition(lo, hi time.Time, cond func(time.Time) bool) time.Time {
+	for hi.Sub(lo) > eclipseSeasonPrecision {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if cond(mid) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return hi.Round(time.Second)
+}
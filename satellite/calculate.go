@@ -1,9 +1,9 @@
 package satellite
 
 import (
-	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/joshuaferrara/go-satellite"
@@ -19,31 +19,50 @@ const (
 	defaultMaxSearchDuration = 7 * 24 * time.Hour // Search up to 7 days ahead
 	// Minimum elevation considered "visible" (degrees)
 	minVisibleElevation = 0.0
+	// на сколько периодов орбиты вперед ищется восход, если это дольше defaultMaxSearchDuration
+	maxSearchPeriods = 3
+	// Earth rotation rate (rad/s)
+	earthRotationRate = 7.292115e-5
 )
 
+// New создает спутник с профилем расчета по умолчанию (DefaultProfile)
 func New(line1 string, line2 string) Satellite {
-	sat := satellite.TLEToSat(line1, line2, satellite.GravityWGS84)
+	sat := newWithGravity(line1, line2, satellite.Gravity(DefaultProfile.Gravity))
+	sat.profile = DefaultProfile
+
+	return sat
+}
+
+// newWithGravity создает спутник с гравитационной моделью SGP4 gravity
+func newWithGravity(line1, line2 string, gravity satellite.Gravity) Satellite {
+	sat := satellite.TLEToSat(numericCatalogLine(line1), numericCatalogLine(line2), gravity)
+
+	epoch, fraction := sgp4Epoch(line1)
 
 	return Satellite{
-		line1: line1,
-		line2: line2,
-		sat:   &sat,
+		line1:         line1,
+		line2:         line2,
+		sat:           &sat,
+		epoch:         epoch,
+		epochFraction: fraction,
 	}
 }
 
 // функция возвращает широту, долготу, высоту спутника
 func (s Satellite) Calculate(t time.Time) (*SatelliteCoords, error) {
-	if s.sat == nil {
-		return nil, errors.New("sateliite is not configured")
+	// рассчитываем позицию спутника на переданный момент времени
+	sv, err := s.StateVector(t.UTC())
+	if err != nil {
+		return nil, err
 	}
 
-	// рассчитываем позицию спутника на переданный момент времени
-	position, _ := satellite.Propagate(*s.sat, t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second())
+	coords := sv.Coords()
 
-	// GST
-	// вернет значение времени в радианах, угловое положение Земли относительно полярной звезды на основе переданного времени
-	gst := satellite.GSTimeFromDate(t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second())
+	return &coords, nil
+}
 
+// coordsFromECI переводит положение в ECI в широту, долготу и высоту
+func coordsFromECI(position satellite.Vector3, gst float64) SatelliteCoords {
 	// Geodesic coordinates (ECI -> LLA)
 	alt, _, latLng := satellite.ECIToLLA(position, gst)
 
@@ -56,31 +75,57 @@ func (s Satellite) Calculate(t time.Time) (*SatelliteCoords, error) {
 
 	lat = math.Mod(lat+540, 360) - 180
 
-	return &SatelliteCoords{
+	return SatelliteCoords{
 		Lat:       lat,
 		Lon:       lon,
 		Alt:       alt,
 		GMapsLink: fmt.Sprintf("https://www.google.com/maps/place/%f+%f", lat, lon),
-	}, nil
+	}
 }
 
+// LookAngles возвращает азимут, угол места и дальность до спутника от наблюдателя.
+// Если у спутника задана рефракция (WithRefraction), угол места - видимый, иначе геометрический.
 func (s Satellite) LookAngles(t time.Time, obsCoords ObserverCoords) LookAngles {
-	jday := satellite.JDay(t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second())
-
 	// рассчитываем позицию спутника на переданный момент времени
-	satPosition, _ := satellite.Propagate(*s.sat, t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second())
+	sv, err := s.StateVector(t.UTC())
+	if err != nil {
+		// как и SGP4 при ошибке, возвращаем NaN: поиск восхода просто не найдет пересечения горизонта
+		nan := math.NaN()
 
-	observerPosition := satellite.LatLong{
-		Latitude:  obsCoords.Lat * satellite.DEG2RAD,
-		Longitude: obsCoords.Lon * satellite.DEG2RAD,
+		return LookAngles{Az: nan, El: nan, Range: nan, RangeRate: nan}
 	}
 
-	lookAngles := satellite.ECIToLookAngles(satPosition, observerPosition, obsCoords.Alt, jday)
+	return sv.LookAngles(obsCoords, s.refraction)
+}
+
+// lookAnglesFromECI рассчитывает направление на спутник по его положению и скорости в TEME.
+// Наблюдатель стоит на эллипсоиде WGS-84, расчет ведется во вращающейся вместе с Землей системе
+// (поворот на звездное время gst, рад).
+func lookAnglesFromECI(satPosition, satVelocity satellite.Vector3, obsCoords ObserverCoords, gst float64, refraction Refraction) LookAngles {
+	p := temeToECEF(vector{satPosition.X, satPosition.Y, satPosition.Z}, gst)
+
+	// скорость относительно вращающейся Земли: v - ω × r
+	v := temeToECEF(vector{satVelocity.X, satVelocity.Y, satVelocity.Z}, gst)
+	v = v.sub(vector{-earthRotationRate * p[1], earthRotationRate * p[0], 0})
+
+	o := geodeticToECEF(obsCoords.Lat, obsCoords.Lon, obsCoords.Alt)
+	up := geodeticUp(obsCoords.Lat, obsCoords.Lon)
+
+	sinLon, cosLon := math.Sincos(obsCoords.Lon * math.Pi / 180)
+	east := vector{-sinLon, cosLon, 0}
+	north := up.cross(east)
+
+	los := p.sub(o)
+	rng := los.norm()
+
+	el := math.Asin(los.dot(up)/rng) * 180 / math.Pi
+	az := normalizeDegrees(math.Atan2(los.dot(east), los.dot(north)) * 180 / math.Pi)
 
 	return LookAngles{
-		Az:    lookAngles.Az * satellite.RAD2DEG,
-		El:    lookAngles.El * satellite.RAD2DEG,
-		Range: lookAngles.Rg,
+		Az:        az,
+		El:        el + refraction.Correction(el),
+		Range:     rng,
+		RangeRate: los.dot(v) / rng,
 	}
 }
 
@@ -186,7 +231,35 @@ func (s Satellite) findNextElevationEvent(startTime time.Time, obsCoords Observe
 // t: The time to start searching from.
 // obsCoords: Observer's coordinates (latitude, longitude, altitude).
 // n: The desired number of visibility ranges to find (n >= 1).
+//
+// Шаг поиска и длительность поиска зависят от типа орбиты (см. RegimeInfo). На высоких орбитах
+// пролет, который уже идет в момент t, возвращается с RisesBefore, а пролет, который не заканчивается
+// до конца поиска (например, геостационарный спутник виден постоянно), - с SetsAfter.
 func (s Satellite) VisibleTimeRange(t time.Time, obsCoords ObserverCoords, n int) []TimeRange {
+	return s.visibleTimeRanges(t, obsCoords, n, false)
+}
+
+// PassesBetween возвращает все пролеты, пересекающиеся с интервалом [from, to). Пролет, который уже идет
+// в момент from, возвращается на любой орбите с From = from и RisesBefore.
+func (s Satellite) PassesBetween(from, to time.Time, obsCoords ObserverCoords) []TimeRange {
+	res := []TimeRange{}
+
+	t := from
+	for t.Before(to) {
+		ranges := s.visibleTimeRanges(t, obsCoords, 1, true)
+		if len(ranges) == 0 || !ranges[0].From.Before(to) {
+			break
+		}
+
+		res = append(res, ranges[0])
+		t = ranges[0].To.Add(time.Second)
+	}
+
+	return res
+}
+
+// visibleTimeRanges - VisibleTimeRange; inProgress - возвращать уже идущий пролет и на низкой орбите
+func (s Satellite) visibleTimeRanges(t time.Time, obsCoords ObserverCoords, n int, inProgress bool) []TimeRange {
 	if n <= 0 {
 		return []TimeRange{}
 	}
@@ -199,6 +272,39 @@ func (s Satellite) VisibleTimeRange(t time.Time, obsCoords ObserverCoords, n int
 	precision := defaultEventTimePrecision
 	maxDuration := defaultMaxSearchDuration // Max duration for *each* rise/set search
 
+	regime, hasRegime := s.Regime()
+	if hasRegime {
+		coarseStep = regime.searchStep()
+		// хотя бы несколько витков, чтобы не пропустить пролеты у апогея
+		maxDuration = max(maxDuration, time.Duration(maxSearchPeriods*regime.PeriodMinutes*float64(time.Minute)))
+	}
+
+	add := func(tr TimeRange) {
+		tr.Difference = tr.To.Sub(tr.From).String()
+
+		if hasRegime {
+			tr.Regime = &regime
+			tr.Culminations = s.culminations(tr, obsCoords, regime.culminationStep())
+		}
+
+		timeRangeList = append(timeRangeList, tr)
+	}
+
+	// пролет LEO длится минуты, и уже начавшийся пролет пропускается, а на высоких орбитах
+	// пролет может идти часами, и он возвращается с момента t
+	inProgress = inProgress || hasRegime && regime.Regime != RegimeLEO
+	if inProgress && s.LookAngles(t, obsCoords).El >= minVisibleElevation {
+		setTime, foundSet := s.findNextElevationEvent(t, obsCoords, false, coarseStep, precision, maxDuration)
+		if !foundSet {
+			add(TimeRange{From: t, To: t.Add(maxDuration), RisesBefore: true, SetsAfter: true})
+
+			return timeRangeList
+		}
+
+		add(TimeRange{From: t, To: setTime, RisesBefore: true})
+		currentTime = setTime.Add(precision)
+	}
+
 	for len(timeRangeList) < n {
 		// 1. Find the next rise time
 		riseTime, foundRise := s.findNextElevationEvent(currentTime, obsCoords, true, coarseStep, precision, maxDuration)
@@ -214,27 +320,15 @@ func (s Satellite) VisibleTimeRange(t time.Time, obsCoords ObserverCoords, n int
 		setTime, foundSet := s.findNextElevationEvent(searchSetStartTime, obsCoords, false, coarseStep, precision, maxDuration)
 
 		if !foundSet {
-			// This is less likely if a rise was found, but possible if the pass is
-			// extremely short or calculation issues occur near the end of maxDuration.
-			// Or if the satellite rises but doesn't set within the remaining maxDuration.
-			// We can choose to stop, or log a warning and continue searching from riseTime + coarseStep.
-			// For simplicity, we stop here.
+			// спутник взошел, но не заходит до конца поиска: возвращаем пролет до конца поиска
+			add(TimeRange{From: riseTime, To: searchSetStartTime.Add(maxDuration), SetsAfter: true})
 			break
 		}
 
 		// 3. Add the valid range to the list
-		timeRange := TimeRange{
-			From: riseTime,
-			To:   setTime,
-		}
-		diff := timeRange.To.Sub(timeRange.From)
 		// Only add if duration is meaningful (longer than precision)
-		if diff > precision {
-			timeRange.Difference = diff.String()
-			timeRangeList = append(timeRangeList, timeRange)
-		} else {
-			// If rise/set are too close, it might be a glitch or extremely short pass.
-			// Skip it and continue searching.
+		if setTime.Sub(riseTime) > precision {
+			add(TimeRange{From: riseTime, To: setTime})
 		}
 
 		// 4. Update currentTime to search for the *next* pass after the current one ends
@@ -248,8 +342,27 @@ func (s *Satellite) UpdateTLE(line1, line2 string) {
 	s.line1 = line1
 	s.line2 = line2
 
-	sat := satellite.TLEToSat(line1, line2, satellite.GravityWGS84)
+	gravity := satellite.Gravity(s.profile.Gravity)
+	if gravity == "" {
+		gravity = satellite.Gravity(DefaultProfile.Gravity)
+	}
+
+	sat := satellite.TLEToSat(numericCatalogLine(line1), numericCatalogLine(line2), gravity)
 	s.sat = &sat
+	s.epoch, s.epochFraction = sgp4Epoch(line1)
+	// численный пропагатор начинался с вектора по старому TLE
+	s.propagator = nil
+}
+
+// numericCatalogLine заменяет номер по каталогу в формате Alpha-5 нулями:
+// go-satellite разбирает его как целое число и завершает программу при ошибке,
+// а для самого расчета SGP4 номер не нужен
+func numericCatalogLine(line string) string {
+	if len(line) < 7 || strings.IndexByte(alpha5Letters, line[2]) < 0 {
+		return line
+	}
+
+	return line[:2] + "00000" + line[7:]
 }
 
 func (sc SatelliteCoords) LatDirection() string {
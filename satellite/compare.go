@@ -0,0 +1,114 @@
+package satellite
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// RICDifference - разница положений по двум наборам элементов в орбитальной системе опорного спутника
+type RICDifference struct {
+	Time       time.Time `json:"time"`
+	Radial     float64   `json:"radial"`     // вдоль радиус-вектора, км
+	InTrack    float64   `json:"inTrack"`    // вдоль движения, км
+	CrossTrack float64   `json:"crossTrack"` // по нормали к плоскости орбиты, км
+	Total      float64   `json:"total"`      // расстояние между положениями, км
+}
+
+// RICSummary - сводка разниц по компонентам, км
+type RICSummary struct {
+	Radial     float64 `json:"radial"`
+	InTrack    float64 `json:"inTrack"`
+	CrossTrack float64 `json:"crossTrack"`
+	Total      float64 `json:"total"`
+}
+
+// Comparison - сравнение двух наборов элементов одного объекта на интервале
+type Comparison struct {
+	ReferenceEpoch time.Time `json:"referenceEpoch"`
+	OtherEpoch     time.Time `json:"otherEpoch"`
+	// разница эпох (other - reference), ч
+	EpochDifferenceHours float64 `json:"epochDifferenceHours"`
+
+	Differences []RICDifference `json:"differences"`
+	// наибольшие по модулю и среднеквадратичные разницы за интервал
+	Max RICSummary `json:"max"`
+	RMS RICSummary `json:"rms"`
+}
+
+// RIC раскладывает разницу положений other - reference по осям орбитальной системы reference:
+// радиальной, вдоль движения и по нормали к плоскости орбиты
+func RIC(reference, other StateVector) RICDifference {
+	r := vector{reference.X, reference.Y, reference.Z}
+	v := vector{reference.VX, reference.VY, reference.VZ}
+	d := vector{other.X, other.Y, other.Z}.sub(r)
+
+	radial := r.unit()
+	cross := r.cross(v).unit()
+	inTrack := cross.cross(radial)
+
+	return RICDifference{
+		Time:       reference.Time,
+		Radial:     d.dot(radial),
+		InTrack:    d.dot(inTrack),
+		CrossTrack: d.dot(cross),
+		Total:      d.norm(),
+	}
+}
+
+// Compare рассчитывает спутник по двум наборам элементов с шагом step от from до to и возвращает
+// разницу положений other относительно reference в системе RIC
+func Compare(reference, other Satellite, from, to time.Time, step time.Duration) (Comparison, error) {
+	referenceElements, err := ParseTLE(reference.line1, reference.line2)
+	if err != nil {
+		return Comparison{}, fmt.Errorf("ParseTLE: %w", err)
+	}
+
+	otherElements, err := ParseTLE(other.line1, other.line2)
+	if err != nil {
+		return Comparison{}, fmt.Errorf("ParseTLE: %w", err)
+	}
+
+	referenceStates, err := reference.StateVectors(from, to, step)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	otherStates, err := other.StateVectors(from, to, step)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	res := Comparison{
+		ReferenceEpoch:       referenceElements.Epoch,
+		OtherEpoch:           otherElements.Epoch,
+		EpochDifferenceHours: otherElements.Epoch.Sub(referenceElements.Epoch).Hours(),
+		Differences:          make([]RICDifference, 0, len(referenceStates)),
+	}
+
+	for i := range referenceStates {
+		d := RIC(referenceStates[i], otherStates[i])
+		res.Differences = append(res.Differences, d)
+
+		res.Max.Radial = math.Max(res.Max.Radial, math.Abs(d.Radial))
+		res.Max.InTrack = math.Max(res.Max.InTrack, math.Abs(d.InTrack))
+		res.Max.CrossTrack = math.Max(res.Max.CrossTrack, math.Abs(d.CrossTrack))
+		res.Max.Total = math.Max(res.Max.Total, d.Total)
+
+		res.RMS.Radial += d.Radial * d.Radial
+		res.RMS.InTrack += d.InTrack * d.InTrack
+		res.RMS.CrossTrack += d.CrossTrack * d.CrossTrack
+		res.RMS.Total += d.Total * d.Total
+	}
+
+	if n := float64(len(res.Differences)); n > 0 {
+		res.RMS = RICSummary{
+			Radial:     math.Sqrt(res.RMS.Radial / n),
+			InTrack:    math.Sqrt(res.RMS.InTrack / n),
+			CrossTrack: math.Sqrt(res.RMS.CrossTrack / n),
+			Total:      math.Sqrt(res.RMS.Total / n),
+		}
+	}
+
+	return res, nil
+}
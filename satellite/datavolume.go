@@ -0,0 +1,187 @@
+package satellite
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// шаг интегрирования объема данных по умолчанию
+const defaultDataVolumeStep = 10 * time.Second
+
+// BitrateStep - скорость передачи, доступная начиная с угла места MinEl
+type BitrateStep struct {
+	MinEl float64 `json:"minEl"` // град
+	Bps   float64 `json:"bps"`   // бит/с
+}
+
+// BitrateTable - зависимость скорости передачи от угла места
+type BitrateTable []BitrateStep
+
+// At возвращает наибольшую скорость, доступную при угле места el, бит/с
+func (b BitrateTable) At(el float64) float64 {
+	var res float64
+	for _, step := range b {
+		if el >= step.MinEl && step.Bps > res {
+			res = step.Bps
+		}
+	}
+
+	return res
+}
+
+// ContactStation - станция приема для расчета объема данных
+type ContactStation struct {
+	ID         int
+	Name       string
+	Coords     ObserverCoords
+	Refraction Refraction
+}
+
+// ContactPass - пролет над одной станцией
+type ContactPass struct {
+	StationID      int       `json:"stationId"`
+	StationName    string    `json:"stationName"`
+	Pass           TimeRange `json:"pass"`
+	MaxEl          float64   `json:"maxEl"`          // град
+	ContactSeconds float64   `json:"contactSeconds"` // время выше минимального угла места
+	Megabytes      float64   `json:"megabytes"`      // объем данных, если станция работает одна
+}
+
+// DailyVolume - объем данных за сутки (UTC) по всем станциям
+type DailyVolume struct {
+	Date           time.Time `json:"date"`
+	Passes         int       `json:"passes"`
+	ContactSeconds float64   `json:"contactSeconds"` // время, когда на связи хотя бы одна станция
+	OverlapSeconds float64   `json:"overlapSeconds"` // время, когда на связи больше одной станции
+	Megabytes      float64   `json:"megabytes"`
+}
+
+// DataVolumeReport - объем данных по пролетам и суткам
+type DataVolumeReport struct {
+	Passes              []ContactPass `json:"passes"`
+	Days                []DailyVolume `json:"days"`
+	TotalContactSeconds float64       `json:"totalContactSeconds"`
+	TotalMegabytes      float64       `json:"totalMegabytes"`
+}
+
+// DataVolume рассчитывает объем данных, который можно принять станциями stations от from до to.
+// Время контакта - когда угол места не меньше minEl. Скорость интегрируется с шагом step по общей
+// для всех станций сетке; если спутник виден с нескольких станций, учитывается одна, с наибольшей скоростью.
+func (s Satellite) DataVolume(stations []ContactStation, from, to time.Time, minEl float64, rates BitrateTable, step time.Duration) (DataVolumeReport, error) {
+	if !to.After(from) {
+		return DataVolumeReport{}, errors.New("конец интервала должен быть позже начала")
+	}
+
+	if step <= 0 {
+		step = defaultDataVolumeStep
+	}
+
+	// наибольшая скорость и количество станций на связи в узлах сетки from + k*step
+	bestRate := make(map[int]float64)
+	stationsInContact := make(map[int]int)
+
+	res := DataVolumeReport{
+		Passes: []ContactPass{},
+	}
+
+	for _, st := range stations {
+		sat := s.WithRefraction(st.Refraction)
+
+		// пролет, который уже идет, тоже учитывается
+		for _, tr := range sat.PassesBetween(from, to, st.Coords) {
+			pass := ContactPass{
+				StationID:   st.ID,
+				StationName: st.Name,
+				Pass:        tr,
+				MaxEl:       -90,
+			}
+
+			var bits float64
+
+			k := int(math.Ceil(float64(tr.From.Sub(from)) / float64(step)))
+			if k < 0 {
+				k = 0
+			}
+
+			for ; ; k++ {
+				tk := from.Add(time.Duration(k) * step)
+				if !tk.Before(to) || tk.After(tr.To) {
+					break
+				}
+
+				el := sat.LookAngles(tk, st.Coords).El
+				pass.MaxEl = math.Max(pass.MaxEl, el)
+
+				if el < minEl {
+					continue
+				}
+
+				rate := rates.At(el)
+
+				pass.ContactSeconds += step.Seconds()
+				bits += rate * step.Seconds()
+
+				stationsInContact[k]++
+				if rate > bestRate[k] {
+					bestRate[k] = rate
+				}
+			}
+
+			pass.Megabytes = bits / 8 / 1e6
+			res.Passes = append(res.Passes, pass)
+
+			if pass.ContactSeconds > 0 {
+				day := dayIndex(from, tr.From)
+				for len(res.Days) <= day {
+					res.Days = append(res.Days, DailyVolume{})
+				}
+				res.Days[day].Passes++
+			}
+		}
+	}
+
+	// сутки от начала интервала, по UTC
+	days := dayIndex(from, to.Add(-time.Nanosecond)) + 1
+	for len(res.Days) < days {
+		res.Days = append(res.Days, DailyVolume{})
+	}
+	res.Days = res.Days[:days]
+
+	start := from.UTC().Truncate(24 * time.Hour)
+	for i := range res.Days {
+		res.Days[i].Date = start.Add(time.Duration(i) * 24 * time.Hour)
+	}
+
+	for k, count := range stationsInContact {
+		day := dayIndex(from, from.Add(time.Duration(k)*step))
+
+		res.Days[day].ContactSeconds += step.Seconds()
+		res.Days[day].Megabytes += bestRate[k] * step.Seconds() / 8 / 1e6
+		if count > 1 {
+			res.Days[day].OverlapSeconds += step.Seconds()
+		}
+	}
+
+	for _, d := range res.Days {
+		res.TotalContactSeconds += d.ContactSeconds
+		res.TotalMegabytes += d.Megabytes
+	}
+
+	sort.Slice(res.Passes, func(i, j int) bool {
+		return res.Passes[i].Pass.From.Before(res.Passes[j].Pass.From)
+	})
+
+	return res, nil
+}
+
+// dayIndex возвращает номер суток UTC момента t, считая сутки, в которые попадает from, нулевыми
+func dayIndex(from, t time.Time) int {
+	start := from.UTC().Truncate(24 * time.Hour)
+	if t.Before(start) {
+		return 0
+	}
+
+	return int(t.Sub(start) / (24 * time.Hour))
+}
@@ -0,0 +1,16 @@
+package satellite
+
+// скорость света, км/с
+const speedOfLight = 299792.458
+
+// DownlinkFrequency возвращает частоту, на которой наземная станция примет сигнал
+// спутника, передающего на частоте freq, при скорости изменения дальности rangeRate (км/с)
+func DownlinkFrequency(freq, rangeRate float64) float64 {
+	return freq * (1 - rangeRate/speedOfLight)
+}
+
+// UplinkFrequency возвращает частоту, на которой наземная станция должна передавать,
+// чтобы спутник принял сигнал на частоте freq, при скорости изменения дальности rangeRate (км/с)
+func UplinkFrequency(freq, rangeRate float64) float64 {
+	return freq / (1 - rangeRate/speedOfLight)
+}
@@ -0,0 +1,357 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// максимальный номер по каталогу, который помещается в TLE в формате Alpha-5 (Z9999)
+const maxAlpha5CatalogNumber = 339999
+
+var ErrCatalogNumberTooLarge = errors.New("номер по каталогу не помещается в формат TLE (Alpha-5)")
+
+// Elements - средние элементы орбиты SGP4, общие для TLE и CCSDS OMM
+type Elements struct {
+	ObjectName      string
+	ObjectID        string // международное обозначение, например 2023-091G
+	CatalogNumber   int
+	Classification  string // U, C, S
+	Epoch           time.Time
+	MeanMotion      float64 // об/сут
+	Eccentricity    float64
+	Inclination     float64 // град
+	RAAN            float64 // долгота восходящего узла, град
+	ArgOfPericenter float64 // аргумент перицентра, град
+	MeanAnomaly     float64 // град
+	EphemerisType   int
+	ElementSetNo    int
+	RevAtEpoch      int
+	BStar           float64 // 1/радиус Земли
+	MeanMotionDot   float64 // об/сут² (уже поделено на 2, как в TLE)
+	MeanMotionDDot  float64 // об/сут³ (уже поделено на 6, как в TLE)
+}
+
+// ParseTLE разбирает двухстрочный набор элементов. Номер по каталогу может быть в формате Alpha-5.
+func ParseTLE(line1, line2 string) (Elements, error) {
+	line1 = strings.TrimRight(line1, " \r\n")
+	line2 = strings.TrimRight(line2, " \r\n")
+
+	if len(line1) < 64 || len(line2) < 63 || line1[0] != '1' || line2[0] != '2' {
+		return Elements{}, errors.New("некорректный формат TLE")
+	}
+
+	var (
+		e   Elements
+		err error
+	)
+
+	fail := func(field string, err error) (Elements, error) {
+		return Elements{}, fmt.Errorf("ошибка разбора поля TLE %s: %w", field, err)
+	}
+
+	e.CatalogNumber, err = decodeAlpha5(line1[2:7])
+	if err != nil {
+		return fail("catalog number", err)
+	}
+
+	e.Classification = strings.TrimSpace(line1[7:8])
+	e.ObjectID = designatorToObjectID(strings.TrimSpace(line1[9:17]))
+
+	year, err := strconv.Atoi(strings.TrimSpace(line1[18:20]))
+	if err != nil {
+		return fail("epoch year", err)
+	}
+	if year < 57 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+
+	days, err := strconv.ParseFloat(strings.TrimSpace(line1[20:32]), 64)
+	if err != nil {
+		return fail("epoch day", err)
+	}
+	e.Epoch = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration((days - 1) * float64(24*time.Hour)))
+
+	e.MeanMotionDot, err = strconv.ParseFloat(strings.ReplaceAll(line1[33:43], " ", ""), 64)
+	if err != nil {
+		return fail("mean motion dot", err)
+	}
+
+	e.MeanMotionDDot, err = parseTLEExp(line1[44:52])
+	if err != nil {
+		return fail("mean motion ddot", err)
+	}
+
+	e.BStar, err = parseTLEExp(line1[53:61])
+	if err != nil {
+		return fail("bstar", err)
+	}
+
+	if eph := strings.TrimSpace(line1[62:63]); eph != "" {
+		e.EphemerisType, _ = strconv.Atoi(eph)
+	}
+	e.ElementSetNo, _ = strconv.Atoi(strings.TrimSpace(line1[64:min(68, len(line1))]))
+
+	fields := []struct {
+		name string
+		dst  *float64
+		raw  string
+	}{
+		{"inclination", &e.Inclination, line2[8:16]},
+		{"raan", &e.RAAN, line2[17:25]},
+		{"arg of pericenter", &e.ArgOfPericenter, line2[34:42]},
+		{"mean anomaly", &e.MeanAnomaly, line2[43:51]},
+		{"mean motion", &e.MeanMotion, line2[52:63]},
+	}
+	for _, f := range fields {
+		*f.dst, err = strconv.ParseFloat(strings.TrimSpace(f.raw), 64)
+		if err != nil {
+			return fail(f.name, err)
+		}
+	}
+
+	e.Eccentricity, err = strconv.ParseFloat("."+strings.TrimSpace(line2[26:33]), 64)
+	if err != nil {
+		return fail("eccentricity", err)
+	}
+
+	if len(line2) >= 68 {
+		e.RevAtEpoch, _ = strconv.Atoi(strings.TrimSpace(line2[63:68]))
+	}
+
+	return e, nil
+}
+
+// TLE формирует двухстрочный набор элементов с контрольными суммами.
+// Номера по каталогу от 100000 до 339999 кодируются в формате Alpha-5,
+// для больших номеров возвращается ErrCatalogNumberTooLarge.
+func (e Elements) TLE() (string, string, error) {
+	catalog, err := encodeAlpha5(e.CatalogNumber)
+	if err != nil {
+		return "", "", err
+	}
+
+	if math.Abs(e.MeanMotionDot) >= 1 {
+		return "", "", errors.New("MEAN_MOTION_DOT не помещается в формат TLE")
+	}
+
+	class := e.Classification
+	if class == "" {
+		class = "U"
+	}
+
+	epoch := e.Epoch.UTC()
+	yearStart := time.Date(epoch.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	days := epoch.Sub(yearStart).Hours()/24 + 1
+
+	ndotSign := " "
+	if e.MeanMotionDot < 0 {
+		ndotSign = "-"
+	}
+	ndot := fmt.Sprintf("%s.%08d", ndotSign, int64(math.Round(math.Abs(e.MeanMotionDot)*1e8)))
+
+	nddot, err := formatTLEExp(e.MeanMotionDDot)
+	if err != nil {
+		return "", "", fmt.Errorf("MEAN_MOTION_DDOT: %w", err)
+	}
+
+	bstar, err := formatTLEExp(e.BStar)
+	if err != nil {
+		return "", "", fmt.Errorf("BSTAR: %w", err)
+	}
+
+	line1 := fmt.Sprintf("1 %5s%1s %-8s %02d%012.8f %s %s %s %d %4d",
+		catalog, class, objectIDToDesignator(e.ObjectID), epoch.Year()%100, days,
+		ndot, nddot, bstar, e.EphemerisType, e.ElementSetNo%10000)
+
+	ecc := int64(math.Round(e.Eccentricity * 1e7))
+	if ecc > 9999999 {
+		return "", "", errors.New("эксцентриситет не помещается в формат TLE")
+	}
+
+	line2 := fmt.Sprintf("2 %5s %8.4f %8.4f %07d %8.4f %8.4f %11.8f%5d",
+		catalog, normalizeDegrees(e.Inclination), normalizeDegrees(e.RAAN), ecc,
+		normalizeDegrees(e.ArgOfPericenter), normalizeDegrees(e.MeanAnomaly), e.MeanMotion, e.RevAtEpoch%100000)
+
+	return line1 + strconv.Itoa(tleChecksum(line1)), line2 + strconv.Itoa(tleChecksum(line2)), nil
+}
+
+// PropagationTLE формирует TLE для пропагатора: номер по каталогу, который не помещается
+// в формат, заменяется нулем (SGP4 его не использует)
+func (e Elements) PropagationTLE() (string, string, error) {
+	line1, line2, err := e.TLE()
+	if errors.Is(err, ErrCatalogNumberTooLarge) {
+		e.CatalogNumber = 0
+		return e.TLE()
+	}
+
+	return line1, line2, err
+}
+
+// NewFromElements создает спутник из средних элементов (например, полученных из OMM)
+func NewFromElements(e Elements) (Satellite, error) {
+	line1, line2, err := e.PropagationTLE()
+	if err != nil {
+		return Satellite{}, err
+	}
+
+	return New(line1, line2), nil
+}
+
+// буквы Alpha-5: I и O не используются, чтобы не путать с 1 и 0
+const alpha5Letters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+func encodeAlpha5(n int) (string, error) {
+	switch {
+	case n < 0:
+		return "", fmt.Errorf("отрицательный номер по каталогу: %d", n)
+	case n < 100000:
+		return fmt.Sprintf("%05d", n), nil
+	case n <= maxAlpha5CatalogNumber:
+		return fmt.Sprintf("%c%04d", alpha5Letters[n/10000-10], n%10000), nil
+	}
+
+	return "", ErrCatalogNumberTooLarge
+}
+
+func decodeAlpha5(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("пустой номер по каталогу")
+	}
+
+	if idx := strings.IndexByte(alpha5Letters, s[0]); idx >= 0 {
+		rest, err := strconv.Atoi(s[1:])
+		if err != nil {
+			return 0, err
+		}
+
+		return (idx+10)*10000 + rest, nil
+	}
+
+	return strconv.Atoi(s)
+}
+
+// objectIDToDesignator переводит "2023-091G" в формат TLE "23091G"
+func objectIDToDesignator(id string) string {
+	if len(id) < 9 || id[4] != '-' {
+		return id
+	}
+
+	return id[2:4] + id[5:]
+}
+
+// designatorToObjectID переводит "23091G" из TLE в "2023-091G"
+func designatorToObjectID(d string) string {
+	if len(d) < 5 {
+		return d
+	}
+
+	year, err := strconv.Atoi(d[:2])
+	if err != nil {
+		return d
+	}
+
+	if year < 57 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+
+	return fmt.Sprintf("%d-%s", year, d[2:])
+}
+
+// parseTLEExp разбирает поле вида " 59089-3" (= 0.59089e-3)
+func parseTLEExp(field string) (float64, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return 0, nil
+	}
+
+	sign := ""
+	if field[0] == '-' || field[0] == '+' {
+		if field[0] == '-' {
+			sign = "-"
+		}
+		field = field[1:]
+	}
+
+	if len(field) < 2 {
+		return 0, fmt.Errorf("некорректное значение %q", field)
+	}
+
+	mantissa, exp := field[:len(field)-2], field[len(field)-2:]
+
+	return strconv.ParseFloat(sign+"0."+mantissa+"e"+exp, 64)
+}
+
+// formatTLEExp форматирует значение в поле вида " 59089-3". Порядок в поле - одна цифра, поэтому
+// значения меньше 1e-10 по модулю записываются нулем, а от 1e9 и больше не помещаются в поле.
+func formatTLEExp(v float64) (string, error) {
+	if v == 0 {
+		return " 00000-0", nil
+	}
+
+	sign := " "
+	if v < 0 {
+		sign = "-"
+	}
+
+	v = math.Abs(v)
+	exp := int(math.Floor(math.Log10(v))) + 1
+	mantissa := int64(math.Round(v / math.Pow(10, float64(exp)) * 1e5))
+	if mantissa >= 100000 {
+		mantissa /= 10
+		exp++
+	}
+
+	if exp < -9 {
+		return " 00000-0", nil
+	}
+	if exp > 9 {
+		return "", errors.New("значение не помещается в формат TLE")
+	}
+
+	expSign := "+"
+	if exp < 0 {
+		expSign = "-"
+	}
+
+	return fmt.Sprintf("%s%05d%s%d", sign, mantissa, expSign, absInt(exp)), nil
+}
+
+func tleChecksum(line string) int {
+	sum := 0
+	for _, c := range line {
+		switch {
+		case c >= '0' && c <= '9':
+			sum += int(c - '0')
+		case c == '-':
+			sum++
+		}
+	}
+
+	return sum % 10
+}
+
+func normalizeDegrees(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+
+	return deg
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,66 @@
+package satellite
+
+import "testing"
+
+func TestFormatTLEExp(t *testing.T) {
+	cases := []struct {
+		v    float64
+		want string
+	}{
+		{v: 0, want: " 00000-0"},
+		{v: 0.5, want: " 50000+0"},
+		{v: -0.5, want: "-50000+0"},
+		{v: 3.4701e-4, want: " 34701-3"},
+		{v: -3.4701e-4, want: "-34701-3"},
+		{v: 12345, want: " 12345+5"},
+		{v: 1e-10, want: " 10000-9"},
+		{v: 9.999996e-11, want: " 10000-9"},
+		{v: 1.2e-11, want: " 00000-0"},
+		{v: -1.2e-11, want: " 00000-0"},
+		{v: 1e-30, want: " 00000-0"},
+	}
+
+	for _, c := range cases {
+		got, err := formatTLEExp(c.v)
+		if err != nil {
+			t.Errorf("formatTLEExp(%g): %v", c.v, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("formatTLEExp(%g) = %q, want %q", c.v, got, c.want)
+		}
+	}
+
+	for _, v := range []float64{1e9, -1e9, 0.999999e9, 1e20} {
+		if got, err := formatTLEExp(v); err == nil {
+			t.Errorf("formatTLEExp(%g) = %q, want error", v, got)
+		}
+	}
+}
+
+func TestElementsTLESmallBStar(t *testing.T) {
+	elements, err := ParseTLE(fitLine1, fitLine2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements.BStar = 1.2e-11
+
+	line1, line2, err := elements.TLE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseTLE(line1, line2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.BStar != 0 {
+		t.Errorf("BSTAR = %g, want 0", parsed.BStar)
+	}
+
+	elements.BStar = 2e9
+	if _, _, err := elements.TLE(); err == nil {
+		t.Error("BSTAR 2e9: want error")
+	}
+}
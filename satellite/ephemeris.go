@@ -0,0 +1,70 @@
+package satellite
+
+import (
+	"errors"
+	"time"
+
+	"github.com/joshuaferrara/go-satellite"
+)
+
+// StateVector - положение и скорость спутника в системе TEME
+type StateVector struct {
+	Time time.Time `json:"time"`
+	X    float64   `json:"x"` // км
+	Y    float64   `json:"y"`
+	Z    float64   `json:"z"`
+	VX   float64   `json:"vx"` // км/с
+	VY   float64   `json:"vy"`
+	VZ   float64   `json:"vz"`
+}
+
+// StateVector возвращает вектор состояния спутника в системе TEME на момент t
+func (s Satellite) StateVector(t time.Time) (StateVector, error) {
+	return s.Propagator().StateVector(t)
+}
+
+// StateVectors возвращает векторы состояния с шагом step от from до to включительно.
+// Шаг должен быть кратен секунде.
+func (s Satellite) StateVectors(from, to time.Time, step time.Duration) ([]StateVector, error) {
+	if step < time.Second || step%time.Second != 0 {
+		return nil, errors.New("шаг должен быть целым числом секунд")
+	}
+
+	if to.Before(from) {
+		return nil, errors.New("конец интервала раньше начала")
+	}
+
+	res := make([]StateVector, 0, int(to.Sub(from)/step)+1)
+	for t := from; !t.After(to); t = t.Add(step) {
+		sv, err := s.StateVector(t)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, sv)
+	}
+
+	return res, nil
+}
+
+// ECEF переводит положение из TEME во вращающуюся вместе с Землей систему (поворот на звездное время,
+// движение полюса не учитывается), км
+func (sv StateVector) ECEF() (float64, float64, float64) {
+	v := temeToECEF(vector{sv.X, sv.Y, sv.Z}, greenwichSiderealTime(sv.Time))
+
+	return v[0], v[1], v[2]
+}
+
+// Coords возвращает широту, долготу и высоту спутника
+func (sv StateVector) Coords() SatelliteCoords {
+	return coordsFromECI(satellite.Vector3{X: sv.X, Y: sv.Y, Z: sv.Z}, greenwichSiderealTime(sv.Time))
+}
+
+// LookAngles возвращает направление на спутник от наблюдателя с учетом рефракции refraction
+func (sv StateVector) LookAngles(obsCoords ObserverCoords, refraction Refraction) LookAngles {
+	return lookAnglesFromECI(
+		satellite.Vector3{X: sv.X, Y: sv.Y, Z: sv.Z},
+		satellite.Vector3{X: sv.VX, Y: sv.VY, Z: sv.VZ},
+		obsCoords, greenwichSiderealTime(sv.Time), refraction,
+	)
+}
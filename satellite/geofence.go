@@ -0,0 +1,294 @@
+package satellite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// События пересечения границы области
+const (
+	GeofenceEntry GeofenceEventType = "entry"
+	GeofenceExit  GeofenceEventType = "exit"
+)
+
+const (
+	// шаг поиска пересечений границ областей: за это время подспутниковая точка проходит около 75 км
+	geofenceStep = 10 * time.Second
+	// точность моментов входа и выхода
+	geofencePrecision = 100 * time.Millisecond
+)
+
+type GeofenceEventType string
+
+// Geofence - именованная область на поверхности Земли: многоугольники из GeoJSON,
+// каждый - внешнее кольцо и дыры
+type Geofence struct {
+	ID       int
+	Name     string
+	Polygons [][][]GeoPoint
+}
+
+// GeofenceEvent - вход подспутниковой точки в область или выход из нее
+type GeofenceEvent struct {
+	Time  time.Time         `json:"time"`
+	Event GeofenceEventType `json:"event"`
+	Lat   float64           `json:"lat"`
+	Lon   float64           `json:"lon"`
+}
+
+// GeofenceReport - события одной области за интервал
+type GeofenceReport struct {
+	GeofenceID    int             `json:"geofenceId"`
+	Name          string          `json:"name"`
+	InsideAtStart bool            `json:"insideAtStart"` // подспутниковая точка в области в начале интервала
+	InsideAtEnd   bool            `json:"insideAtEnd"`
+	Events        []GeofenceEvent `json:"events"`
+	// суммарное время внутри области за интервал, с
+	InsideSeconds float64 `json:"insideSeconds"`
+}
+
+// geoJSON - части GeoJSON (RFC 7946), в которых могут быть многоугольники
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Features    []geoJSON       `json:"features"`
+	Geometries  []geoJSON       `json:"geometries"`
+}
+
+// ParseGeoJSON разбирает многоугольники из GeoJSON: Polygon, MultiPolygon, Feature, FeatureCollection
+// или GeometryCollection. Области, пересекающие меридиан 180°, по RFC 7946 должны быть разрезаны по нему.
+func ParseGeoJSON(data []byte) ([][][]GeoPoint, error) {
+	var g geoJSON
+
+	err := json.Unmarshal(data, &g)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора GeoJSON: %w", err)
+	}
+
+	polygons, err := g.polygons()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(polygons) == 0 {
+		return nil, errors.New("в GeoJSON нет многоугольников")
+	}
+
+	return polygons, nil
+}
+
+func (g geoJSON) polygons() ([][][]GeoPoint, error) {
+	switch g.Type {
+	case "Polygon":
+		var coords [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &coords); err != nil {
+			return nil, fmt.Errorf("некорректные координаты Polygon: %w", err)
+		}
+
+		polygon, err := geoJSONPolygon(coords)
+		if err != nil {
+			return nil, err
+		}
+
+		return [][][]GeoPoint{polygon}, nil
+
+	case "MultiPolygon":
+		var coords [][][][]float64
+		if err := json.Unmarshal(g.Coordinates, &coords); err != nil {
+			return nil, fmt.Errorf("некорректные координаты MultiPolygon: %w", err)
+		}
+
+		res := make([][][]GeoPoint, 0, len(coords))
+		for _, c := range coords {
+			polygon, err := geoJSONPolygon(c)
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, polygon)
+		}
+
+		return res, nil
+
+	case "Feature":
+		if g.Geometry == nil {
+			return nil, nil
+		}
+
+		return g.Geometry.polygons()
+
+	case "FeatureCollection", "GeometryCollection":
+		var res [][][]GeoPoint
+
+		for _, part := range append(g.Features, g.Geometries...) {
+			polygons, err := part.polygons()
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, polygons...)
+		}
+
+		return res, nil
+
+	case "Point", "MultiPoint", "LineString", "MultiLineString":
+		// не области
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("неизвестный тип GeoJSON: %q", g.Type)
+}
+
+// geoJSONPolygon переводит кольца многоугольника из позиций GeoJSON [долгота, широта]
+func geoJSONPolygon(coords [][][]float64) ([][]GeoPoint, error) {
+	if len(coords) == 0 {
+		return nil, errors.New("многоугольник без колец")
+	}
+
+	res := make([][]GeoPoint, 0, len(coords))
+	for _, ring := range coords {
+		if len(ring) < 4 {
+			return nil, errors.New("кольцо многоугольника должно состоять из четырех и более позиций")
+		}
+
+		points := make([]GeoPoint, 0, len(ring))
+		for _, pos := range ring {
+			if len(pos) < 2 {
+				return nil, errors.New("позиция GeoJSON должна содержать долготу и широту")
+			}
+
+			points = append(points, GeoPoint{Lat: pos[1], Lon: pos[0]})
+		}
+
+		res = append(res, points)
+	}
+
+	return res, nil
+}
+
+// Contains проверяет попадание точки в область: внутри внешнего кольца одного из многоугольников
+// и вне его дыр
+func (g Geofence) Contains(p GeoPoint) bool {
+	for _, polygon := range g.Polygons {
+		if !pointInPolygon(p, polygon[0]) {
+			continue
+		}
+
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if pointInPolygon(p, hole) {
+				inHole = true
+				break
+			}
+		}
+
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GeofenceEvents находит входы подспутниковой точки в области и выходы из них от from до to.
+// Подспутниковая точка считается по Calculate с шагом geofenceStep, моменты пересечения границы
+// уточняются делением пополам.
+func (s Satellite) GeofenceEvents(from, to time.Time, fences []Geofence) ([]GeofenceReport, error) {
+	if !to.After(from) {
+		return nil, errors.New("конец интервала должен быть позже начала")
+	}
+
+	subSatellite := func(t time.Time) (GeoPoint, error) {
+		c, err := s.Calculate(t)
+		return GeoPoint{Lat: c.Lat, Lon: c.Lon}, err
+	}
+
+	start, err := subSatellite(from)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]GeofenceReport, len(fences))
+	inside := make([]bool, len(fences))
+	enteredAt := make([]time.Time, len(fences))
+
+	for i, f := range fences {
+		inside[i] = f.Contains(start)
+		enteredAt[i] = from
+
+		res[i] = GeofenceReport{
+			GeofenceID:    f.ID,
+			Name:          f.Name,
+			InsideAtStart: inside[i],
+			Events:        []GeofenceEvent{},
+		}
+	}
+
+	prevT := from
+	for t := from.Add(geofenceStep); ; t = t.Add(geofenceStep) {
+		if t.After(to) {
+			t = to
+		}
+
+		p, err := subSatellite(t)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, f := range fences {
+			if f.Contains(p) == inside[i] {
+				continue
+			}
+
+			// граница между prevT и t: уточняем делением пополам
+			lo, hi := prevT, t
+			for hi.Sub(lo) > geofencePrecision {
+				mid := lo.Add(hi.Sub(lo) / 2)
+
+				mp, err := subSatellite(mid)
+				if err != nil {
+					return nil, err
+				}
+
+				if f.Contains(mp) == inside[i] {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+
+			at, err := subSatellite(hi)
+			if err != nil {
+				return nil, err
+			}
+
+			event := GeofenceEvent{Time: hi, Event: GeofenceEntry, Lat: at.Lat, Lon: at.Lon}
+			if inside[i] {
+				event.Event = GeofenceExit
+				res[i].InsideSeconds += hi.Sub(enteredAt[i]).Seconds()
+			} else {
+				enteredAt[i] = hi
+			}
+
+			res[i].Events = append(res[i].Events, event)
+			inside[i] = !inside[i]
+		}
+
+		prevT = t
+		if !t.Before(to) {
+			break
+		}
+	}
+
+	for i := range fences {
+		res[i].InsideAtEnd = inside[i]
+		if inside[i] {
+			res[i].InsideSeconds += to.Sub(enteredAt[i]).Seconds()
+		}
+	}
+
+	return res, nil
+}
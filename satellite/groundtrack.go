@@ -0,0 +1,105 @@
+package satellite
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// средний радиус Земли для расчета зоны видимости, км
+const earthRadiusKm = 6371.0
+
+// GeoPoint - точка на поверхности Земли, град
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// GroundTrack возвращает подспутниковые точки с шагом step от from до to включительно
+func (s Satellite) GroundTrack(from, to time.Time, step time.Duration) ([]GeoPoint, error) {
+	if step <= 0 {
+		return nil, errors.New("шаг должен быть больше нуля")
+	}
+
+	var res []GeoPoint
+	for t := from; !t.After(to); t = t.Add(step) {
+		coords, err := s.Calculate(t)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, GeoPoint{Lat: coords.Lat, Lon: coords.Lon})
+	}
+
+	return res, nil
+}
+
+// Footprint возвращает границу зоны радиовидимости спутника (угол места 0°) из n точек.
+// Кольцо замкнуто: последняя точка совпадает с первой.
+func Footprint(coords SatelliteCoords, n int) []GeoPoint {
+	if n < 3 {
+		n = 3
+	}
+
+	// центральный угол между подспутниковой точкой и горизонтом
+	lambda := math.Acos(earthRadiusKm / (earthRadiusKm + math.Max(coords.Alt, 0)))
+
+	lat0 := coords.Lat * math.Pi / 180
+	lon0 := coords.Lon * math.Pi / 180
+
+	res := make([]GeoPoint, 0, n+1)
+	for i := 0; i <= n; i++ {
+		bearing := 2 * math.Pi * float64(i%n) / float64(n)
+
+		lat := math.Asin(math.Sin(lat0)*math.Cos(lambda) + math.Cos(lat0)*math.Sin(lambda)*math.Cos(bearing))
+		lon := lon0 + math.Atan2(math.Sin(bearing)*math.Sin(lambda)*math.Cos(lat0), math.Cos(lambda)-math.Sin(lat0)*math.Sin(lat))
+
+		res = append(res, GeoPoint{
+			Lat: lat * 180 / math.Pi,
+			Lon: math.Mod(lon*180/math.Pi+540, 360) - 180,
+		})
+	}
+
+	return res
+}
+
+// SplitAtAntimeridian разбивает линию на части там, где она пересекает меридиан 180°,
+// добавляя точки пересечения на концы частей. Иначе картографические программы
+// рисуют отрезок через всю карту.
+func SplitAtAntimeridian(points []GeoPoint) [][]GeoPoint {
+	if len(points) == 0 {
+		return nil
+	}
+
+	var (
+		res     [][]GeoPoint
+		current = []GeoPoint{points[0]}
+	)
+
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1], points[i]
+
+		if math.Abs(cur.Lon-prev.Lon) > 180 {
+			// переносим текущую точку на сторону предыдущей и находим широту на 180°
+			curLon := cur.Lon
+			edge := 180.0
+			if prev.Lon < 0 {
+				curLon -= 360
+				edge = -180
+			} else {
+				curLon += 360
+			}
+
+			k := (edge - prev.Lon) / (curLon - prev.Lon)
+			lat := prev.Lat + k*(cur.Lat-prev.Lat)
+
+			current = append(current, GeoPoint{Lat: lat, Lon: edge})
+			res = append(res, current)
+			current = []GeoPoint{{Lat: lat, Lon: -edge}}
+		}
+
+		current = append(current, cur)
+	}
+
+	return append(res, current)
+}
@@ -0,0 +1,450 @@
+package satellite
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	// максимальный шаг поиска окон съемки
+	maxImagingStep = 10 * time.Second
+	// максимальное количество точек, которыми представляется площадная цель
+	maxImagingSamples = 2000
+	// максимальное количество точек перебора внутри окна при поиске наилучшего момента
+	maxImagingScanPoints = 600
+)
+
+// Sensor - параметры съемочной аппаратуры
+type Sensor struct {
+	HalfAngle   float64 // половина угла поля зрения, град
+	MaxOffNadir float64 // максимальное отклонение оси от надира, град
+	// минимальная высота Солнца над горизонтом в точке цели, град. nil - без ограничения
+	MinSunElevation *float64
+}
+
+// ImagingAccess - окно, в котором цель попадает в поле зрения аппаратуры
+type ImagingAccess struct {
+	TimeRange
+	BestTime     time.Time `json:"bestTime"`     // момент наименьшего отклонения от надира
+	OffNadir     float64   `json:"offNadir"`     // отклонение от надира на цель в BestTime, град
+	GroundRange  float64   `json:"groundRange"`  // расстояние по поверхности от подспутниковой точки до цели, км
+	SlantRange   float64   `json:"slantRange"`   // наклонная дальность до цели, км
+	SunElevation float64   `json:"sunElevation"` // высота Солнца в точке цели, град
+	Target       GeoPoint  `json:"target"`       // точка цели, ближайшая к надиру
+}
+
+// RevisitStats - статистика повторного доступа к цели за интервал
+type RevisitStats struct {
+	Accesses           int     `json:"accesses"`
+	TotalAccessSeconds float64 `json:"totalAccessSeconds"`
+	// промежутки между окончанием окна и началом следующего, ч. nil, если окон меньше двух
+	MinGapHours  *float64 `json:"minGapHours"`
+	MeanGapHours *float64 `json:"meanGapHours"`
+	MaxGapHours  *float64 `json:"maxGapHours"`
+}
+
+type imagingTarget struct {
+	points []GeoPoint
+	ecef   []vector
+	up     []vector
+	center vector  // единичный вектор на центр цели
+	radius float64 // угловой радиус цели, рад
+}
+
+type imagingSample struct {
+	offNadir     float64
+	groundRange  float64
+	slantRange   float64
+	sunElevation float64
+	point        GeoPoint
+}
+
+// ImagingAccesses возвращает окна, в которых цель попадает в поле зрения аппаратуры, от from до to.
+// Цель - точка (один элемент target) или многоугольник (вершины по порядку). Цель видна, если
+// хотя бы одна ее точка выше горизонта и отклонена от надира не больше чем на MaxOffNadir + HalfAngle.
+func (s Satellite) ImagingAccesses(from, to time.Time, target []GeoPoint, sensor Sensor) ([]ImagingAccess, error) {
+	if to.Before(from) {
+		return nil, errors.New("конец интервала раньше начала")
+	}
+
+	if len(target) == 0 || len(target) == 2 {
+		return nil, errors.New("цель должна быть точкой или многоугольником из трех и более вершин")
+	}
+
+	if sensor.HalfAngle < 0 || sensor.MaxOffNadir < 0 || sensor.HalfAngle+sensor.MaxOffNadir <= 0 || sensor.HalfAngle+sensor.MaxOffNadir >= 90 {
+		return nil, errors.New("сумма угла поля зрения и отклонения от надира должна быть от 0 до 90°")
+	}
+
+	e, err := ParseTLE(s.line1, s.line2)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := (sensor.HalfAngle + sensor.MaxOffNadir) * math.Pi / 180
+	altitude := e.SemiMajorAxis() - equatorialRadiusKm
+
+	// расстояние на поверхности, на которое дотягивается аппаратура, определяет шаг сетки цели и шаг поиска
+	reachKm := math.Max(altitude*math.Tan(limit), 1)
+
+	tgt := newImagingTarget(target, math.Min(reachKm, 50))
+
+	step := time.Duration(reachKm/groundSpeedKmS(e)*float64(time.Second)) / 2
+	step = step.Truncate(time.Second)
+	if step < time.Second {
+		step = time.Second
+	}
+	if step > maxImagingStep {
+		step = maxImagingStep
+	}
+
+	visible := func(t time.Time) (bool, error) {
+		_, ok, err := s.imagingSample(t, tgt, limit, sensor.MinSunElevation)
+		return ok, err
+	}
+
+	from = from.UTC().Truncate(time.Second)
+	to = to.UTC()
+
+	res := []ImagingAccess{}
+
+	var (
+		start  time.Time
+		inside bool
+		prev   time.Time
+	)
+
+	for t := from; ; t = t.Add(step) {
+		if t.After(to) {
+			t = to.Truncate(time.Second)
+		}
+
+		ok, err := visible(t)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case ok && !inside:
+			start = t
+			if t.After(from) {
+				start, err = bisectVisibility(prev, t, visible)
+				if err != nil {
+					return nil, err
+				}
+			}
+			inside = true
+		case !ok && inside:
+			end, err := bisectVisibility(prev, t, func(t time.Time) (bool, error) {
+				ok, err := visible(t)
+				return !ok, err
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			access, err := s.imagingAccess(start, end.Add(-time.Second), tgt, limit, sensor.MinSunElevation)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, access)
+			inside = false
+		}
+
+		prev = t
+		if !t.Before(to.Truncate(time.Second)) {
+			break
+		}
+	}
+
+	if inside {
+		access, err := s.imagingAccess(start, prev, tgt, limit, sensor.MinSunElevation)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, access)
+	}
+
+	return res, nil
+}
+
+// Revisit считает статистику повторного доступа по окнам съемки
+func Revisit(accesses []ImagingAccess) RevisitStats {
+	res := RevisitStats{
+		Accesses: len(accesses),
+	}
+
+	var sum float64
+
+	for i, a := range accesses {
+		res.TotalAccessSeconds += a.To.Sub(a.From).Seconds()
+
+		if i == 0 {
+			continue
+		}
+
+		gap := a.From.Sub(accesses[i-1].To).Hours()
+		sum += gap
+
+		if res.MinGapHours == nil || gap < *res.MinGapHours {
+			res.MinGapHours = &gap
+		}
+		if res.MaxGapHours == nil || gap > *res.MaxGapHours {
+			res.MaxGapHours = &gap
+		}
+	}
+
+	if len(accesses) > 1 {
+		mean := sum / float64(len(accesses)-1)
+		res.MeanGapHours = &mean
+	}
+
+	return res
+}
+
+// imagingAccess заполняет окно [from, to] параметрами в момент наименьшего отклонения от надира
+func (s Satellite) imagingAccess(from, to time.Time, tgt imagingTarget, limit float64, minSunEl *float64) (ImagingAccess, error) {
+	step := time.Second
+	if n := int64(to.Sub(from) / time.Second); n > maxImagingScanPoints {
+		step = time.Duration(n/maxImagingScanPoints+1) * time.Second
+	}
+
+	access := ImagingAccess{
+		TimeRange: TimeRange{
+			From:       from,
+			To:         to,
+			Difference: to.Sub(from).String(),
+		},
+		OffNadir: math.Inf(1),
+	}
+
+	for t := from; !t.After(to); t = t.Add(step) {
+		sample, ok, err := s.imagingSample(t, tgt, limit, minSunEl)
+		if err != nil {
+			return ImagingAccess{}, err
+		}
+
+		if ok && sample.offNadir < access.OffNadir {
+			access.BestTime = t
+			access.OffNadir = sample.offNadir
+			access.GroundRange = sample.groundRange
+			access.SlantRange = sample.slantRange
+			access.SunElevation = sample.sunElevation
+			access.Target = sample.point
+		}
+	}
+
+	// окно короче шага перебора
+	if math.IsInf(access.OffNadir, 1) {
+		access.BestTime = from
+		access.OffNadir = 0
+	}
+
+	return access, nil
+}
+
+// imagingSample находит точку цели с наименьшим отклонением от надира, доступную в момент t
+func (s Satellite) imagingSample(t time.Time, tgt imagingTarget, limit float64, minSunEl *float64) (imagingSample, bool, error) {
+	sv, err := s.StateVector(t)
+	if err != nil {
+		return imagingSample{}, false, err
+	}
+
+	x, y, z := sv.ECEF()
+	sat := vector{x, y, z}
+	r := sat.norm()
+
+	// быстрая отбраковка: цель дальше, чем дотягивается аппаратура
+	reach := math.Acos(math.Min(earthRadiusKm/r, 1))
+	if ratio := r * math.Sin(limit) / earthRadiusKm; ratio < 1 {
+		reach = math.Pi/2 - limit - math.Acos(ratio)
+	}
+
+	if angleBetween(sat, tgt.center) > reach+tgt.radius+math.Pi/180 {
+		return imagingSample{}, false, nil
+	}
+
+	var sun vector
+	if minSunEl != nil {
+		sun = sunECEF(t)
+	}
+
+	var (
+		best imagingSample
+		ok   bool
+	)
+
+	for i, p := range tgt.ecef {
+		los := sat.sub(p)
+		slant := los.norm()
+
+		if tgt.up[i].dot(los) < 0 {
+			continue
+		}
+
+		offNadir := angleBetween(sat.scale(-1), p.sub(sat))
+		if offNadir > limit {
+			continue
+		}
+
+		if minSunEl != nil && elevationFrom(p, tgt.up[i], sun) < *minSunEl {
+			continue
+		}
+
+		if !ok || offNadir < best.offNadir {
+			best = imagingSample{
+				offNadir:    offNadir,
+				groundRange: earthRadiusKm * angleBetween(sat, p),
+				slantRange:  slant,
+				point:       tgt.points[i],
+			}
+			ok = true
+		}
+	}
+
+	if !ok {
+		return imagingSample{}, false, nil
+	}
+
+	if minSunEl == nil {
+		sun = sunECEF(t)
+	}
+
+	p := geodeticToECEF(best.point.Lat, best.point.Lon, 0)
+	best.sunElevation = elevationFrom(p, geodeticUp(best.point.Lat, best.point.Lon), sun)
+	best.offNadir *= 180 / math.Pi
+
+	return best, true, nil
+}
+
+// newImagingTarget представляет цель набором точек: точка - сама собой, многоугольник - вершинами,
+// точками на сторонах и сеткой внутри с шагом не больше spacingKm
+func newImagingTarget(target []GeoPoint, spacingKm float64) imagingTarget {
+	points := target
+	if len(target) > 2 {
+		for {
+			points = polygonSamples(target, spacingKm)
+			if len(points) <= maxImagingSamples {
+				break
+			}
+			spacingKm *= 1.5
+		}
+	}
+
+	tgt := imagingTarget{
+		points: points,
+	}
+
+	var sum vector
+	for _, p := range points {
+		v := geodeticToECEF(p.Lat, p.Lon, 0)
+		tgt.ecef = append(tgt.ecef, v)
+		tgt.up = append(tgt.up, geodeticUp(p.Lat, p.Lon))
+		sum = sum.add(v.unit())
+	}
+
+	tgt.center = sum.unit()
+	for _, v := range tgt.ecef {
+		tgt.radius = math.Max(tgt.radius, angleBetween(tgt.center, v))
+	}
+
+	return tgt
+}
+
+func polygonSamples(polygon []GeoPoint, spacingKm float64) []GeoPoint {
+	const kmPerDegree = 111.2
+
+	var res []GeoPoint
+
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	minLon, maxLon := math.Inf(1), math.Inf(-1)
+
+	for i, a := range polygon {
+		b := polygon[(i+1)%len(polygon)]
+
+		minLat, maxLat = math.Min(minLat, a.Lat), math.Max(maxLat, a.Lat)
+		minLon, maxLon = math.Min(minLon, a.Lon), math.Max(maxLon, a.Lon)
+
+		// стороны делятся в координатах широта/долгота - для целей размером до сотен км этого достаточно
+		length := math.Hypot(b.Lat-a.Lat, (b.Lon-a.Lon)*math.Cos((a.Lat+b.Lat)/2*math.Pi/180)) * kmPerDegree
+		n := int(math.Ceil(length / spacingKm))
+		if n < 1 {
+			n = 1
+		}
+
+		for k := 0; k < n; k++ {
+			f := float64(k) / float64(n)
+			res = append(res, GeoPoint{Lat: a.Lat + f*(b.Lat-a.Lat), Lon: a.Lon + f*(b.Lon-a.Lon)})
+		}
+	}
+
+	latStep := spacingKm / kmPerDegree
+	lonStep := spacingKm / (kmPerDegree * math.Max(math.Cos((minLat+maxLat)/2*math.Pi/180), 0.01))
+
+	for lat := minLat + latStep/2; lat < maxLat; lat += latStep {
+		for lon := minLon + lonStep/2; lon < maxLon; lon += lonStep {
+			p := GeoPoint{Lat: lat, Lon: lon}
+			if pointInPolygon(p, polygon) {
+				res = append(res, p)
+			}
+		}
+	}
+
+	return res
+}
+
+// pointInPolygon проверяет попадание точки в многоугольник (метод лучей в координатах широта/долгота)
+func pointInPolygon(p GeoPoint, polygon []GeoPoint) bool {
+	inside := false
+
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// bisectVisibility находит первую секунду на (lo, hi], в которую cond истинно (cond(hi) истинно)
+func bisectVisibility(lo, hi time.Time, cond func(time.Time) (bool, error)) (time.Time, error) {
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+
+		ok, err := cond(mid)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return hi, nil
+}
+
+// groundSpeedKmS возвращает скорость движения подспутниковой точки для круговой орбиты
+// без учета вращения Земли, км/с
+func groundSpeedKmS(e Elements) float64 {
+	return 2 * math.Pi * earthRadiusKm / e.Period().Seconds()
+}
+
+// sunECEF возвращает положение Солнца во вращающейся системе, км
+func sunECEF(t time.Time) vector {
+	x, y, z := SunPosition(t)
+
+	return temeToECEF(vector{x, y, z}, greenwichSiderealTime(t))
+}
+
+// elevationFrom возвращает угол места цели target из точки p с нормалью up, град
+func elevationFrom(p, up, target vector) float64 {
+	los := target.sub(p)
+
+	return math.Asin(up.dot(los)/los.norm()) * 180 / math.Pi
+}
@@ -0,0 +1,139 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// количество контрольных точек для оценки погрешности интерполяции
+const interpolationCheckPoints = 32
+
+// Ephemeris - таблица векторов состояния с постоянным шагом. Положение и скорость между узлами
+// восстанавливаются кубической интерполяцией Эрмита по положению и скорости в соседних узлах.
+type Ephemeris struct {
+	states []StateVector
+	step   time.Duration
+	// оценка погрешности интерполяции положения по контрольным точкам в серединах интервалов, км
+	MaxErrorKm float64
+}
+
+// Ephemeris рассчитывает таблицу векторов состояния от from до to с шагом step (четное число секунд,
+// чтобы середины интервалов, в которых проверяется погрешность, приходились на целые секунды)
+func (s Satellite) Ephemeris(from, to time.Time, step time.Duration) (Ephemeris, error) {
+	if step < 2*time.Second || step%(2*time.Second) != 0 {
+		return Ephemeris{}, errors.New("шаг должен быть четным числом секунд")
+	}
+
+	from = from.UTC().Truncate(time.Second)
+	if to.Sub(from) < step {
+		to = from.Add(step)
+	}
+
+	states, err := s.StateVectors(from, to, step)
+	if err != nil {
+		return Ephemeris{}, err
+	}
+
+	e := Ephemeris{
+		states: states,
+		step:   step,
+	}
+
+	intervals := len(states) - 1
+	checks := interpolationCheckPoints
+	if checks > intervals {
+		checks = intervals
+	}
+
+	for i := 0; i < checks; i++ {
+		k := i * intervals / checks
+		mid := states[k].Time.Add(step / 2)
+
+		exact, err := s.StateVector(mid)
+		if err != nil {
+			return Ephemeris{}, err
+		}
+
+		approx := e.interpolate(k, mid)
+
+		d := vector{approx.X, approx.Y, approx.Z}.sub(vector{exact.X, exact.Y, exact.Z}).norm()
+		e.MaxErrorKm = math.Max(e.MaxErrorKm, d)
+	}
+
+	return e, nil
+}
+
+// From возвращает начало таблицы
+func (e Ephemeris) From() time.Time {
+	if len(e.states) == 0 {
+		return time.Time{}
+	}
+
+	return e.states[0].Time
+}
+
+// To возвращает конец таблицы
+func (e Ephemeris) To() time.Time {
+	if len(e.states) == 0 {
+		return time.Time{}
+	}
+
+	return e.states[len(e.states)-1].Time
+}
+
+// Covers проверяет, что момент t внутри таблицы
+func (e Ephemeris) Covers(t time.Time) bool {
+	return len(e.states) > 1 && !t.Before(e.From()) && !t.After(e.To())
+}
+
+// StateVector возвращает интерполированный вектор состояния на момент t
+func (e Ephemeris) StateVector(t time.Time) (StateVector, error) {
+	if !e.Covers(t) {
+		return StateVector{}, fmt.Errorf("момент %s вне таблицы эфемерид", t.UTC().Format(time.RFC3339))
+	}
+
+	k := int(t.Sub(e.From()) / e.step)
+	if k >= len(e.states)-1 {
+		k = len(e.states) - 2
+	}
+
+	return e.interpolate(k, t.UTC()), nil
+}
+
+// interpolate восстанавливает вектор состояния на момент t внутри интервала [k, k+1]
+func (e Ephemeris) interpolate(k int, t time.Time) StateVector {
+	return hermite(e.states[k], e.states[k+1], t)
+}
+
+// hermite восстанавливает вектор состояния на момент t между узлами a и b (a раньше b)
+// кубической интерполяцией Эрмита по положению и скорости
+func hermite(a, b StateVector, t time.Time) StateVector {
+	h := b.Time.Sub(a.Time).Seconds()
+	tau := t.Sub(a.Time).Seconds() / h
+
+	tau2 := tau * tau
+	tau3 := tau2 * tau
+
+	// базисные функции Эрмита и их производные по tau
+	h00, h10, h01, h11 := 2*tau3-3*tau2+1, tau3-2*tau2+tau, -2*tau3+3*tau2, tau3-tau2
+	d00, d10, d01, d11 := 6*tau2-6*tau, 3*tau2-4*tau+1, -6*tau2+6*tau, 3*tau2-2*tau
+
+	pos := func(p0, v0, p1, v1 float64) float64 {
+		return h00*p0 + h10*h*v0 + h01*p1 + h11*h*v1
+	}
+	vel := func(p0, v0, p1, v1 float64) float64 {
+		return (d00*p0+d01*p1)/h + d10*v0 + d11*v1
+	}
+
+	return StateVector{
+		Time: t,
+		X:    pos(a.X, a.VX, b.X, b.VX),
+		Y:    pos(a.Y, a.VY, b.Y, b.VY),
+		Z:    pos(a.Z, a.VZ, b.Z, b.VZ),
+		VX:   vel(a.X, a.VX, b.X, b.VX),
+		VY:   vel(a.Y, a.VY, b.Y, b.VY),
+		VZ:   vel(a.Z, a.VZ, b.Z, b.VZ),
+	}
+}
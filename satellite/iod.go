@@ -0,0 +1,218 @@
+package satellite
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// разрыв между измерениями, после которого начинается новая дуга (другой пролет)
+	maxArcGap = 15 * time.Minute
+	// шаг поиска корня уравнения Гаусса для расстояния до спутника, км
+	gaussRootStep = 1.0
+	// наибольшее расстояние до спутника, которое ищется в методе Гаусса, км
+	gaussMaxRadius = 100000.0
+)
+
+// osculatingElements - оскулирующие элементы орбиты в параметрах, которые уточняются при подгонке TLE
+type osculatingElements struct {
+	meanMotion float64 // об/сут
+	// e·cos(ω) и e·sin(ω): в отличие от e и ω определены и для круговой орбиты
+	ex, ey      float64
+	inclination float64 // град
+	raan        float64 // град
+	argOfLat    float64 // средний аргумент широты ω + M, град
+}
+
+// osculating рассчитывает оскулирующие элементы по вектору состояния в TEME.
+// Для экваториальных орбит долгота узла не определена.
+func osculating(sv StateVector) osculatingElements {
+	r := vector{sv.X, sv.Y, sv.Z}
+	v := vector{sv.VX, sv.VY, sv.VZ}
+
+	rn := r.norm()
+	h := r.cross(v)
+
+	a := 1 / (2/rn - v.dot(v)/earthMu)
+	eVec := r.scale(v.dot(v) - earthMu/rn).sub(v.scale(r.dot(v))).scale(1 / earthMu)
+	e := eVec.norm()
+
+	raan := math.Atan2(h[0], -h[1])
+	node := vector{math.Cos(raan), math.Sin(raan), 0}
+	inPlane := h.unit().cross(node)
+
+	argOfPericenter := math.Atan2(eVec.dot(inPlane), eVec.dot(node))
+	argOfLat := math.Atan2(r.dot(inPlane), r.dot(node))
+
+	// истинная аномалия -> эксцентрическая -> средняя
+	trueAnomaly := argOfLat - argOfPericenter
+	eccAnomaly := 2 * math.Atan(math.Sqrt((1-e)/(1+e))*math.Tan(trueAnomaly/2))
+	meanAnomaly := eccAnomaly - e*math.Sin(eccAnomaly)
+
+	return osculatingElements{
+		meanMotion:  math.Sqrt(earthMu/(a*a*a)) * 86400 / (2 * math.Pi),
+		ex:          e * math.Cos(argOfPericenter),
+		ey:          e * math.Sin(argOfPericenter),
+		inclination: math.Acos(h[2]/h.norm()) * 180 / math.Pi,
+		raan:        normalizeDegrees(raan * 180 / math.Pi),
+		argOfLat:    normalizeDegrees((argOfPericenter + meanAnomaly) * 180 / math.Pi),
+	}
+}
+
+// gaussInitialOrbit определяет вектор состояния на момент второго измерения по трем направлениям
+// на спутник методом Гаусса (Curtis, Orbital Mechanics for Engineering Students, алгоритм 5.5).
+// Подходит для измерений на одном пролете, рефракция снимается приближенно.
+func gaussInitialOrbit(obs [3]AnglesObservation) (StateVector, error) {
+	var (
+		station [3]vector
+		los     [3]vector
+	)
+
+	for i, o := range obs {
+		gst := greenwichSiderealTime(o.Time)
+		el := o.El - o.Refraction.Correction(o.El)
+
+		station[i] = temeToECEF(geodeticToECEF(o.Observer.Lat, o.Observer.Lon, o.Observer.Alt), -gst)
+		los[i] = temeToECEF(losECEF(o.Observer, o.Az, el), -gst)
+	}
+
+	tau1 := obs[0].Time.Sub(obs[1].Time).Seconds()
+	tau3 := obs[2].Time.Sub(obs[1].Time).Seconds()
+	tau := tau3 - tau1
+
+	p1 := los[1].cross(los[2])
+	p2 := los[0].cross(los[2])
+	p3 := los[0].cross(los[1])
+
+	d0 := los[0].dot(p1)
+	if math.Abs(d0) < 1e-12 {
+		return StateVector{}, errors.New("направления на спутник лежат в одной плоскости, метод Гаусса неприменим")
+	}
+
+	var d [3][3]float64
+	for i := range station {
+		d[i] = [3]float64{station[i].dot(p1), station[i].dot(p2), station[i].dot(p3)}
+	}
+
+	A := (-d[0][1]*tau3/tau + d[1][1] + d[2][1]*tau1/tau) / d0
+	B := (d[0][1]*(tau3*tau3-tau*tau)*tau3/tau + d[2][1]*(tau*tau-tau1*tau1)*tau1/tau) / (6 * d0)
+	E := station[1].dot(los[1])
+	R2 := station[1].dot(station[1])
+
+	a := -(A*A + 2*A*E + R2)
+	b := -2 * earthMu * B * (A + E)
+	c := -earthMu * earthMu * B * B
+
+	// уравнение 8-й степени для расстояния до спутника на момент второго измерения
+	f := func(x float64) float64 {
+		x2 := x * x
+		x3 := x2 * x
+		x6 := x3 * x3
+
+		return x6*x2 + a*x6 + b*x3 + c
+	}
+
+	for lo := equatorialRadiusKm; lo < gaussMaxRadius; lo += gaussRootStep {
+		hi := lo + gaussRootStep
+		if f(lo)*f(hi) > 0 {
+			continue
+		}
+
+		// уточняем корень делением пополам
+		for i := 0; i < 60; i++ {
+			mid := (lo + hi) / 2
+			if f(lo)*f(mid) <= 0 {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+
+		r2 := (lo + hi) / 2
+		r23 := r2 * r2 * r2
+		mu := earthMu
+
+		rho1 := ((6*(d[2][0]*tau1/tau3+d[1][0]*tau/tau3)*r23+mu*d[2][0]*(tau*tau-tau1*tau1)*tau1/tau3)/
+			(6*r23+mu*(tau*tau-tau3*tau3)) - d[0][0]) / d0
+		rho2 := A + mu*B/r23
+		rho3 := ((6*(d[0][2]*tau3/tau1-d[1][2]*tau/tau1)*r23+mu*d[0][2]*(tau*tau-tau3*tau3)*tau3/tau1)/
+			(6*r23+mu*(tau*tau-tau1*tau1)) - d[2][2]) / d0
+
+		if rho1 <= 0 || rho2 <= 0 || rho3 <= 0 {
+			lo = hi
+			continue
+		}
+
+		r1 := station[0].add(los[0].scale(rho1))
+		r := station[1].add(los[1].scale(rho2))
+		r3 := station[2].add(los[2].scale(rho3))
+
+		// коэффициенты Лагранжа в виде рядов по времени
+		f1 := 1 - mu*tau1*tau1/(2*r23)
+		f3 := 1 - mu*tau3*tau3/(2*r23)
+		g1 := tau1 - mu*tau1*tau1*tau1/(6*r23)
+		g3 := tau3 - mu*tau3*tau3*tau3/(6*r23)
+
+		v := r1.scale(-f3).add(r3.scale(f1)).scale(1 / (f1*g3 - f3*g1))
+
+		return StateVector{
+			Time: obs[1].Time.UTC(),
+			X:    r[0],
+			Y:    r[1],
+			Z:    r[2],
+			VX:   v[0],
+			VY:   v[1],
+			VZ:   v[2],
+		}, nil
+	}
+
+	return StateVector{}, errors.New("метод Гаусса не нашел орбиту по измерениям")
+}
+
+// losECEF возвращает единичный вектор направления от наблюдателя по азимуту и углу места (град) в ECEF
+func losECEF(obs ObserverCoords, az, el float64) vector {
+	sinAz, cosAz := math.Sincos(az * math.Pi / 180)
+	sinEl, cosEl := math.Sincos(el * math.Pi / 180)
+
+	up := geodeticUp(obs.Lat, obs.Lon)
+
+	sinLon, cosLon := math.Sincos(obs.Lon * math.Pi / 180)
+	east := vector{-sinLon, cosLon, 0}
+	north := up.cross(east)
+
+	return east.scale(cosEl * sinAz).add(north.scale(cosEl * cosAz)).add(up.scale(sinEl))
+}
+
+// gaussTriplet выбирает три измерения для метода Гаусса: первое, среднее и последнее на самой длинной
+// дуге - измерениях одной станции без перерывов дольше maxArcGap
+func gaussTriplet(angles []AnglesObservation) ([3]AnglesObservation, error) {
+	sorted := append([]AnglesObservation(nil), angles...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var (
+		best     []AnglesObservation
+		bestSpan time.Duration
+	)
+
+	start := 0
+	for i := 1; i <= len(sorted); i++ {
+		if i < len(sorted) && sorted[i].Observer == sorted[i-1].Observer && sorted[i].Time.Sub(sorted[i-1].Time) <= maxArcGap {
+			continue
+		}
+
+		arc := sorted[start:i]
+		if span := arc[len(arc)-1].Time.Sub(arc[0].Time); len(arc) >= 3 && span > bestSpan {
+			best, bestSpan = arc, span
+		}
+
+		start = i
+	}
+
+	if best == nil {
+		return [3]AnglesObservation{}, errors.New("нужно хотя бы три измерения одной станции на одном пролете")
+	}
+
+	return [3]AnglesObservation{best[0], best[len(best)/2], best[len(best)-1]}, nil
+}
@@ -0,0 +1,195 @@
+package satellite
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// постоянная Больцмана, дБВт/(К·Гц)
+	boltzmannDB = -228.6
+	// DefaultLinkBudgetStep - шаг расчета энергетики по умолчанию
+	DefaultLinkBudgetStep = 10 * time.Second
+	// высота однородной атмосферы для пересчета поглощения на высоту станции, км
+	atmosphereScaleHeightKm = 6.0
+)
+
+// Link - параметры радиолинии
+type Link struct {
+	FrequencyHz      float64 `json:"frequencyHz"`      // несущая частота, Гц
+	TxPowerW         float64 `json:"txPowerW"`         // мощность передатчика, Вт
+	TxGainDBi        float64 `json:"txGainDbi"`        // усиление передающей антенны, дБи
+	RxGainDBi        float64 `json:"rxGainDbi"`        // усиление приемной антенны, дБи
+	SystemNoiseTempK float64 `json:"systemNoiseTempK"` // шумовая температура приемной системы, К
+	BandwidthHz      float64 `json:"bandwidthHz"`      // шумовая полоса приемника, Гц
+	DataRateBps      float64 `json:"dataRateBps"`      // скорость передачи, бит/с. Если задана, считается Eb/N0
+	LossesDB         float64 `json:"lossesDb"`         // прочие потери (фидер, поляризация, наведение), дБ
+	// требуемое Eb/N0 (если задана скорость) или ОСШ, дБ
+	RequiredDB float64 `json:"requiredDb"`
+	// запас, при котором линия считается закрытой, дБ
+	RequiredMarginDB float64 `json:"requiredMarginDb"`
+}
+
+// Valid проверяет параметры радиолинии
+func (l Link) Valid() error {
+	switch {
+	case l.FrequencyHz <= 0:
+		return errors.New("частота должна быть больше нуля")
+	case l.TxPowerW <= 0:
+		return errors.New("мощность передатчика должна быть больше нуля")
+	case l.SystemNoiseTempK <= 0:
+		return errors.New("шумовая температура должна быть больше нуля")
+	case l.BandwidthHz <= 0:
+		return errors.New("полоса должна быть больше нуля")
+	case l.DataRateBps < 0:
+		return errors.New("скорость передачи не может быть отрицательной")
+	}
+
+	return nil
+}
+
+// LinkPoint - энергетика радиолинии в один момент
+type LinkPoint struct {
+	Time       time.Time `json:"time"`
+	El         float64   `json:"el"`         // угол места, град
+	Range      float64   `json:"range"`      // дальность, км
+	FSPL       float64   `json:"fspl"`       // потери в свободном пространстве, дБ
+	AtmLoss    float64   `json:"atmLoss"`    // поглощение в атмосфере, дБ
+	RxPowerDBW float64   `json:"rxPowerDbw"` // мощность на входе приемника, дБВт
+	SNR        float64   `json:"snr"`        // отношение сигнал/шум в полосе, дБ
+	EbN0       *float64  `json:"ebN0"`       // Eb/N0, дБ (если задана скорость)
+	Margin     float64   `json:"margin"`     // запас относительно требуемого значения, дБ
+	Closes     bool      `json:"closes"`     // запас не меньше требуемого
+}
+
+// LinkPass - энергетика радиолинии на одном пролете
+type LinkPass struct {
+	Pass          TimeRange   `json:"pass"`
+	Points        []LinkPoint `json:"points"`
+	MaxMargin     float64     `json:"maxMargin"`     // дБ
+	ClosedSeconds float64     `json:"closedSeconds"` // сколько секунд линия закрыта
+	// первый и последний момент, когда линия закрыта
+	ClosedFrom *time.Time `json:"closedFrom"`
+	ClosedTo   *time.Time `json:"closedTo"`
+}
+
+// FreeSpacePathLoss возвращает потери в свободном пространстве на дальности rangeKm для частоты freqHz, дБ
+func FreeSpacePathLoss(rangeKm, freqHz float64) float64 {
+	return 20 * math.Log10(4*math.Pi*rangeKm*freqHz/speedOfLight)
+}
+
+// поглощение в газах атмосферы в зените на уровне моря для стандартной атмосферы
+// (по графикам ITU-R P.676), частота в ГГц и дБ
+var zenithAttenuation = [][2]float64{
+	{0.1, 0.030},
+	{1, 0.035},
+	{2, 0.037},
+	{4, 0.040},
+	{8, 0.050},
+	{10, 0.055},
+	{15, 0.080},
+	{20, 0.250},
+	{22.2, 0.500},
+	{25, 0.300},
+	{30, 0.250},
+	{40, 0.400},
+	{50, 1.500},
+}
+
+// AtmosphericLoss возвращает поглощение в газах атмосферы на частоте freqHz при угле места el (град)
+// для станции на высоте altKm, дБ. Упрощенная модель: поглощение в зените по ITU-R P.676,
+// умноженное на воздушную массу (формула Kasten-Young).
+func AtmosphericLoss(freqHz, el, altKm float64) float64 {
+	f := freqHz / 1e9
+
+	// линейная интерполяция по логарифму частоты, за пределами таблицы - крайние значения
+	zenith := zenithAttenuation[len(zenithAttenuation)-1][1]
+	i := sort.Search(len(zenithAttenuation), func(i int) bool { return zenithAttenuation[i][0] >= f })
+	switch {
+	case i == 0:
+		zenith = zenithAttenuation[0][1]
+	case i < len(zenithAttenuation):
+		lo, hi := zenithAttenuation[i-1], zenithAttenuation[i]
+		k := math.Log(f/lo[0]) / math.Log(hi[0]/lo[0])
+		zenith = lo[1] + k*(hi[1]-lo[1])
+	}
+
+	zenith *= math.Exp(-math.Max(altKm, 0) / atmosphereScaleHeightKm)
+
+	el = math.Max(el, 0)
+	airMass := 1 / (math.Sin(el*math.Pi/180) + 0.50572*math.Pow(el+6.07995, -1.6364))
+
+	return zenith * airMass
+}
+
+// Evaluate рассчитывает энергетику радиолинии для положения спутника angles и станции на высоте altKm
+func (l Link) Evaluate(t time.Time, angles LookAngles, altKm float64) LinkPoint {
+	p := LinkPoint{
+		Time:    t,
+		El:      angles.El,
+		Range:   angles.Range,
+		FSPL:    FreeSpacePathLoss(angles.Range, l.FrequencyHz),
+		AtmLoss: AtmosphericLoss(l.FrequencyHz, angles.El, altKm),
+	}
+
+	p.RxPowerDBW = 10*math.Log10(l.TxPowerW) + l.TxGainDBi + l.RxGainDBi - p.FSPL - p.AtmLoss - l.LossesDB
+
+	// C/N0, дБГц
+	cn0 := p.RxPowerDBW - boltzmannDB - 10*math.Log10(l.SystemNoiseTempK)
+
+	p.SNR = cn0 - 10*math.Log10(l.BandwidthHz)
+	p.Margin = p.SNR - l.RequiredDB
+
+	if l.DataRateBps > 0 {
+		ebN0 := cn0 - 10*math.Log10(l.DataRateBps)
+		p.EbN0 = &ebN0
+		p.Margin = ebN0 - l.RequiredDB
+	}
+
+	p.Closes = angles.El >= 0 && p.Margin >= l.RequiredMarginDB
+
+	return p
+}
+
+// LinkBudget рассчитывает энергетику радиолинии на пролете tr с шагом step
+func (s Satellite) LinkBudget(tr TimeRange, obsCoords ObserverCoords, link Link, step time.Duration) LinkPass {
+	if step <= 0 {
+		step = DefaultLinkBudgetStep
+	}
+
+	res := LinkPass{
+		Pass:      tr,
+		MaxMargin: math.Inf(-1),
+	}
+
+	var times []time.Time
+	for t := tr.From; t.Before(tr.To); t = t.Add(step) {
+		times = append(times, t)
+	}
+	times = append(times, tr.To)
+
+	for i, t := range times {
+		p := link.Evaluate(t, s.LookAngles(t, obsCoords), obsCoords.Alt)
+		res.Points = append(res.Points, p)
+		res.MaxMargin = math.Max(res.MaxMargin, p.Margin)
+
+		if !p.Closes {
+			continue
+		}
+
+		closed := t
+		if res.ClosedFrom == nil {
+			res.ClosedFrom = &closed
+		}
+		res.ClosedTo = &closed
+
+		// длительность считается по интервалу до следующей точки
+		if i+1 < len(times) {
+			res.ClosedSeconds += times[i+1].Sub(t).Seconds()
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,60 @@
+package satellite
+
+import (
+	"math"
+	"time"
+)
+
+// средний радиус Луны, км
+const moonRadiusKm = 1737.4
+
+// MoonPosition возвращает положение Луны в инерциальной системе (экватор и равноденствие даты), км.
+// Ряд Montenbruck, Pfleger (Astronomy on the Personal Computer) по направлению и основные члены
+// теории Meeus по расстоянию: точность около 1-2′ по направлению и 0.01% по расстоянию.
+func MoonPosition(t time.Time) (float64, float64, float64) {
+	// юлианские столетия от J2000
+	T := (julianDate(t) - 2451545.0) / 36525
+
+	const arcsec = 180 * 3600 / math.Pi
+
+	frac := func(x float64) float64 { return x - math.Floor(x) }
+
+	// средняя долгота Луны, об
+	meanLon := frac(0.606433 + 1336.855225*T)
+	// средние аномалии Луны и Солнца, средняя элонгация Луны и аргумент широты, рад
+	l := 2 * math.Pi * frac(0.374897+1325.552410*T)
+	ls := 2 * math.Pi * frac(0.993133+99.997361*T)
+	D := 2 * math.Pi * frac(0.827361+1236.853086*T)
+	F := 2 * math.Pi * frac(0.259086+1342.227825*T)
+
+	// возмущения по долготе, угл. с
+	dL := 22640*math.Sin(l) - 4586*math.Sin(l-2*D) + 2370*math.Sin(2*D) + 769*math.Sin(2*l) -
+		668*math.Sin(ls) - 412*math.Sin(2*F) - 212*math.Sin(2*l-2*D) - 206*math.Sin(l+ls-2*D) +
+		192*math.Sin(l+2*D) - 165*math.Sin(ls-2*D) - 125*math.Sin(D) - 110*math.Sin(l+ls) +
+		148*math.Sin(l-ls) - 55*math.Sin(2*F-2*D)
+
+	S := F + (dL+412*math.Sin(2*F)+541*math.Sin(ls))/arcsec
+	h := F - 2*D
+	N := -526*math.Sin(h) + 44*math.Sin(l+h) - 31*math.Sin(-l+h) - 23*math.Sin(ls+h) +
+		11*math.Sin(-ls+h) - 25*math.Sin(-2*l+F) + 21*math.Sin(-l+F)
+
+	eclipticLon := 2 * math.Pi * frac(meanLon+dL/1296e3)
+	eclipticLat := (18520*math.Sin(S) + N) / arcsec
+
+	r := 385000.56 - 20905.355*math.Cos(l) - 3699.111*math.Cos(2*D-l) - 2955.968*math.Cos(2*D) -
+		569.925*math.Cos(2*l) + 48.888*math.Cos(ls) - 3.149*math.Cos(2*F) + 246.158*math.Cos(2*D-2*l) -
+		152.138*math.Cos(2*D-ls-l) - 170.733*math.Cos(2*D+l) - 204.586*math.Cos(2*D-ls) -
+		129.620*math.Cos(ls-l) + 108.743*math.Cos(D) + 104.755*math.Cos(ls+l)
+
+	obliquity := (23.439291 - 0.0130042*T) * math.Pi / 180
+
+	sinLon, cosLon := math.Sincos(eclipticLon)
+	sinLat, cosLat := math.Sincos(eclipticLat)
+	sinEps, cosEps := math.Sincos(obliquity)
+
+	x := cosLat * cosLon
+	y := cosEps*cosLat*sinLon - sinEps*sinLat
+	z := sinEps*cosLat*sinLon + cosEps*sinLat
+
+	return r * x, r * y, r * z
+}
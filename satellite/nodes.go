@@ -0,0 +1,126 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// шаг поиска пересечений экватора
+	nodeSearchStep = time.Minute
+)
+
+// NodeCrossing - пересечение спутником плоскости экватора
+type NodeCrossing struct {
+	Time      time.Time `json:"time"`
+	Node      string    `json:"node"`      // ascending - восходящий узел, descending - нисходящий
+	Lon       float64   `json:"lon"`       // долгота пересечения экватора, град
+	LocalTime float64   `json:"localTime"` // местное среднее солнечное время в узле, ч
+}
+
+// NodeCrossings возвращает пересечения экватора от from до to.
+// Момент пересечения уточняется до секунды и интерполируется внутри нее.
+func (s Satellite) NodeCrossings(from, to time.Time) ([]NodeCrossing, error) {
+	if to.Before(from) {
+		return nil, errors.New("конец интервала раньше начала")
+	}
+
+	from = from.UTC().Truncate(time.Second)
+
+	prev, err := s.StateVector(from)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []NodeCrossing{}
+
+	for t := from.Add(nodeSearchStep); !prev.Time.After(to); t = t.Add(nodeSearchStep) {
+		cur, err := s.StateVector(t)
+		if err != nil {
+			return nil, err
+		}
+
+		if (prev.Z < 0) != (cur.Z < 0) {
+			crossing, err := s.refineNodeCrossing(prev, cur)
+			if err != nil {
+				return nil, err
+			}
+
+			if !crossing.Time.Before(from) && !crossing.Time.After(to) {
+				res = append(res, crossing)
+			}
+		}
+
+		prev = cur
+	}
+
+	return res, nil
+}
+
+// refineNodeCrossing сужает интервал со сменой знака z до секунды бисекцией,
+// затем линейно интерполирует положение внутри секунды
+func (s Satellite) refineNodeCrossing(lo, hi StateVector) (NodeCrossing, error) {
+	ascending := lo.Z < 0
+
+	for hi.Time.Sub(lo.Time) > time.Second {
+		mid, err := s.StateVector(lo.Time.Add(hi.Time.Sub(lo.Time) / 2))
+		if err != nil {
+			return NodeCrossing{}, err
+		}
+
+		if (mid.Z < 0) == (lo.Z < 0) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	frac := 0.0
+	if lo.Z != hi.Z {
+		frac = lo.Z / (lo.Z - hi.Z)
+	}
+
+	t := lo.Time.Add(time.Duration(frac * float64(hi.Time.Sub(lo.Time))))
+	x := lo.X + frac*(hi.X-lo.X)
+	y := lo.Y + frac*(hi.Y-lo.Y)
+
+	// долгота - прямое восхождение точки минус звездное время
+	lon := math.Atan2(y, x) - greenwichSiderealTime(t)
+	lon = normalizeDegrees(lon*180/math.Pi+180) - 180
+
+	node := "descending"
+	if ascending {
+		node = "ascending"
+	}
+
+	return NodeCrossing{
+		Time:      t,
+		Node:      node,
+		Lon:       lon,
+		LocalTime: meanLocalTime(t, lon),
+	}, nil
+}
+
+// LTAN возвращает местное среднее солнечное время восходящего узла на эпоху элементов, ч
+func (e Elements) LTAN() float64 {
+	lon := normalizeDegrees(e.RAAN - greenwichSiderealTime(e.Epoch)*180/math.Pi)
+
+	return meanLocalTime(e.Epoch, lon)
+}
+
+// meanLocalTime возвращает местное среднее солнечное время на долготе lon (град), ч [0, 24)
+func meanLocalTime(t time.Time, lon float64) float64 {
+	t = t.UTC()
+	ut := float64(t.Sub(t.Truncate(24*time.Hour))) / float64(time.Hour)
+
+	return math.Mod(math.Mod(ut+lon/15, 24)+24, 24)
+}
+
+// FormatLocalTime переводит время в часах в строку ЧЧ:ММ:СС
+func FormatLocalTime(hours float64) string {
+	sec := int(math.Round(hours*3600)) % 86400
+
+	return fmt.Sprintf("%02d:%02d:%02d", sec/3600, sec/60%60, sec%60)
+}
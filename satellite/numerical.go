@@ -0,0 +1,286 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// шаг интегрирования (и расстояние между узлами, между которыми интерполируется результат)
+	numericalStep = 10 * time.Second
+	// на сколько от начального вектора можно уйти: дальше узлы занимают слишком много памяти,
+	// а без учета прецессии система TEME перестает быть инерциальной
+	maxNumericalSpan = 30 * 24 * time.Hour
+	// ниже этой высоты спутник считается сгоревшим в атмосфере, км
+	minNumericalAltitude = 100.0
+	// гравитационные параметры Солнца и Луны, км³/с²
+	sunMu  = 1.32712440018e11
+	moonMu = 4902.800066
+	// плотность атмосферы, к которой приведен B* в TLE, кг/м²/радиус Земли
+	bstarReferenceDensity = 0.157
+)
+
+// зональные гармоники J2–J6 (EGM-96, ненормированные)
+var zonalHarmonics = []float64{0, 0, earthJ2, -2.53215306e-6, -1.61098761e-6, -2.27296082e-7, 5.40681239e-7}
+
+// статическая экспоненциальная модель плотности атмосферы (Vallado, табл. 8-4):
+// базовая высота (км), плотность на ней (кг/м³) и высота однородной атмосферы (км)
+var atmosphereDensity = [][3]float64{
+	{0, 1.225, 7.249},
+	{25, 3.899e-2, 6.349},
+	{30, 1.774e-2, 6.682},
+	{40, 3.972e-3, 7.554},
+	{50, 1.057e-3, 8.382},
+	{60, 3.206e-4, 7.714},
+	{70, 8.770e-5, 6.549},
+	{80, 1.905e-5, 5.799},
+	{90, 3.396e-6, 5.382},
+	{100, 5.297e-7, 5.877},
+	{110, 9.661e-8, 7.263},
+	{120, 2.438e-8, 9.473},
+	{130, 8.484e-9, 12.636},
+	{140, 3.845e-9, 16.149},
+	{150, 2.070e-9, 22.523},
+	{180, 5.464e-10, 29.740},
+	{200, 2.789e-10, 37.105},
+	{250, 7.248e-11, 45.546},
+	{300, 2.418e-11, 53.628},
+	{350, 9.518e-12, 53.298},
+	{400, 3.725e-12, 58.515},
+	{450, 1.585e-12, 60.828},
+	{500, 6.967e-13, 63.822},
+	{600, 1.454e-13, 71.835},
+	{700, 3.614e-14, 88.667},
+	{800, 1.170e-14, 124.64},
+	{900, 5.245e-15, 181.05},
+	{1000, 3.019e-15, 268.00},
+}
+
+// AtmosphereDensity возвращает плотность атмосферы на высоте altKm по статической экспоненциальной модели, кг/м³
+func AtmosphereDensity(altKm float64) float64 {
+	if altKm < 0 {
+		altKm = 0
+	}
+
+	i := len(atmosphereDensity) - 1
+	for i > 0 && atmosphereDensity[i][0] > altKm {
+		i--
+	}
+
+	base := atmosphereDensity[i]
+
+	return base[1] * math.Exp(-(altKm-base[0])/base[2])
+}
+
+// NumericalPropagator численно интегрирует уравнения движения от начального вектора состояния.
+// Учитываются зональные гармоники J2–J6, сопротивление атмосферы (статическая экспоненциальная модель)
+// и притяжение Солнца и Луны. Интегрирование - методом Рунге-Кутты 4-го порядка с постоянным шагом,
+// узлы сохраняются по мере надобности, а между ними вектор состояния восстанавливается интерполяцией
+// Эрмита. Система TEME считается инерциальной (прецессия и нутация за время расчета не учитываются).
+type NumericalPropagator struct {
+	// баллистический коэффициент Cd·A/m, м²/кг
+	ballistic float64
+
+	mu sync.Mutex
+	// узлы от начального вектора вперед и назад по времени, forward[0] и backward[0] - начальный вектор
+	forward  []StateVector
+	backward []StateVector
+}
+
+// NewNumericalPropagator создает пропагатор с начальным вектором initial (TEME) и баллистическим
+// коэффициентом ballistic (Cd·A/m, м²/кг; 0 - без сопротивления атмосферы)
+func NewNumericalPropagator(initial StateVector, ballistic float64) *NumericalPropagator {
+	initial.Time = initial.Time.UTC()
+
+	return &NumericalPropagator{
+		ballistic: ballistic,
+		forward:   []StateVector{initial},
+		backward:  []StateVector{initial},
+	}
+}
+
+// NumericalPropagator создает численный пропагатор, начальный вектор которого рассчитан по SGP4
+// на эпоху TLE, а баллистический коэффициент - по B*
+func (s Satellite) NumericalPropagator() (*NumericalPropagator, error) {
+	elements, err := ParseTLE(s.line1, s.line2)
+	if err != nil {
+		return nil, fmt.Errorf("ParseTLE: %w", err)
+	}
+
+	initial, err := s.sgp4().StateVector(elements.Epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	// B* = ρ0·B/2, где B = Cd·A/m; отрицательный B* (что бывает в TLE) не дает сопротивления
+	ballistic := math.Max(2*elements.BStar/bstarReferenceDensity, 0)
+
+	return NewNumericalPropagator(initial, ballistic), nil
+}
+
+// StateVector возвращает вектор состояния на момент t, при необходимости продолжая интегрирование
+func (p *NumericalPropagator) StateVector(t time.Time) (StateVector, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t = t.UTC()
+	epoch := p.forward[0].Time
+
+	dt := t.Sub(epoch)
+	if dt > maxNumericalSpan || dt < -maxNumericalSpan {
+		return StateVector{}, fmt.Errorf("момент %s дальше %d суток от начального вектора", t.Format(time.RFC3339), maxNumericalSpan/(24*time.Hour))
+	}
+
+	if dt >= 0 {
+		k := int(dt / numericalStep)
+
+		nodes, err := p.extend(p.forward, k+1, numericalStep)
+		p.forward = nodes
+		if err != nil {
+			return StateVector{}, err
+		}
+
+		return hermite(nodes[k], nodes[k+1], t), nil
+	}
+
+	k := int(-dt / numericalStep)
+
+	nodes, err := p.extend(p.backward, k+1, -numericalStep)
+	p.backward = nodes
+	if err != nil {
+		return StateVector{}, err
+	}
+
+	return hermite(nodes[k+1], nodes[k], t), nil
+}
+
+// extend дописывает в nodes узлы с шагом step, пока их не станет больше n
+func (p *NumericalPropagator) extend(nodes []StateVector, n int, step time.Duration) ([]StateVector, error) {
+	for len(nodes) <= n {
+		last := nodes[len(nodes)-1]
+
+		next := p.rk4(last, step)
+
+		alt := approxAltitude(vector{next.X, next.Y, next.Z})
+		if alt < minNumericalAltitude || math.IsNaN(alt) {
+			return nodes, errors.New("спутник вошел в плотные слои атмосферы")
+		}
+
+		nodes = append(nodes, next)
+	}
+
+	return nodes, nil
+}
+
+// rk4 делает один шаг интегрирования методом Рунге-Кутты 4-го порядка
+func (p *NumericalPropagator) rk4(sv StateVector, step time.Duration) StateVector {
+	h := step.Seconds()
+
+	r := vector{sv.X, sv.Y, sv.Z}
+	v := vector{sv.VX, sv.VY, sv.VZ}
+
+	t0 := sv.Time
+	tMid := t0.Add(step / 2)
+	t1 := t0.Add(step)
+
+	k1r, k1v := v, p.acceleration(t0, r, v)
+	k2r, k2v := v.add(k1v.scale(h/2)), p.acceleration(tMid, r.add(k1r.scale(h/2)), v.add(k1v.scale(h/2)))
+	k3r, k3v := v.add(k2v.scale(h/2)), p.acceleration(tMid, r.add(k2r.scale(h/2)), v.add(k2v.scale(h/2)))
+	k4r, k4v := v.add(k3v.scale(h)), p.acceleration(t1, r.add(k3r.scale(h)), v.add(k3v.scale(h)))
+
+	r = r.add(k1r.add(k2r.scale(2)).add(k3r.scale(2)).add(k4r).scale(h / 6))
+	v = v.add(k1v.add(k2v.scale(2)).add(k3v.scale(2)).add(k4v).scale(h / 6))
+
+	return StateVector{
+		Time: t1,
+		X:    r[0],
+		Y:    r[1],
+		Z:    r[2],
+		VX:   v[0],
+		VY:   v[1],
+		VZ:   v[2],
+	}
+}
+
+// acceleration возвращает ускорение спутника в положении r (км) со скоростью v (км/с) на момент t, км/с²
+func (p *NumericalPropagator) acceleration(t time.Time, r, v vector) vector {
+	rn := r.norm()
+
+	a := r.scale(-earthMu / (rn * rn * rn))
+	a = a.add(zonalAcceleration(r))
+
+	if p.ballistic > 0 {
+		a = a.add(dragAcceleration(r, v, p.ballistic))
+	}
+
+	sx, sy, sz := SunPosition(t)
+	a = a.add(thirdBodyAcceleration(r, vector{sx, sy, sz}, sunMu))
+
+	mx, my, mz := MoonPosition(t)
+	a = a.add(thirdBodyAcceleration(r, vector{mx, my, mz}, moonMu))
+
+	return a
+}
+
+// zonalAcceleration возвращает возмущающее ускорение от зональных гармоник J2–J6, км/с².
+// Потенциал гармоники n: -μ·Jn·Rⁿ/rⁿ⁺¹·Pn(s), где s = z/r - синус геоцентрической широты.
+func zonalAcceleration(r vector) vector {
+	rn := r.norm()
+	s := r[2] / rn
+	radial := r.scale(1 / rn)
+
+	// полиномы Лежандра и их производные, рекуррентно
+	pPrev, pCur := 1.0, s
+	dPrev, dCur := 0.0, 1.0
+
+	var a vector
+	ratio := equatorialRadiusKm / rn
+
+	for n := 1; n < len(zonalHarmonics)-1; n++ {
+		pNext := (float64(2*n+1)*s*pCur - float64(n)*pPrev) / float64(n+1)
+		dNext := dPrev + float64(2*n+1)*pCur
+
+		pPrev, pCur = pCur, pNext
+		dPrev, dCur = dCur, dNext
+
+		deg := n + 1
+		k := earthMu * zonalHarmonics[deg] * math.Pow(ratio, float64(deg)) / (rn * rn)
+
+		a = a.add(radial.scale(k * (float64(deg+1)*pCur + s*dCur)))
+		a[2] -= k * dCur
+	}
+
+	return a
+}
+
+// dragAcceleration возвращает ускорение от сопротивления атмосферы, км/с². Атмосфера вращается вместе с Землей.
+func dragAcceleration(r, v vector, ballistic float64) vector {
+	rho := AtmosphereDensity(approxAltitude(r))
+
+	// скорость относительно атмосферы
+	rel := v.sub(vector{-earthRotationRate * r[1], earthRotationRate * r[0], 0})
+
+	// ρ (кг/м³) · B (м²/кг) дает 1/м, переводим в 1/км
+	return rel.scale(-0.5 * rho * ballistic * 1000 * rel.norm())
+}
+
+// thirdBodyAcceleration возвращает возмущающее ускорение от тела с гравитационным параметром mu в положении body, км/с²
+func thirdBodyAcceleration(r, body vector, mu float64) vector {
+	d := body.sub(r)
+	dn := d.norm()
+	bn := body.norm()
+
+	return d.scale(mu / (dn * dn * dn)).sub(body.scale(mu / (bn * bn * bn)))
+}
+
+// approxAltitude возвращает высоту над эллипсоидом для положения r (км), приближенно: радиус эллипсоида
+// берется по геоцентрической широте
+func approxAltitude(r vector) float64 {
+	rn := r.norm()
+	s := r[2] / rn
+
+	return rn - equatorialRadiusKm*(1-wgs84Flattening*s*s)
+}
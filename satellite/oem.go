@@ -0,0 +1,142 @@
+package satellite
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// формат времени в сообщениях CCSDS (UTC, без суффикса Z)
+const ccsdsTimeLayout = "2006-01-02T15:04:05.000"
+
+// OEM - CCSDS Orbit Ephemeris Message (CCSDS 502.0-B) с одним сегментом.
+// Векторы состояния - в системе TEME относительно центра Земли, время - UTC.
+type OEM struct {
+	Originator   string
+	CreationDate time.Time
+	ObjectName   string
+	ObjectID     string // международное обозначение, например 2023-091G
+	States       []StateVector
+}
+
+// KVN возвращает OEM в текстовом формате "KEY = value"
+func (o OEM) KVN() ([]byte, error) {
+	if len(o.States) == 0 {
+		return nil, errors.New("нет векторов состояния для OEM")
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "CCSDS_OEM_VERS = 2.0\n")
+	fmt.Fprintf(&buf, "CREATION_DATE = %s\n", o.CreationDate.UTC().Format(ccsdsTimeLayout))
+	fmt.Fprintf(&buf, "ORIGINATOR = %s\n", o.Originator)
+	fmt.Fprintf(&buf, "\nMETA_START\n")
+	fmt.Fprintf(&buf, "OBJECT_NAME = %s\n", o.ObjectName)
+	fmt.Fprintf(&buf, "OBJECT_ID = %s\n", o.ObjectID)
+	fmt.Fprintf(&buf, "CENTER_NAME = EARTH\n")
+	fmt.Fprintf(&buf, "REF_FRAME = TEME\n")
+	fmt.Fprintf(&buf, "TIME_SYSTEM = UTC\n")
+	fmt.Fprintf(&buf, "START_TIME = %s\n", o.States[0].Time.UTC().Format(ccsdsTimeLayout))
+	fmt.Fprintf(&buf, "STOP_TIME = %s\n", o.States[len(o.States)-1].Time.UTC().Format(ccsdsTimeLayout))
+	fmt.Fprintf(&buf, "META_STOP\n\n")
+	fmt.Fprintf(&buf, "COMMENT SGP4, position [km], velocity [km/s]\n")
+
+	for _, sv := range o.States {
+		fmt.Fprintf(&buf, "%s %.6f %.6f %.6f %.9f %.9f %.9f\n",
+			sv.Time.UTC().Format(ccsdsTimeLayout), sv.X, sv.Y, sv.Z, sv.VX, sv.VY, sv.VZ)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// XML возвращает OEM в формате NDM/XML
+func (o OEM) XML() ([]byte, error) {
+	if len(o.States) == 0 {
+		return nil, errors.New("нет векторов состояния для OEM")
+	}
+
+	doc := oemXML{
+		ID:             "CCSDS_OEM_VERS",
+		Version:        "2.0",
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://sanaregistry.org/r/ndmxml_unqualified/ndmxml-2.0.0-master-2.0.xsd",
+		Header: oemXMLHeader{
+			CreationDate: o.CreationDate.UTC().Format(ccsdsTimeLayout),
+			Originator:   o.Originator,
+		},
+	}
+
+	doc.Segment.Metadata = oemXMLMetadata{
+		ObjectName: o.ObjectName,
+		ObjectID:   o.ObjectID,
+		CenterName: "EARTH",
+		RefFrame:   "TEME",
+		TimeSystem: "UTC",
+		StartTime:  o.States[0].Time.UTC().Format(ccsdsTimeLayout),
+		StopTime:   o.States[len(o.States)-1].Time.UTC().Format(ccsdsTimeLayout),
+	}
+
+	doc.Segment.Data.Comment = "SGP4, position [km], velocity [km/s]"
+	doc.Segment.Data.States = make([]oemXMLState, 0, len(o.States))
+	for _, sv := range o.States {
+		doc.Segment.Data.States = append(doc.Segment.Data.States, oemXMLState{
+			Epoch: sv.Time.UTC().Format(ccsdsTimeLayout),
+			X:     fmt.Sprintf("%.6f", sv.X),
+			Y:     fmt.Sprintf("%.6f", sv.Y),
+			Z:     fmt.Sprintf("%.6f", sv.Z),
+			XDot:  fmt.Sprintf("%.9f", sv.VX),
+			YDot:  fmt.Sprintf("%.9f", sv.VY),
+			ZDot:  fmt.Sprintf("%.9f", sv.VZ),
+		})
+	}
+
+	res, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования OEM XML: %w", err)
+	}
+
+	return append([]byte(xml.Header), res...), nil
+}
+
+type oemXML struct {
+	XMLName        xml.Name     `xml:"oem"`
+	XSI            string       `xml:"xmlns:xsi,attr"`
+	SchemaLocation string       `xml:"xsi:noNamespaceSchemaLocation,attr"`
+	ID             string       `xml:"id,attr"`
+	Version        string       `xml:"version,attr"`
+	Header         oemXMLHeader `xml:"header"`
+	Segment        struct {
+		Metadata oemXMLMetadata `xml:"metadata"`
+		Data     struct {
+			Comment string        `xml:"COMMENT"`
+			States  []oemXMLState `xml:"stateVector"`
+		} `xml:"data"`
+	} `xml:"body>segment"`
+}
+
+type oemXMLHeader struct {
+	CreationDate string `xml:"CREATION_DATE"`
+	Originator   string `xml:"ORIGINATOR"`
+}
+
+type oemXMLMetadata struct {
+	ObjectName string `xml:"OBJECT_NAME"`
+	ObjectID   string `xml:"OBJECT_ID"`
+	CenterName string `xml:"CENTER_NAME"`
+	RefFrame   string `xml:"REF_FRAME"`
+	TimeSystem string `xml:"TIME_SYSTEM"`
+	StartTime  string `xml:"START_TIME"`
+	StopTime   string `xml:"STOP_TIME"`
+}
+
+type oemXMLState struct {
+	Epoch string `xml:"EPOCH"`
+	X     string `xml:"X"`
+	Y     string `xml:"Y"`
+	Z     string `xml:"Z"`
+	XDot  string `xml:"X_DOT"`
+	YDot  string `xml:"Y_DOT"`
+	ZDot  string `xml:"Z_DOT"`
+}
@@ -0,0 +1,335 @@
+package satellite
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OMM - CCSDS Orbit Mean-elements Message (CCSDS 502.0-B) в JSON представлении CelesTrak
+type OMM struct {
+	CCSDSOMMVers       string  `json:"CCSDS_OMM_VERS,omitempty"`
+	ObjectName         string  `json:"OBJECT_NAME"`
+	ObjectID           string  `json:"OBJECT_ID"`
+	CenterName         string  `json:"CENTER_NAME"`
+	RefFrame           string  `json:"REF_FRAME"`
+	TimeSystem         string  `json:"TIME_SYSTEM"`
+	MeanElementTheory  string  `json:"MEAN_ELEMENT_THEORY"`
+	Epoch              string  `json:"EPOCH"`
+	MeanMotion         float64 `json:"MEAN_MOTION"`
+	Eccentricity       float64 `json:"ECCENTRICITY"`
+	Inclination        float64 `json:"INCLINATION"`
+	RAOfAscNode        float64 `json:"RA_OF_ASC_NODE"`
+	ArgOfPericenter    float64 `json:"ARG_OF_PERICENTER"`
+	MeanAnomaly        float64 `json:"MEAN_ANOMALY"`
+	EphemerisType      int     `json:"EPHEMERIS_TYPE"`
+	ClassificationType string  `json:"CLASSIFICATION_TYPE"`
+	NoradCatID         int     `json:"NORAD_CAT_ID"`
+	ElementSetNo       int     `json:"ELEMENT_SET_NO"`
+	RevAtEpoch         int     `json:"REV_AT_EPOCH"`
+	BStar              float64 `json:"BSTAR"`
+	MeanMotionDot      float64 `json:"MEAN_MOTION_DOT"`
+	MeanMotionDDot     float64 `json:"MEAN_MOTION_DDOT"`
+}
+
+// формат времени EPOCH в OMM
+const ommEpochLayout = "2006-01-02T15:04:05.000000"
+
+// ParseOMM разбирает OMM в формате KVN, XML или JSON (формат определяется автоматически)
+// и возвращает средние элементы SGP4
+func ParseOMM(data []byte) (Elements, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return Elements{}, errors.New("пустой OMM")
+	}
+
+	var (
+		fields map[string]string
+		err    error
+	)
+
+	switch data[0] {
+	case '{', '[':
+		fields, err = ommFieldsJSON(data)
+	case '<':
+		fields, err = ommFieldsXML(data)
+	default:
+		fields, err = ommFieldsKVN(data)
+	}
+	if err != nil {
+		return Elements{}, err
+	}
+
+	return elementsFromOMMFields(fields)
+}
+
+// OMM возвращает элементы в виде OMM для хранения и выдачи в JSON
+func (e Elements) OMM() OMM {
+	class := e.Classification
+	if class == "" {
+		class = "U"
+	}
+
+	return OMM{
+		CCSDSOMMVers:       "2.0",
+		ObjectName:         e.ObjectName,
+		ObjectID:           e.ObjectID,
+		CenterName:         "EARTH",
+		RefFrame:           "TEME",
+		TimeSystem:         "UTC",
+		MeanElementTheory:  "SGP4",
+		Epoch:              e.Epoch.UTC().Format(ommEpochLayout),
+		MeanMotion:         e.MeanMotion,
+		Eccentricity:       e.Eccentricity,
+		Inclination:        e.Inclination,
+		RAOfAscNode:        e.RAAN,
+		ArgOfPericenter:    e.ArgOfPericenter,
+		MeanAnomaly:        e.MeanAnomaly,
+		EphemerisType:      e.EphemerisType,
+		ClassificationType: class,
+		NoradCatID:         e.CatalogNumber,
+		ElementSetNo:       e.ElementSetNo,
+		RevAtEpoch:         e.RevAtEpoch,
+		BStar:              e.BStar,
+		MeanMotionDot:      e.MeanMotionDot,
+		MeanMotionDDot:     e.MeanMotionDDot,
+	}
+}
+
+func ommFieldsJSON(data []byte) (map[string]string, error) {
+	// CelesTrak отдает массив OMM, берем первый
+	if data[0] == '[' {
+		var list []json.RawMessage
+
+		err := json.Unmarshal(data, &list)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора OMM JSON: %w", err)
+		}
+		if len(list) == 0 {
+			return nil, errors.New("пустой массив OMM")
+		}
+
+		data = list[0]
+	}
+
+	var raw map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора OMM JSON: %w", err)
+	}
+
+	fields := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if v == nil {
+			continue
+		}
+		fields[strings.ToUpper(k)] = fmt.Sprint(v)
+	}
+
+	return fields, nil
+}
+
+// ommFieldsXML собирает значения всех листовых элементов XML (EPOCH, MEAN_MOTION, ...)
+func ommFieldsXML(data []byte) (map[string]string, error) {
+	fields := make(map[string]string)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var (
+		current string
+		text    strings.Builder
+	)
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора OMM XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			current = strings.ToUpper(t.Name.Local)
+			text.Reset()
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if strings.ToUpper(t.Name.Local) == current {
+				if value := strings.TrimSpace(text.String()); value != "" {
+					fields[current] = value
+				}
+			}
+			current = ""
+			text.Reset()
+		}
+	}
+
+	return fields, nil
+}
+
+// ommFieldsKVN разбирает строки вида "KEY = value [units]"
+func ommFieldsKVN(data []byte) (map[string]string, error) {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "COMMENT") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("некорректная строка OMM KVN: %q", line)
+		}
+
+		value = strings.TrimSpace(value)
+		// единицы измерения в квадратных скобках не нужны
+		if idx := strings.Index(value, "["); idx >= 0 {
+			value = strings.TrimSpace(value[:idx])
+		}
+
+		fields[strings.ToUpper(strings.TrimSpace(key))] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения OMM KVN: %w", err)
+	}
+
+	return fields, nil
+}
+
+func elementsFromOMMFields(fields map[string]string) (Elements, error) {
+	if theory, ok := fields["MEAN_ELEMENT_THEORY"]; ok && !strings.EqualFold(theory, "SGP4") && !strings.EqualFold(theory, "SGP/SGP4") {
+		return Elements{}, fmt.Errorf("неподдерживаемая теория средних элементов %q, нужна SGP4", theory)
+	}
+
+	if ts, ok := fields["TIME_SYSTEM"]; ok && !strings.EqualFold(ts, "UTC") {
+		return Elements{}, fmt.Errorf("неподдерживаемая шкала времени %q, нужна UTC", ts)
+	}
+
+	if frame, ok := fields["REF_FRAME"]; ok && !strings.EqualFold(frame, "TEME") {
+		return Elements{}, fmt.Errorf("неподдерживаемая система координат %q, нужна TEME", frame)
+	}
+
+	e := Elements{
+		ObjectName:     fields["OBJECT_NAME"],
+		ObjectID:       fields["OBJECT_ID"],
+		Classification: fields["CLASSIFICATION_TYPE"],
+	}
+
+	epoch, ok := fields["EPOCH"]
+	if !ok {
+		return Elements{}, errors.New("в OMM нет EPOCH")
+	}
+
+	var err error
+
+	e.Epoch, err = parseOMMEpoch(epoch)
+	if err != nil {
+		return Elements{}, err
+	}
+
+	required := []struct {
+		key string
+		dst *float64
+	}{
+		{"MEAN_MOTION", &e.MeanMotion},
+		{"ECCENTRICITY", &e.Eccentricity},
+		{"INCLINATION", &e.Inclination},
+		{"RA_OF_ASC_NODE", &e.RAAN},
+		{"ARG_OF_PERICENTER", &e.ArgOfPericenter},
+		{"MEAN_ANOMALY", &e.MeanAnomaly},
+	}
+	for _, f := range required {
+		value, ok := fields[f.key]
+		if !ok {
+			return Elements{}, fmt.Errorf("в OMM нет %s", f.key)
+		}
+
+		*f.dst, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Elements{}, fmt.Errorf("ошибка разбора %s: %w", f.key, err)
+		}
+	}
+
+	optionalFloats := []struct {
+		key string
+		dst *float64
+	}{
+		{"BSTAR", &e.BStar},
+		{"MEAN_MOTION_DOT", &e.MeanMotionDot},
+		{"MEAN_MOTION_DDOT", &e.MeanMotionDDot},
+	}
+	for _, f := range optionalFloats {
+		value, ok := fields[f.key]
+		if !ok {
+			continue
+		}
+
+		*f.dst, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Elements{}, fmt.Errorf("ошибка разбора %s: %w", f.key, err)
+		}
+	}
+
+	optionalInts := []struct {
+		key string
+		dst *int
+	}{
+		{"NORAD_CAT_ID", &e.CatalogNumber},
+		{"EPHEMERIS_TYPE", &e.EphemerisType},
+		{"ELEMENT_SET_NO", &e.ElementSetNo},
+		{"REV_AT_EPOCH", &e.RevAtEpoch},
+	}
+	for _, f := range optionalInts {
+		value, ok := fields[f.key]
+		if !ok {
+			continue
+		}
+
+		*f.dst, err = strconv.Atoi(value)
+		if err != nil {
+			return Elements{}, fmt.Errorf("ошибка разбора %s: %w", f.key, err)
+		}
+	}
+
+	if e.MeanMotion <= 0 || e.Eccentricity < 0 || e.Eccentricity >= 1 {
+		return Elements{}, errors.New("некорректные элементы орбиты в OMM")
+	}
+
+	return e, nil
+}
+
+// parseOMMEpoch разбирает время в формате CCSDS: календарная дата или день года, с Z или без
+func parseOMMEpoch(value string) (time.Time, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "Z")
+
+	layouts := []string{
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02T15:04:05",
+		"2006-002T15:04:05.999999999",
+		"2006-002T15:04:05",
+	}
+
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("некорректный формат EPOCH: %q", value)
+}
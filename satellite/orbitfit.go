@@ -0,0 +1,640 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Системы координат векторов состояния в измерениях
+const (
+	// вращающаяся вместе с Землей система, в которой выдает решение GPS-приемник
+	FrameECEF Frame = "ecef"
+	FrameTEME Frame = "teme"
+)
+
+// Способы получения начального приближения при подгонке TLE
+const (
+	InitialOrbitGauss = "gauss" // метод Гаусса по трем направлениям
+	InitialOrbitState = "state" // вектор состояния из телеметрии
+	InitialOrbitTLE   = "tle"   // заданный набор элементов
+)
+
+const (
+	// погрешности измерений по умолчанию
+	defaultAnglesSigma   = 0.1  // град
+	defaultPositionSigma = 0.01 // км
+	defaultVelocitySigma = 1e-5 // км/с
+	// итерации дифференциальной коррекции
+	maxFitIterations = 50
+	// итерации пересчета оскулирующих элементов в средние
+	maxMeanElementsIterations = 20
+	// относительное уменьшение суммы квадратов невязок, при котором коррекция считается сошедшейся
+	fitTolerance = 1e-4
+)
+
+// приращения параметров для численного расчета производных: n (об/сут), e·cos(ω), e·sin(ω), i, Ω,
+// ω + M (град) и B*. Они намного больше точности записи TLE, иначе производные тонут в округлении.
+var fitSteps = []float64{1e-5, 1e-4, 1e-4, 1e-2, 1e-2, 1e-2, 1e-5}
+
+type Frame string
+
+// AnglesObservation - измеренное со станции направление на спутник
+type AnglesObservation struct {
+	Time       time.Time
+	Observer   ObserverCoords
+	Refraction Refraction // рефракция, которая входит в измеренный угол места
+	Az         float64    // град
+	El         float64    // град
+}
+
+// StateObservation - вектор состояния спутника из телеметрии (например, решение GPS-приемника)
+type StateObservation struct {
+	StateVector
+	Frame Frame `json:"frame"` // ecef (по умолчанию) или teme
+}
+
+// FitOptions - настройки подгонки TLE
+type FitOptions struct {
+	Profile Profile
+	// начальное приближение; nil - по измерениям: вектор состояния из телеметрии или метод Гаусса
+	Initial *Elements
+	// номер по каталогу, обозначение и другие поля, которые переносятся в результат
+	Template Elements
+	// уточнять B* (нужны измерения на интервале хотя бы в сутки), иначе B* берется из Template
+	FitBStar bool
+	// погрешности измерений, 0 - по умолчанию
+	AnglesSigma   float64 // град
+	PositionSigma float64 // км
+	VelocitySigma float64 // км/с
+}
+
+// AngleResidual - невязка направления: измеренное минус рассчитанное
+type AngleResidual struct {
+	Time time.Time `json:"time"`
+	Az   float64   `json:"az"` // по азимуту, умноженная на cos(el), град
+	El   float64   `json:"el"` // град
+}
+
+// StateResidual - невязка вектора состояния: измеренное положение минус рассчитанное
+// в орбитальной системе рассчитанного
+type StateResidual struct {
+	RICDifference
+	Velocity float64 `json:"velocity"` // модуль разницы скоростей, км/с
+}
+
+// OrbitFit - результат подгонки TLE по измерениям
+type OrbitFit struct {
+	Elements     Elements `json:"-"`
+	Line1        string   `json:"line1"`
+	Line2        string   `json:"line2"`
+	InitialOrbit string   `json:"initialOrbit"`
+	Iterations   int      `json:"iterations"`
+	Converged    bool     `json:"converged"`
+	// среднеквадратичная невязка в долях погрешности измерений (около 1 - TLE согласуется с измерениями)
+	WeightedRMS float64 `json:"weightedRms"`
+	// среднеквадратичные невязки направлений (град) и положений (км)
+	AnglesRMS   *float64 `json:"anglesRms"`
+	PositionRMS *float64 `json:"positionRms"`
+
+	AngleResiduals []AngleResidual `json:"angleResiduals"`
+	StateResiduals []StateResidual `json:"stateResiduals"`
+}
+
+// orbitFitter подгоняет средние элементы SGP4 под измерения. Параметры: n, e·cos(ω), e·sin(ω), i, Ω,
+// ω + M и, если уточняется, B*.
+type orbitFitter struct {
+	angles []AnglesObservation
+	// векторы состояния, переведенные в TEME
+	states []StateVector
+	opts   FitOptions
+	epoch  time.Time
+}
+
+// FitOrbit подгоняет набор элементов SGP4 под измерения направлений и векторы состояния
+// дифференциальной коррекцией (метод Левенберга-Марквардта). Эпоха результата - момент начального
+// приближения: среднее из трех измерений для метода Гаусса, ближайший к середине вектор состояния
+// или середина интервала измерений для заданного начального TLE.
+func FitOrbit(angles []AnglesObservation, states []StateObservation, opts FitOptions) (OrbitFit, error) {
+	if opts.AnglesSigma == 0 {
+		opts.AnglesSigma = defaultAnglesSigma
+	}
+	if opts.PositionSigma == 0 {
+		opts.PositionSigma = defaultPositionSigma
+	}
+	if opts.VelocitySigma == 0 {
+		opts.VelocitySigma = defaultVelocitySigma
+	}
+
+	if opts.AnglesSigma < 0 || opts.PositionSigma < 0 || opts.VelocitySigma < 0 {
+		return OrbitFit{}, errors.New("погрешность измерений не может быть отрицательной")
+	}
+
+	f := orbitFitter{
+		angles: angles,
+		opts:   opts,
+	}
+
+	for _, o := range states {
+		sv, err := o.teme()
+		if err != nil {
+			return OrbitFit{}, err
+		}
+
+		f.states = append(f.states, sv)
+	}
+
+	params := 6
+	if opts.FitBStar {
+		params++
+	}
+
+	if 2*len(angles)+6*len(states) < params {
+		return OrbitFit{}, fmt.Errorf("мало измерений: нужно не меньше %d величин (направление - 2, вектор состояния - 6)", params)
+	}
+
+	initial, method, err := f.initialState()
+	if err != nil {
+		return OrbitFit{}, err
+	}
+
+	f.epoch = initial.Time
+
+	p, err := f.meanElements(initial)
+	if err != nil {
+		return OrbitFit{}, fmt.Errorf("начальное приближение: %w", err)
+	}
+
+	p = p[:params]
+
+	res, cost, err := f.residuals(p)
+	if err != nil {
+		return OrbitFit{}, fmt.Errorf("начальное приближение: %w", err)
+	}
+
+	fit := OrbitFit{
+		InitialOrbit: method,
+	}
+
+	lambda := 1e-3
+
+	for fit.Iterations < maxFitIterations && !fit.Converged {
+		fit.Iterations++
+
+		jacobian, err := f.jacobian(p, res)
+		if err != nil {
+			return OrbitFit{}, err
+		}
+
+		// нормальные уравнения JᵀJ·δ = -Jᵀr
+		normal := make([][]float64, len(p))
+		gradient := make([]float64, len(p))
+		for i := range p {
+			normal[i] = make([]float64, len(p))
+			for j := range p {
+				for k := range res {
+					normal[i][j] += jacobian[k][i] * jacobian[k][j]
+				}
+			}
+			for k := range res {
+				gradient[i] -= jacobian[k][i] * res[k]
+			}
+		}
+
+		accepted := false
+		for lambda < 1e10 {
+			damped := make([][]float64, len(p))
+			for i := range normal {
+				damped[i] = append([]float64(nil), normal[i]...)
+				damped[i][i] *= 1 + lambda
+			}
+
+			delta, err := solveLinear(damped, gradient)
+			if err != nil {
+				lambda *= 10
+				continue
+			}
+
+			next := make([]float64, len(p))
+			for i := range p {
+				next[i] = p[i] + delta[i]
+			}
+
+			nextRes, nextCost, err := f.residuals(next)
+			if err != nil || nextCost >= cost {
+				lambda *= 10
+				continue
+			}
+
+			fit.Converged = (cost-nextCost)/cost < fitTolerance
+			p, res, cost = next, nextRes, nextCost
+			lambda = math.Max(lambda/10, 1e-9)
+			accepted = true
+
+			break
+		}
+
+		// шаг, уменьшающий невязки, не находится: минимум достигнут с точностью записи TLE
+		if !accepted {
+			fit.Converged = true
+		}
+	}
+
+	sat, elements, err := f.satellite(p)
+	if err != nil {
+		return OrbitFit{}, err
+	}
+
+	fit.Elements = elements
+	fit.Line1, fit.Line2 = sat.line1, sat.line2
+	fit.WeightedRMS = math.Sqrt(cost / float64(len(res)))
+
+	err = f.report(sat, &fit)
+	if err != nil {
+		return OrbitFit{}, err
+	}
+
+	return fit, nil
+}
+
+// teme возвращает вектор состояния в системе TEME
+func (o StateObservation) teme() (StateVector, error) {
+	sv := o.StateVector
+	sv.Time = sv.Time.UTC()
+
+	switch o.Frame {
+	case FrameTEME:
+		return sv, nil
+	case "", FrameECEF:
+		gst := greenwichSiderealTime(sv.Time)
+		r := vector{sv.X, sv.Y, sv.Z}
+
+		// скорость в инерциальной системе: v + ω × r
+		v := vector{sv.VX, sv.VY, sv.VZ}.add(vector{-earthRotationRate * r[1], earthRotationRate * r[0], 0})
+
+		r = temeToECEF(r, -gst)
+		v = temeToECEF(v, -gst)
+
+		return StateVector{Time: sv.Time, X: r[0], Y: r[1], Z: r[2], VX: v[0], VY: v[1], VZ: v[2]}, nil
+	}
+
+	return StateVector{}, fmt.Errorf("неизвестная система координат %q", o.Frame)
+}
+
+// initialState возвращает вектор состояния начального приближения и способ, которым он получен
+func (f *orbitFitter) initialState() (StateVector, string, error) {
+	if f.opts.Initial != nil {
+		times := make([]time.Time, 0, len(f.angles)+len(f.states))
+		for _, o := range f.angles {
+			times = append(times, o.Time.UTC())
+		}
+		for _, sv := range f.states {
+			times = append(times, sv.Time)
+		}
+
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+		middle := times[0].Add(times[len(times)-1].Sub(times[0]) / 2).Truncate(time.Millisecond)
+
+		line1, line2, err := f.opts.Initial.PropagationTLE()
+		if err != nil {
+			return StateVector{}, "", err
+		}
+
+		sat, err := NewWithProfile(line1, line2, f.opts.Profile)
+		if err != nil {
+			return StateVector{}, "", err
+		}
+
+		sv, err := sat.StateVector(middle)
+		if err != nil {
+			return StateVector{}, "", err
+		}
+
+		return sv, InitialOrbitTLE, nil
+	}
+
+	if len(f.states) > 0 {
+		first, last := f.states[0].Time, f.states[0].Time
+		for _, sv := range f.states {
+			if sv.Time.Before(first) {
+				first = sv.Time
+			}
+			if sv.Time.After(last) {
+				last = sv.Time
+			}
+		}
+
+		middle := first.Add(last.Sub(first) / 2)
+
+		best := f.states[0]
+		for _, sv := range f.states {
+			if absDuration(sv.Time.Sub(middle)) < absDuration(best.Time.Sub(middle)) {
+				best = sv
+			}
+		}
+
+		return best, InitialOrbitState, nil
+	}
+
+	triplet, err := gaussTriplet(f.angles)
+	if err != nil {
+		return StateVector{}, "", err
+	}
+
+	sv, err := gaussInitialOrbit(triplet)
+	if err != nil {
+		return StateVector{}, "", err
+	}
+
+	return sv, InitialOrbitGauss, nil
+}
+
+// meanElements подбирает средние элементы SGP4, с которыми SGP4 на эпоху дает вектор состояния sv:
+// оскулирующие элементы по SGP4 сравниваются с целевыми, и разница добавляется к средним
+func (f *orbitFitter) meanElements(sv StateVector) ([]float64, error) {
+	target := osculating(sv)
+
+	p := []float64{target.meanMotion, target.ex, target.ey, target.inclination, target.raan, target.argOfLat, f.opts.Template.BStar}
+	if f.opts.Initial != nil {
+		p[6] = f.opts.Initial.BStar
+	}
+
+	for i := 0; i < maxMeanElementsIterations; i++ {
+		sat, _, err := f.satellite(p)
+		if err != nil {
+			return nil, err
+		}
+
+		current, err := sat.StateVector(f.epoch)
+		if err != nil {
+			return nil, err
+		}
+
+		c := osculating(current)
+
+		d := []float64{
+			target.meanMotion - c.meanMotion,
+			target.ex - c.ex,
+			target.ey - c.ey,
+			target.inclination - c.inclination,
+			angleDifference(target.raan, c.raan),
+			angleDifference(target.argOfLat, c.argOfLat),
+		}
+
+		for j := range d {
+			p[j] += d[j]
+		}
+
+		if math.Abs(d[0]) < 1e-8 && math.Abs(d[5]) < 1e-5 {
+			break
+		}
+	}
+
+	return p, nil
+}
+
+// satellite создает спутник по параметрам подгонки
+func (f *orbitFitter) satellite(p []float64) (Satellite, Elements, error) {
+	e := math.Hypot(p[1], p[2])
+	if p[0] <= 0 || e >= 1 || p[3] < 0 || p[3] > 180 {
+		return Satellite{}, Elements{}, errors.New("параметры вне допустимой области")
+	}
+
+	argOfPericenter := math.Atan2(p[2], p[1]) * 180 / math.Pi
+
+	elements := f.opts.Template
+	elements.Epoch = f.epoch
+	elements.MeanMotion = p[0]
+	elements.Eccentricity = e
+	elements.Inclination = p[3]
+	elements.RAAN = normalizeDegrees(p[4])
+	elements.ArgOfPericenter = normalizeDegrees(argOfPericenter)
+	elements.MeanAnomaly = normalizeDegrees(p[5] - argOfPericenter)
+	elements.MeanMotionDot = 0
+	elements.MeanMotionDDot = 0
+
+	if len(p) > 6 {
+		elements.BStar = p[6]
+	}
+
+	// номер витка переносится на новую эпоху
+	if !f.opts.Template.Epoch.IsZero() {
+		days := f.epoch.Sub(f.opts.Template.Epoch).Hours() / 24
+		elements.RevAtEpoch = max(f.opts.Template.RevAtEpoch+int(math.Floor(days*p[0])), 0)
+	}
+
+	line1, line2, err := elements.PropagationTLE()
+	if err != nil {
+		return Satellite{}, Elements{}, err
+	}
+
+	sat, err := NewWithProfile(line1, line2, f.opts.Profile)
+	if err != nil {
+		return Satellite{}, Elements{}, err
+	}
+
+	return sat, elements, nil
+}
+
+// residuals возвращает невязки измерений, деленные на их погрешности, и сумму их квадратов
+func (f *orbitFitter) residuals(p []float64) ([]float64, float64, error) {
+	sat, _, err := f.satellite(p)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]float64, 0, 2*len(f.angles)+6*len(f.states))
+
+	for _, o := range f.angles {
+		sv, err := sat.StateVector(o.Time)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		la := sv.LookAngles(o.Observer, o.Refraction)
+
+		res = append(res,
+			angleDifference(o.Az, la.Az)*math.Cos(o.El*math.Pi/180)/f.opts.AnglesSigma,
+			(o.El-la.El)/f.opts.AnglesSigma,
+		)
+	}
+
+	for _, o := range f.states {
+		sv, err := sat.StateVector(o.Time)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		res = append(res,
+			(o.X-sv.X)/f.opts.PositionSigma,
+			(o.Y-sv.Y)/f.opts.PositionSigma,
+			(o.Z-sv.Z)/f.opts.PositionSigma,
+			(o.VX-sv.VX)/f.opts.VelocitySigma,
+			(o.VY-sv.VY)/f.opts.VelocitySigma,
+			(o.VZ-sv.VZ)/f.opts.VelocitySigma,
+		)
+	}
+
+	cost := 0.0
+	for _, r := range res {
+		cost += r * r
+	}
+
+	if math.IsNaN(cost) {
+		return nil, 0, errors.New("невязки не рассчитываются")
+	}
+
+	return res, cost, nil
+}
+
+// jacobian рассчитывает производные невязок по параметрам конечными разностями
+func (f *orbitFitter) jacobian(p, res []float64) ([][]float64, error) {
+	jacobian := make([][]float64, len(res))
+	for k := range jacobian {
+		jacobian[k] = make([]float64, len(p))
+	}
+
+	for j := range p {
+		h := fitSteps[j]
+		if j == 6 {
+			h = math.Max(h, 0.1*math.Abs(p[j]))
+		}
+
+		shifted := append([]float64(nil), p...)
+		shifted[j] += h
+
+		shiftedRes, _, err := f.residuals(shifted)
+		if err != nil {
+			// у границы допустимой области шагаем в другую сторону
+			h = -h
+			shifted[j] = p[j] + h
+
+			shiftedRes, _, err = f.residuals(shifted)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for k := range res {
+			jacobian[k][j] = (shiftedRes[k] - res[k]) / h
+		}
+	}
+
+	return jacobian, nil
+}
+
+// report заполняет невязки измерений для найденного TLE
+func (f *orbitFitter) report(sat Satellite, fit *OrbitFit) error {
+	if len(f.angles) > 0 {
+		sum := 0.0
+
+		for _, o := range f.angles {
+			sv, err := sat.StateVector(o.Time)
+			if err != nil {
+				return err
+			}
+
+			la := sv.LookAngles(o.Observer, o.Refraction)
+
+			r := AngleResidual{
+				Time: o.Time.UTC(),
+				Az:   angleDifference(o.Az, la.Az) * math.Cos(o.El*math.Pi/180),
+				El:   o.El - la.El,
+			}
+			sum += r.Az*r.Az + r.El*r.El
+
+			fit.AngleResiduals = append(fit.AngleResiduals, r)
+		}
+
+		rms := math.Sqrt(sum / float64(len(f.angles)))
+		fit.AnglesRMS = &rms
+	}
+
+	if len(f.states) > 0 {
+		sum := 0.0
+
+		for _, o := range f.states {
+			sv, err := sat.StateVector(o.Time)
+			if err != nil {
+				return err
+			}
+
+			r := StateResidual{
+				RICDifference: RIC(sv, o),
+				Velocity:      vector{o.VX - sv.VX, o.VY - sv.VY, o.VZ - sv.VZ}.norm(),
+			}
+			sum += r.Total * r.Total
+
+			fit.StateResiduals = append(fit.StateResiduals, r)
+		}
+
+		rms := math.Sqrt(sum / float64(len(f.states)))
+		fit.PositionRMS = &rms
+	}
+
+	return nil
+}
+
+// angleDifference возвращает разницу углов a - b, приведенную к (-180, 180], град
+func angleDifference(a, b float64) float64 {
+	d := normalizeDegrees(a - b)
+	if d > 180 {
+		d -= 360
+	}
+
+	return d
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
+
+// solveLinear решает систему a·x = b методом Гаусса с выбором главного элемента
+func solveLinear(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+
+	m := make([][]float64, n)
+	for i := range a {
+		m[i] = append(append([]float64(nil), a[i]...), b[i])
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+
+		if math.Abs(m[pivot][col]) < 1e-300 {
+			return nil, errors.New("вырожденная система")
+		}
+
+		m[col], m[pivot] = m[pivot], m[col]
+
+		for row := col + 1; row < n; row++ {
+			k := m[row][col] / m[col][col]
+			for j := col; j <= n; j++ {
+				m[row][j] -= k * m[col][j]
+			}
+		}
+	}
+
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := m[i][n]
+		for j := i + 1; j < n; j++ {
+			sum -= m[i][j] * x[j]
+		}
+
+		x[i] = sum / m[i][i]
+	}
+
+	return x, nil
+}
@@ -0,0 +1,222 @@
+package satellite
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TLE, по которому генерируются измерения
+const (
+	fitLine1 = "1 25544U 98067A   24183.58373848  .00018876  00000+0  34701-3 0  9997"
+	fitLine2 = "2 25544  51.6418 101.3576 0006878  64.9182 295.2226 15.49597969458107"
+)
+
+func fitTruth(t *testing.T) (Satellite, Elements) {
+	t.Helper()
+
+	elements, err := ParseTLE(fitLine1, fitLine2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return New(fitLine1, fitLine2), elements
+}
+
+// ecefObservation переводит вектор состояния из TEME во вращающуюся систему, как его выдал бы GPS-приемник
+func ecefObservation(sv StateVector) StateObservation {
+	gst := greenwichSiderealTime(sv.Time)
+	r := temeToECEF(vector{sv.X, sv.Y, sv.Z}, gst)
+	v := temeToECEF(vector{sv.VX, sv.VY, sv.VZ}, gst)
+
+	// скорость относительно вращающейся Земли: v - ω × r
+	v = v.add(vector{earthRotationRate * r[1], -earthRotationRate * r[0], 0})
+
+	return StateObservation{
+		StateVector: StateVector{Time: sv.Time, X: r[0], Y: r[1], Z: r[2], VX: v[0], VY: v[1], VZ: v[2]},
+		Frame:       FrameECEF,
+	}
+}
+
+// fitDistance возвращает расстояние между положениями двух спутников на момент at, км
+func fitDistance(t *testing.T, a, b Satellite, at time.Time) float64 {
+	t.Helper()
+
+	sa, err := a.StateVector(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, err := b.StateVector(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return math.Sqrt((sa.X-sb.X)*(sa.X-sb.X) + (sa.Y-sb.Y)*(sa.Y-sb.Y) + (sa.Z-sb.Z)*(sa.Z-sb.Z))
+}
+
+// checkFit сравнивает подогнанные элементы с исходными. Эпоха результата другая, поэтому долгота узла
+// сравнивается с пересчитанной на нее, а среднее движение - с допуском на торможение (n растет
+// примерно на 2·10⁻⁴ об/сут за сутки).
+func checkFit(t *testing.T, fit OrbitFit, truth Satellite, elements Elements, later time.Time) {
+	t.Helper()
+
+	if !fit.Converged {
+		t.Errorf("коррекция не сошлась за %d итераций", fit.Iterations)
+	}
+
+	got := fit.Elements
+	checks := []struct {
+		name      string
+		delta     float64
+		tolerance float64
+	}{
+		{"среднее движение, об/сут", got.MeanMotion - elements.MeanMotion, 5e-4},
+		{"эксцентриситет", got.Eccentricity - elements.Eccentricity, 1e-5},
+		{"наклонение, град", got.Inclination - elements.Inclination, 1e-3},
+		{"долгота узла, град", angleDifference(got.RAAN, elements.RAANAt(got.Epoch)), 1e-2},
+		// прогноз по подогнанному TLE, км
+		{"положение через 6 ч, км", fitDistance(t, New(fit.Line1, fit.Line2), truth, later), 0.05},
+	}
+
+	for _, c := range checks {
+		if math.Abs(c.delta) > c.tolerance {
+			t.Errorf("%s: расхождение %.3g, допуск %g", c.name, c.delta, c.tolerance)
+		}
+	}
+}
+
+func TestFitOrbitStates(t *testing.T) {
+	truth, elements := fitTruth(t)
+	from := time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)
+
+	for _, frame := range []Frame{FrameTEME, FrameECEF} {
+		t.Run(string(frame), func(t *testing.T) {
+			// два витка, вектор раз в 10 минут
+			var states []StateObservation
+			for at := from; at.Before(from.Add(3 * time.Hour)); at = at.Add(10 * time.Minute) {
+				sv, err := truth.StateVector(at)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if frame == FrameTEME {
+					states = append(states, StateObservation{StateVector: sv, Frame: FrameTEME})
+				} else {
+					states = append(states, ecefObservation(sv))
+				}
+			}
+
+			fit, err := FitOrbit(nil, states, FitOptions{Profile: DefaultProfile, Template: elements})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fit.InitialOrbit != InitialOrbitState {
+				t.Errorf("начальное приближение %s, ожидалось %s", fit.InitialOrbit, InitialOrbitState)
+			}
+			if fit.PositionRMS == nil || *fit.PositionRMS > 0.01 {
+				t.Errorf("СКО положения %v км, допуск 0.01 км", fit.PositionRMS)
+			}
+
+			checkFit(t, fit, truth, elements, from.Add(9*time.Hour))
+		})
+	}
+}
+
+func TestFitOrbitAngles(t *testing.T) {
+	truth, elements := fitTruth(t)
+	obs := ObserverCoords{Lat: 55.75, Lon: 37.6, Alt: 0.2}
+	from := time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)
+
+	// два соседних пролета, направление раз в 20 секунд
+	passes := truth.PassesBetween(from, from.Add(24*time.Hour), obs)
+	if len(passes) < 2 {
+		t.Fatalf("пролетов %d, нужно два", len(passes))
+	}
+
+	var angles []AnglesObservation
+	for _, pass := range passes[:2] {
+		for at := pass.From; at.Before(pass.To); at = at.Add(20 * time.Second) {
+			la := truth.LookAngles(at, obs)
+			angles = append(angles, AnglesObservation{Time: at, Observer: obs, Az: la.Az, El: la.El})
+		}
+	}
+
+	fit, err := FitOrbit(angles, nil, FitOptions{Profile: DefaultProfile, Template: elements})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fit.InitialOrbit != InitialOrbitGauss {
+		t.Errorf("начальное приближение %s, ожидалось %s", fit.InitialOrbit, InitialOrbitGauss)
+	}
+	if fit.AnglesRMS == nil || *fit.AnglesRMS > 0.001 {
+		t.Errorf("СКО направлений %v°, допуск 0.001°", fit.AnglesRMS)
+	}
+
+	checkFit(t, fit, truth, elements, passes[1].To.Add(6*time.Hour))
+}
+
+func TestFitOrbitErrors(t *testing.T) {
+	truth, elements := fitTruth(t)
+	at := time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)
+	obs := ObserverCoords{Lat: 55.75, Lon: 37.6, Alt: 0.2}
+
+	sv, err := truth.StateVector(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	la := truth.LookAngles(at, obs)
+
+	state := []StateObservation{{StateVector: sv, Frame: FrameTEME}}
+	opts := FitOptions{Profile: DefaultProfile, Template: elements}
+
+	cases := []struct {
+		name   string
+		angles []AnglesObservation
+		states []StateObservation
+		opts   FitOptions
+		err    string
+	}{
+		{
+			name:   "два направления - четыре величины на шесть параметров",
+			angles: []AnglesObservation{{Time: at, Observer: obs, Az: la.Az, El: la.El}, {Time: at.Add(time.Minute), Observer: obs, Az: la.Az, El: la.El}},
+			opts:   opts,
+			err:    "мало измерений",
+		},
+		{
+			name:   "один вектор состояния и уточнение B*",
+			states: state,
+			opts:   FitOptions{Profile: DefaultProfile, Template: elements, FitBStar: true},
+			err:    "не меньше 7",
+		},
+		{
+			name:   "неизвестная система координат",
+			states: []StateObservation{{StateVector: sv, Frame: "itrf"}},
+			opts:   opts,
+			err:    "неизвестная система координат",
+		},
+		{
+			name:   "отрицательная погрешность положения",
+			states: state,
+			opts:   FitOptions{Profile: DefaultProfile, Template: elements, PositionSigma: -0.01},
+			err:    "отрицательной",
+		},
+		{
+			name:   "отрицательная погрешность направлений",
+			states: state,
+			opts:   FitOptions{Profile: DefaultProfile, Template: elements, AnglesSigma: -0.1},
+			err:    "отрицательной",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := FitOrbit(c.angles, c.states, c.opts)
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("ошибка %v, ожидалась %q", err, c.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package satellite
+
+import "time"
+
+const (
+	// шаг грубого поиска максимального угла места в пролете
+	passDetailsStep = 10 * time.Second
+)
+
+// PassDetails - параметры одного пролета: направления восхода и захода и кульминация
+type PassDetails struct {
+	TimeRange
+	AOSAz     float64   `json:"aosAz"`     // азимут восхода, град
+	LOSAz     float64   `json:"losAz"`     // азимут захода, град
+	MaxEl     float64   `json:"maxEl"`     // максимальный угол места, град
+	MaxElAz   float64   `json:"maxElAz"`   // азимут в кульминации, град
+	MaxElTime time.Time `json:"maxElTime"` // время кульминации
+}
+
+// PassDetails рассчитывает азимуты восхода/захода и кульминацию пролета tr
+func (s Satellite) PassDetails(tr TimeRange, obsCoords ObserverCoords) PassDetails {
+	res := PassDetails{
+		TimeRange: tr,
+		AOSAz:     s.LookAngles(tr.From, obsCoords).Az,
+		LOSAz:     s.LookAngles(tr.To, obsCoords).Az,
+		MaxEl:     -90,
+	}
+
+	// грубый поиск по сетке, затем уточнение тернарным поиском вокруг лучшей точки
+	best := tr.From
+	for t := tr.From; !t.After(tr.To); t = t.Add(passDetailsStep) {
+		if el := s.LookAngles(t, obsCoords).El; el > res.MaxEl {
+			res.MaxEl = el
+			best = t
+		}
+	}
+
+	lo, hi := best.Add(-passDetailsStep), best.Add(passDetailsStep)
+	if lo.Before(tr.From) {
+		lo = tr.From
+	}
+	if hi.After(tr.To) {
+		hi = tr.To
+	}
+
+	for hi.Sub(lo) > defaultEventTimePrecision {
+		m1 := lo.Add(hi.Sub(lo) / 3)
+		m2 := hi.Add(-hi.Sub(lo) / 3)
+
+		if s.LookAngles(m1, obsCoords).El < s.LookAngles(m2, obsCoords).El {
+			lo = m1
+		} else {
+			hi = m2
+		}
+	}
+
+	peak := lo.Add(hi.Sub(lo) / 2)
+	la := s.LookAngles(peak, obsCoords)
+	if la.El >= res.MaxEl {
+		res.MaxEl = la.El
+		best = peak
+	}
+
+	res.MaxElTime = best
+	res.MaxElAz = s.LookAngles(best, obsCoords).Az
+
+	return res
+}
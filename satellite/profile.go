@@ -0,0 +1,119 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joshuaferrara/go-satellite"
+)
+
+// Гравитационные модели SGP4
+const (
+	GravityWGS72Old Gravity = "wgs72old"
+	GravityWGS72    Gravity = "wgs72"
+	GravityWGS84    Gravity = "wgs84"
+)
+
+// Режимы работы SGP4
+const (
+	// улучшенный режим Vallado (2006)
+	OpsModeImproved OpsMode = "improved"
+	// режим, совместимый с расчетами AFSPC
+	OpsModeAFSPC OpsMode = "afspc"
+)
+
+// период, начиная с которого SGP4 считает объект объектом дальнего космоса (SDP4)
+const deepSpacePeriod = 225 * time.Minute
+
+type Gravity string
+
+type OpsMode string
+
+// ErrAFSPCDeepSpace - режим AFSPC запрошен для объекта дальнего космоса. go-satellite всегда считает
+// в улучшенном режиме, а для околоземных орбит режимы не различаются: разница только в звездном
+// времени на эпоху и в выборе узла в dpper, то есть в ветке дальнего космоса.
+var ErrAFSPCDeepSpace = errors.New("режим afspc для объектов дальнего космоса не поддерживается")
+
+// Profile - профиль расчета SGP4: гравитационная модель и режим работы
+type Profile struct {
+	Gravity Gravity `json:"gravity"`
+	OpsMode OpsMode `json:"opsMode"`
+}
+
+// DefaultProfile - профиль, с которым создается спутник в New. Константы WGS-84 оставлены по умолчанию,
+// чтобы не менять результаты уже работающих расчетов; TLE рассчитываются с константами WGS-72, и
+// наименьшую систематическую ошибку SGP4 дает профиль wgs72/improved.
+var DefaultProfile = Profile{
+	Gravity: GravityWGS84,
+	OpsMode: OpsModeImproved,
+}
+
+// ParseProfile разбирает профиль в виде "gravity/opsmode" или "gravity" (тогда режим - improved)
+func ParseProfile(s string) (Profile, error) {
+	gravity, opsMode, found := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "/")
+
+	p := Profile{
+		Gravity: Gravity(gravity),
+		OpsMode: OpsModeImproved,
+	}
+	if found {
+		p.OpsMode = OpsMode(opsMode)
+	}
+
+	if err := p.Valid(); err != nil {
+		return Profile{}, err
+	}
+
+	return p, nil
+}
+
+// Valid проверяет, что гравитационная модель и режим известны
+func (p Profile) Valid() error {
+	switch p.Gravity {
+	case GravityWGS72Old, GravityWGS72, GravityWGS84:
+	default:
+		return fmt.Errorf("неизвестная гравитационная модель %q", p.Gravity)
+	}
+
+	switch p.OpsMode {
+	case OpsModeImproved, OpsModeAFSPC:
+	default:
+		return fmt.Errorf("неизвестный режим SGP4 %q", p.OpsMode)
+	}
+
+	return nil
+}
+
+func (p Profile) String() string {
+	return string(p.Gravity) + "/" + string(p.OpsMode)
+}
+
+// NewWithProfile создает спутник с профилем расчета SGP4 profile
+func NewWithProfile(line1, line2 string, profile Profile) (Satellite, error) {
+	if err := profile.Valid(); err != nil {
+		return Satellite{}, err
+	}
+
+	if profile.OpsMode == OpsModeAFSPC {
+		elements, err := ParseTLE(line1, line2)
+		if err != nil {
+			return Satellite{}, fmt.Errorf("ParseTLE: %w", err)
+		}
+
+		if elements.Period() >= deepSpacePeriod {
+			return Satellite{}, ErrAFSPCDeepSpace
+		}
+	}
+
+	sat := newWithGravity(line1, line2, satellite.Gravity(profile.Gravity))
+	sat.profile = profile
+
+	return sat, nil
+}
+
+// Profile возвращает профиль расчета SGP4 спутника
+func (s Satellite) Profile() Profile {
+	return s.profile
+}
@@ -0,0 +1,199 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshuaferrara/go-satellite"
+)
+
+// Виды пропагаторов
+const (
+	// аналитическая модель SGP4 по TLE
+	PropagatorSGP4 PropagatorKind = "sgp4"
+	// численное интегрирование от вектора состояния на эпоху TLE (см. NumericalPropagator)
+	PropagatorNumerical PropagatorKind = "numerical"
+)
+
+type PropagatorKind string
+
+// Propagator рассчитывает вектор состояния спутника в системе TEME на момент t
+type Propagator interface {
+	StateVector(t time.Time) (StateVector, error)
+}
+
+// sgp4Propagator - аналитическая модель SGP4 по TLE, пропагатор по умолчанию
+type sgp4Propagator struct {
+	sat *satellite.Satellite
+	// эпоха TLE без дробной части секунды - от нее go-satellite отсчитывает время
+	epoch time.Time
+	// дробная часть секунды эпохи TLE, которую go-satellite отбрасывает
+	epochFraction time.Duration
+}
+
+// StateVector рассчитывает вектор состояния по SGP4.
+// go-satellite принимает время с точностью до секунды и отсчитывает его от эпохи TLE без дробной части
+// секунды, поэтому момент сдвигается на эту дробную часть, а между соседними целыми секундами
+// вектор состояния интерполируется. Время от эпохи go-satellite считает разностью юлианских дат,
+// которая округляется до десятков микросекунд, поэтому каждому вектору приписывается момент,
+// на который он рассчитан на самом деле.
+func (p sgp4Propagator) StateVector(t time.Time) (StateVector, error) {
+	if p.sat == nil {
+		return StateVector{}, errors.New("sateliite is not configured")
+	}
+
+	t = t.UTC()
+
+	lib := t.Add(-p.epochFraction)
+	from := lib.Truncate(time.Second)
+
+	a, err := p.propagate(from)
+	if err != nil {
+		return StateVector{}, err
+	}
+
+	if lib.Equal(from) {
+		return shiftState(a, t), nil
+	}
+
+	b, err := p.propagate(from.Add(time.Second))
+	if err != nil {
+		return StateVector{}, err
+	}
+
+	return hermite(a, b, t), nil
+}
+
+// propagate вызывает go-satellite на момент lib (целое число секунд) и возвращает вектор состояния
+// с моментом, к которому он на самом деле относится
+func (p sgp4Propagator) propagate(lib time.Time) (StateVector, error) {
+	position, velocity := satellite.Propagate(*p.sat, lib.Year(), int(lib.Month()), lib.Day(), lib.Hour(), lib.Minute(), lib.Second())
+
+	// время от эпохи в минутах, ровно как его считает go-satellite
+	tsince := (julianDay(lib) - julianDay(p.epoch)) * 1440
+
+	sv := StateVector{
+		Time: p.epoch.Add(p.epochFraction + time.Duration(tsince*float64(time.Minute))),
+		X:    position.X,
+		Y:    position.Y,
+		Z:    position.Z,
+		VX:   velocity.X,
+		VY:   velocity.Y,
+		VZ:   velocity.Z,
+	}
+
+	// go-satellite не возвращает код ошибки SGP4, поэтому проверяем результат
+	for _, v := range []float64{sv.X, sv.Y, sv.Z, sv.VX, sv.VY, sv.VZ} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return StateVector{}, fmt.Errorf("ошибка расчета SGP4 на момент %s", sv.Time.Format(time.RFC3339))
+		}
+	}
+
+	return sv, nil
+}
+
+// sgp4Epoch возвращает эпоху TLE без дробной части секунды и саму дробную часть, повторяя разбор
+// эпохи в go-satellite (days2mdhms), чтобы получить ровно те же величины
+func sgp4Epoch(line1 string) (time.Time, time.Duration) {
+	if len(line1) < 32 {
+		return time.Time{}, 0
+	}
+
+	year, err := strconv.Atoi(strings.TrimSpace(line1[18:20]))
+	if err != nil {
+		return time.Time{}, 0
+	}
+
+	if year < 57 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+
+	days, err := strconv.ParseFloat(strings.TrimSpace(line1[20:32]), 64)
+	if err != nil {
+		return time.Time{}, 0
+	}
+
+	dayOfYear := math.Floor(days)
+	temp := (days - dayOfYear) * 24.0
+	hour := math.Floor(temp)
+	temp = (temp - hour) * 60.0
+	minute := math.Floor(temp)
+	sec := (temp - minute) * 60.0
+
+	epoch := time.Date(year, time.January, int(dayOfYear), int(hour), int(minute), int(sec), 0, time.UTC)
+
+	return epoch, time.Duration((sec - math.Trunc(sec)) * float64(time.Second))
+}
+
+// julianDay - юлианская дата момента t (целые секунды) так, как ее считает go-satellite
+func julianDay(t time.Time) float64 {
+	return satellite.JDay(t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second())
+}
+
+// shiftState переносит вектор состояния на близкий момент t (доли миллисекунды) по задаче двух тел
+func shiftState(sv StateVector, t time.Time) StateVector {
+	dt := t.Sub(sv.Time).Seconds()
+	sv.Time = t
+
+	if dt == 0 {
+		return sv
+	}
+
+	r := vector{sv.X, sv.Y, sv.Z}
+	v := vector{sv.VX, sv.VY, sv.VZ}
+	n := r.norm()
+	acc := r.scale(-earthMu / (n * n * n))
+
+	r = r.add(v.scale(dt)).add(acc.scale(dt * dt / 2))
+	v = v.add(acc.scale(dt))
+
+	sv.X, sv.Y, sv.Z = r[0], r[1], r[2]
+	sv.VX, sv.VY, sv.VZ = v[0], v[1], v[2]
+
+	return sv
+}
+
+// WithPropagator возвращает копию спутника, у которой все расчеты (Calculate, LookAngles,
+// VisibleTimeRange и остальные) используют пропагатор p. nil - SGP4 по TLE спутника.
+func (s Satellite) WithPropagator(p Propagator) Satellite {
+	s.propagator = p
+
+	return s
+}
+
+// Propagator возвращает пропагатор, которым считается спутник
+func (s Satellite) Propagator() Propagator {
+	if s.propagator != nil {
+		return s.propagator
+	}
+
+	return s.sgp4()
+}
+
+// sgp4 возвращает пропагатор SGP4 по TLE спутника, даже если выбран другой
+func (s Satellite) sgp4() sgp4Propagator {
+	return sgp4Propagator{sat: s.sat, epoch: s.epoch, epochFraction: s.epochFraction}
+}
+
+// WithPropagatorKind возвращает копию спутника с пропагатором вида kind ("" - SGP4)
+func (s Satellite) WithPropagatorKind(kind PropagatorKind) (Satellite, error) {
+	switch kind {
+	case "", PropagatorSGP4:
+		return s.WithPropagator(nil), nil
+	case PropagatorNumerical:
+		p, err := s.NumericalPropagator()
+		if err != nil {
+			return Satellite{}, err
+		}
+
+		return s.WithPropagator(p), nil
+	}
+
+	return Satellite{}, fmt.Errorf("неизвестный пропагатор %q", kind)
+}
@@ -0,0 +1,72 @@
+package satellite
+
+import (
+	"math"
+)
+
+// Модели атмосферной рефракции
+const (
+	// без поправки, геометрический угол места
+	RefractionNone RefractionModel = "none"
+	// формула Беннетта (в обратной форме Саемундссона) для стандартной атмосферы: 1010 гПа, 10°C
+	RefractionBennett RefractionModel = "bennett"
+	// формула Беннетта с поправкой на давление и температуру у наблюдателя
+	RefractionBennettPT RefractionModel = "bennett-pt"
+)
+
+const (
+	standardPressure    = 1010.0 // гПа
+	standardTemperature = 10.0   // °C
+	// ниже этого угла места формула теряет смысл, поправка берется постоянной
+	minRefractionElevation = -1.0
+)
+
+type RefractionModel string
+
+// Refraction задает модель рефракции. Давление и температура учитываются только моделью bennett-pt.
+type Refraction struct {
+	Model       RefractionModel `json:"model"`
+	Pressure    float64         `json:"pressure"`    // гПа
+	Temperature float64         `json:"temperature"` // °C
+}
+
+// Valid проверяет, что модель известна, а для bennett-pt задано физичное давление
+func (r Refraction) Valid() bool {
+	switch r.Model {
+	case "", RefractionNone, RefractionBennett:
+		return true
+	case RefractionBennettPT:
+		return r.Pressure > 0 && r.Temperature > -273.15
+	}
+
+	return false
+}
+
+// Correction возвращает поправку (град), которую нужно прибавить к геометрическому углу места el,
+// чтобы получить видимый угол места
+func (r Refraction) Correction(el float64) float64 {
+	factor := 1.0
+
+	switch r.Model {
+	case RefractionBennett:
+	case RefractionBennettPT:
+		factor = (r.Pressure / standardPressure) * ((273.15 + standardTemperature) / (273.15 + r.Temperature))
+	default:
+		return 0
+	}
+
+	el = math.Max(el, minRefractionElevation)
+
+	// R в угловых минутах
+	R := 1.02 / math.Tan((el+10.3/(el+5.11))*math.Pi/180)
+
+	return factor * R / 60
+}
+
+// WithRefraction возвращает копию спутника, у которой LookAngles (а значит и поиск
+// восхода/захода в VisibleTimeRange) учитывают рефракцию
+func (s Satellite) WithRefraction(r Refraction) Satellite {
+	s.refraction = r
+
+	return s
+}
@@ -0,0 +1,196 @@
+package satellite
+
+import (
+	"math"
+	"time"
+)
+
+// Типы орбит
+const (
+	RegimeLEO OrbitRegime = "leo" // низкая: апогей ниже 2000 км
+	RegimeMEO OrbitRegime = "meo" // средняя: между низкой и геосинхронной
+	RegimeGEO OrbitRegime = "geo" // геосинхронная: период около звездных суток, почти круговая
+	RegimeHEO OrbitRegime = "heo" // высокоэллиптическая (например, Молния)
+	// выше геосинхронной
+	RegimeHigh OrbitRegime = "high"
+)
+
+const (
+	// высота апогея, ниже которой орбита считается низкой, км
+	leoMaxApogeeAltitude = 2000.0
+	// эксцентриситет, начиная с которого орбита считается высокоэллиптической
+	heoMinEccentricity = 0.25
+	// допустимое отклонение периода геосинхронной орбиты от звездных суток (доля)
+	geoPeriodTolerance = 0.1
+	// эксцентриситет, до которого орбита с суточным периодом считается геосинхронной
+	geoMaxEccentricity = 0.1
+	// звездные сутки
+	siderealDay = 86164090 * time.Millisecond
+
+	// шаг грубого поиска восхода и захода - такая доля периода круговой орбиты на высоте перигея
+	// (самое быстрое движение по небу), но в этих пределах
+	searchStepsPerPeriod = 20
+	minSearchStep        = time.Minute
+	maxSearchStep        = time.Hour
+	// шаг поиска кульминаций - такая доля шага поиска восхода, но в этих пределах
+	culminationStepsPerSearchStep = 30
+	minCulminationStep            = 10 * time.Second
+	maxCulminationStep            = 5 * time.Minute
+	// на сколько угол места должен упасть после максимума, чтобы он считался отдельной кульминацией, град
+	culminationProminence = 0.01
+)
+
+type OrbitRegime string
+
+// RegimeInfo - тип орбиты и то, как из-за него выглядят пролеты
+type RegimeInfo struct {
+	Regime          OrbitRegime `json:"regime"`
+	PeriodMinutes   float64     `json:"periodMinutes"`
+	PerigeeAltitude float64     `json:"perigeeAltitude"` // км
+	ApogeeAltitude  float64     `json:"apogeeAltitude"`  // км
+	Description     string      `json:"description"`
+	// шаг, с которым ищутся восход и заход, с
+	SearchStepSeconds float64 `json:"searchStepSeconds"`
+}
+
+// Culmination - местный максимум угла места внутри пролета
+type Culmination struct {
+	Time time.Time `json:"time"`
+	Az   float64   `json:"az"` // град
+	El   float64   `json:"el"` // град
+}
+
+var regimeDescriptions = map[OrbitRegime]string{
+	RegimeLEO:  "низкая орбита: пролеты длятся минуты, в пролете одна кульминация",
+	RegimeMEO:  "средняя орбита: пролеты длятся часы",
+	RegimeGEO:  "геосинхронная орбита: спутник почти неподвижен на небе - виден постоянно или не виден совсем",
+	RegimeHEO:  "высокоэллиптическая орбита: долгие пролеты у апогея и короткие у перигея, в пролете может быть несколько кульминаций",
+	RegimeHigh: "орбита выше геосинхронной: пролеты длятся сутки и дольше",
+}
+
+// Regime определяет тип орбиты по средним элементам
+func (e Elements) Regime() RegimeInfo {
+	a := e.SemiMajorAxis()
+	period := e.Period()
+
+	res := RegimeInfo{
+		PeriodMinutes:   period.Minutes(),
+		PerigeeAltitude: a*(1-e.Eccentricity) - equatorialRadiusKm,
+		ApogeeAltitude:  a*(1+e.Eccentricity) - equatorialRadiusKm,
+	}
+
+	switch {
+	case e.Eccentricity >= heoMinEccentricity:
+		res.Regime = RegimeHEO
+	case res.ApogeeAltitude < leoMaxApogeeAltitude:
+		res.Regime = RegimeLEO
+	case math.Abs(period.Seconds()/siderealDay.Seconds()-1) < geoPeriodTolerance && e.Eccentricity < geoMaxEccentricity:
+		res.Regime = RegimeGEO
+	case period < siderealDay:
+		res.Regime = RegimeMEO
+	default:
+		res.Regime = RegimeHigh
+	}
+
+	res.Description = regimeDescriptions[res.Regime]
+	res.SearchStepSeconds = res.searchStep().Seconds()
+
+	return res
+}
+
+// Regime определяет тип орбиты спутника по его TLE
+func (s Satellite) Regime() (RegimeInfo, bool) {
+	elements, err := ParseTLE(s.line1, s.line2)
+	if err != nil || elements.MeanMotion <= 0 {
+		return RegimeInfo{}, false
+	}
+
+	return elements.Regime(), true
+}
+
+// searchStep возвращает шаг грубого поиска восхода и захода: пролет не короче времени, за которое
+// спутник в перигее проходит заметную часть витка, поэтому шаг - доля периода круговой орбиты
+// на высоте перигея
+func (r RegimeInfo) searchStep() time.Duration {
+	radius := equatorialRadiusKm + math.Max(r.PerigeeAltitude, 0)
+	period := 2 * math.Pi * math.Sqrt(radius*radius*radius/earthMu)
+
+	step := time.Duration(period / searchStepsPerPeriod * float64(time.Second))
+
+	return min(max(step, minSearchStep), maxSearchStep).Round(time.Second)
+}
+
+// culminationStep возвращает шаг, с которым ищутся кульминации внутри пролета
+func (r RegimeInfo) culminationStep() time.Duration {
+	step := r.searchStep() / culminationStepsPerSearchStep
+
+	return min(max(step, minCulminationStep), maxCulminationStep).Round(time.Second)
+}
+
+// culminations находит местные максимумы угла места внутри пролета tr. Максимумы на границах
+// пролета (например, если он начался до начала поиска) кульминациями не считаются.
+func (s Satellite) culminations(tr TimeRange, obsCoords ObserverCoords, step time.Duration) []Culmination {
+	var (
+		res []Culmination
+		// наибольший угол после последнего спада и наименьший после последнего максимума
+		maxT           time.Time
+		maxEl, minEl   = math.Inf(-1), math.Inf(1)
+		lookingForPeak = true
+	)
+
+	for t := tr.From; !t.After(tr.To); t = t.Add(step) {
+		el := s.LookAngles(t, obsCoords).El
+
+		if el > maxEl {
+			maxEl, maxT = el, t
+		}
+		if el < minEl {
+			minEl = el
+		}
+
+		if lookingForPeak && el < maxEl-culminationProminence {
+			if maxT.After(tr.From) {
+				res = append(res, s.refineCulmination(maxT, step, tr, obsCoords))
+			}
+
+			lookingForPeak = false
+			minEl = el
+		} else if !lookingForPeak && el > minEl+culminationProminence {
+			lookingForPeak = true
+			maxEl, maxT = el, t
+		}
+	}
+
+	return res
+}
+
+// refineCulmination уточняет максимум угла места около момента t тернарным поиском
+func (s Satellite) refineCulmination(t time.Time, step time.Duration, tr TimeRange, obsCoords ObserverCoords) Culmination {
+	lo, hi := t.Add(-step), t.Add(step)
+	if lo.Before(tr.From) {
+		lo = tr.From
+	}
+	if hi.After(tr.To) {
+		hi = tr.To
+	}
+
+	for hi.Sub(lo) > defaultEventTimePrecision {
+		m1 := lo.Add(hi.Sub(lo) / 3)
+		m2 := hi.Add(-hi.Sub(lo) / 3)
+
+		if s.LookAngles(m1, obsCoords).El < s.LookAngles(m2, obsCoords).El {
+			lo = m1
+		} else {
+			hi = m2
+		}
+	}
+
+	peak := lo.Add(hi.Sub(lo) / 2)
+	la := s.LookAngles(peak, obsCoords)
+
+	return Culmination{
+		Time: peak,
+		Az:   la.Az,
+		El:   la.El,
+	}
+}
@@ -0,0 +1,378 @@
+package satellite
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// шаг трассы при поиске пересечений восходящих и нисходящих витков
+	crossoverStep = 30 * time.Second
+	// минимальный промежуток между витками в точке пересечения: соседние отрезки у самой северной
+	// (южной) точки витка касаются друг друга, а не пересекаются
+	minCrossoverGap = 10 * time.Minute
+	// размер ячейки сетки, по которой раскладываются нисходящие отрезки трассы, град: отрезок за
+	// crossoverStep на низкой орбите занимает одну-две ячейки
+	crossoverCellDeg = 2.0
+)
+
+// шаги уточнения точки пересечения (половина отрезка трассы около нее)
+var crossoverRefineSteps = []time.Duration{5 * time.Second, 500 * time.Millisecond, 50 * time.Millisecond}
+
+// RepeatCycle - повторяемость трассы с учетом дрейфа из-за J2
+type RepeatCycle struct {
+	// витков за узловые сутки - оборот Земли относительно плоскости орбиты
+	RevsPerDay         float64 `json:"revsPerDay"`
+	NodalPeriodMinutes float64 `json:"nodalPeriodMinutes"` // период между восходящими узлами, мин
+	NodalDayHours      float64 `json:"nodalDayHours"`      // длительность узловых суток, ч
+	// сдвиг трассы по экватору между соседними витками
+	TrackSpacingDeg float64 `json:"trackSpacingDeg"`
+	TrackSpacingKm  float64 `json:"trackSpacingKm"`
+	// повторение трассы; nil, если за наибольшее количество суток трасса не повторяется с нужной точностью
+	Repeat *TrackRepeat `json:"repeat"`
+}
+
+// TrackRepeat - цикл, после которого трасса повторяется
+type TrackRepeat struct {
+	Days          int     `json:"days"` // узловых суток
+	Orbits        int     `json:"orbits"`
+	DurationHours float64 `json:"durationHours"`
+	// на сколько трасса смещается по экватору за цикл, км
+	DriftKm float64 `json:"driftKm"`
+	// расстояние по экватору между соседними трассами всего цикла
+	GridSpacingDeg float64 `json:"gridSpacingDeg"`
+	GridSpacingKm  float64 `json:"gridSpacingKm"`
+}
+
+// Crossover - пересечение трасс восходящего и нисходящего витков
+type Crossover struct {
+	Lat            float64   `json:"lat"`
+	Lon            float64   `json:"lon"`
+	AscendingTime  time.Time `json:"ascendingTime"`
+	DescendingTime time.Time `json:"descendingTime"`
+	// промежуток между прохождениями точки, ч (> 0 - нисходящий виток позже)
+	TimeDifferenceHours float64 `json:"timeDifferenceHours"`
+}
+
+// ArgOfPerigeeRate возвращает вековой дрейф аргумента перигея из-за J2, град/сут
+func (e Elements) ArgOfPerigeeRate() float64 {
+	a := e.SemiMajorAxis()
+	p := a * (1 - e.Eccentricity*e.Eccentricity)
+	n := e.MeanMotion * 360 // град/сут
+	cos := math.Cos(e.Inclination * math.Pi / 180)
+
+	return 0.75 * n * earthJ2 * (equatorialRadiusKm / p) * (equatorialRadiusKm / p) * (5*cos*cos - 1)
+}
+
+// RepeatCycle рассчитывает повторяемость трассы: аргумент широты растет со скоростью n + ω̇, Земля
+// поворачивается относительно узла со скоростью ωз - Ω̇. Ищется наименьшее число узловых суток
+// (не больше maxDays), за которое трасса возвращается не дальше tolerance (км) по экватору.
+// Торможение в атмосфере не учитывается.
+func (e Elements) RepeatCycle(tolerance float64, maxDays int) RepeatCycle {
+	// град/сут
+	argOfLatRate := e.MeanMotion*360 + e.ArgOfPerigeeRate()
+	earthRate := earthRotationRate*86400*180/math.Pi - e.RAANRate()
+
+	revsPerDay := argOfLatRate / earthRate
+	equator := 2 * math.Pi * equatorialRadiusKm
+
+	res := RepeatCycle{
+		RevsPerDay:         revsPerDay,
+		NodalPeriodMinutes: 360 / argOfLatRate * 24 * 60,
+		NodalDayHours:      360 / earthRate * 24,
+		TrackSpacingDeg:    360 / revsPerDay,
+		TrackSpacingKm:     equator / revsPerDay,
+	}
+
+	for days := 1; days <= maxDays; days++ {
+		orbits := int(math.Round(revsPerDay * float64(days)))
+		if orbits == 0 {
+			continue
+		}
+
+		// за orbits витков Земля поворачивается на orbits/revsPerDay оборотов, трасса повторяется,
+		// если это почти целое число
+		drift := math.Abs(float64(orbits)/revsPerDay-float64(days)) * equator
+		if drift > tolerance {
+			continue
+		}
+
+		res.Repeat = &TrackRepeat{
+			Days:           days,
+			Orbits:         orbits,
+			DurationHours:  float64(orbits) * res.NodalPeriodMinutes / 60,
+			DriftKm:        drift,
+			GridSpacingDeg: 360 / float64(orbits),
+			GridSpacingKm:  equator / float64(orbits),
+		}
+
+		break
+	}
+
+	return res
+}
+
+// RepeatCycle рассчитывает повторяемость трассы по TLE спутника (см. Elements.RepeatCycle)
+func (s Satellite) RepeatCycle(tolerance float64, maxDays int) (RepeatCycle, error) {
+	if tolerance <= 0 {
+		return RepeatCycle{}, errors.New("допуск повторения должен быть положительным")
+	}
+
+	e, err := ParseTLE(s.line1, s.line2)
+	if err != nil {
+		return RepeatCycle{}, err
+	}
+
+	if e.MeanMotion <= 0 {
+		return RepeatCycle{}, errors.New("некорректное среднее движение в TLE")
+	}
+
+	return e.RepeatCycle(tolerance, maxDays), nil
+}
+
+// crossoverSegment - отрезок трассы между соседними отсчетами
+type crossoverSegment struct {
+	from, to time.Time
+	a, b     GeoPoint
+}
+
+// crossoverCell - ячейка сетки широта/долгота размером crossoverCellDeg
+type crossoverCell struct {
+	lat, lon int
+}
+
+// split делит отрезок, пересекающий меридиан 180°, на две части по обе стороны от него: иначе в
+// координатах долгота/широта он превратился бы в отрезок через всю карту
+func (seg crossoverSegment) split() []crossoverSegment {
+	if math.Abs(seg.b.Lon-seg.a.Lon) <= 180 {
+		return []crossoverSegment{seg}
+	}
+
+	// долгота конца, продолженная через меридиан 180°, и сам меридиан со стороны начала
+	bLon, edge := seg.b.Lon+360, 180.0
+	if seg.a.Lon < 0 {
+		bLon, edge = seg.b.Lon-360, -180.0
+	}
+
+	f := (edge - seg.a.Lon) / (bLon - seg.a.Lon)
+	lat := seg.a.Lat + f*(seg.b.Lat-seg.a.Lat)
+	t := seg.from.Add(time.Duration(f * float64(seg.to.Sub(seg.from))))
+
+	return []crossoverSegment{
+		{from: seg.from, to: t, a: seg.a, b: GeoPoint{Lat: lat, Lon: edge}},
+		{from: t, to: seg.to, a: GeoPoint{Lat: lat, Lon: -edge}, b: seg.b},
+	}
+}
+
+// cells возвращает ячейки сетки, которые покрывает рамка отрезка
+func (seg crossoverSegment) cells() []crossoverCell {
+	lat1 := int(math.Floor(math.Min(seg.a.Lat, seg.b.Lat) / crossoverCellDeg))
+	lat2 := int(math.Floor(math.Max(seg.a.Lat, seg.b.Lat) / crossoverCellDeg))
+	lon1 := int(math.Floor(math.Min(seg.a.Lon, seg.b.Lon) / crossoverCellDeg))
+	lon2 := int(math.Floor(math.Max(seg.a.Lon, seg.b.Lon) / crossoverCellDeg))
+
+	res := make([]crossoverCell, 0, (lat2-lat1+1)*(lon2-lon1+1))
+	for lat := lat1; lat <= lat2; lat++ {
+		for lon := lon1; lon <= lon2; lon++ {
+			res = append(res, crossoverCell{lat: lat, lon: lon})
+		}
+	}
+
+	return res
+}
+
+// Crossovers находит пересечения трасс восходящих и нисходящих витков внутри многоугольника region
+// от from до to. Трасса считается отрезками по crossoverStep, точка пересечения уточняется по все
+// более коротким отрезкам около нее.
+func (s Satellite) Crossovers(from, to time.Time, region []GeoPoint) ([]Crossover, error) {
+	if !to.After(from) {
+		return nil, errors.New("конец интервала должен быть позже начала")
+	}
+	if len(region) < 3 {
+		return nil, errors.New("область должна быть многоугольником из трех и более вершин")
+	}
+
+	minLat, maxLat, minLon, maxLon := polygonBounds(region)
+
+	var ascending, descending []crossoverSegment
+
+	prev, err := s.Calculate(from)
+	if err != nil {
+		return nil, err
+	}
+
+	for t := from.Add(crossoverStep); !t.After(to); t = t.Add(crossoverStep) {
+		cur, err := s.Calculate(t)
+		if err != nil {
+			return nil, err
+		}
+
+		seg := crossoverSegment{
+			from: t.Add(-crossoverStep),
+			to:   t,
+			a:    GeoPoint{Lat: prev.Lat, Lon: prev.Lon},
+			b:    GeoPoint{Lat: cur.Lat, Lon: cur.Lon},
+		}
+		prev = cur
+
+		for _, seg := range seg.split() {
+			// отрезки вне рамки области не рассматриваем
+			if math.Max(seg.a.Lat, seg.b.Lat) < minLat || math.Min(seg.a.Lat, seg.b.Lat) > maxLat ||
+				math.Max(seg.a.Lon, seg.b.Lon) < minLon || math.Min(seg.a.Lon, seg.b.Lon) > maxLon {
+				continue
+			}
+
+			if seg.b.Lat >= seg.a.Lat {
+				ascending = append(ascending, seg)
+			} else {
+				descending = append(descending, seg)
+			}
+		}
+	}
+
+	// нисходящие отрезки раскладываются по ячейкам сетки, и каждый восходящий проверяется только
+	// с теми, что попали в его ячейки, а не со всеми
+	cells := make(map[crossoverCell][]int)
+	for i, desc := range descending {
+		for _, c := range desc.cells() {
+			cells[c] = append(cells[c], i)
+		}
+	}
+
+	// checked[j] - номер (с 1) последнего восходящего отрезка, с которым проверен нисходящий j
+	checked := make([]int, len(descending))
+
+	res := []Crossover{}
+
+	for i, asc := range ascending {
+		var candidates []int
+		for _, c := range asc.cells() {
+			for _, j := range cells[c] {
+				if checked[j] != i+1 {
+					checked[j] = i + 1
+					candidates = append(candidates, j)
+				}
+			}
+		}
+
+		for _, j := range candidates {
+			desc := descending[j]
+			if absDuration(asc.from.Sub(desc.from)) < minCrossoverGap {
+				continue
+			}
+
+			pa, pd, ok := segmentIntersection(asc.a, asc.b, desc.a, desc.b)
+			if !ok || pa < 0 || pa > 1 || pd < 0 || pd > 1 {
+				continue
+			}
+
+			c, err := s.refineCrossover(
+				asc.from.Add(time.Duration(pa*float64(asc.to.Sub(asc.from)))),
+				desc.from.Add(time.Duration(pd*float64(desc.to.Sub(desc.from)))),
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			if pointInPolygon(GeoPoint{Lat: c.Lat, Lon: c.Lon}, region) {
+				res = append(res, c)
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return minTime(res[i].AscendingTime, res[i].DescendingTime).Before(minTime(res[j].AscendingTime, res[j].DescendingTime))
+	})
+
+	return res, nil
+}
+
+// refineCrossover уточняет моменты прохождения точки пересечения на восходящем (ta) и нисходящем (td)
+// витках пересечением все более коротких отрезков трассы около них. Долготы отсчитываются от
+// подспутниковой точки в ta, чтобы отрезки у меридиана 180° не разрывались.
+func (s Satellite) refineCrossover(ta, td time.Time) (Crossover, error) {
+	ref, err := s.Calculate(ta)
+	if err != nil {
+		return Crossover{}, err
+	}
+
+	subSatellite := func(t time.Time) (GeoPoint, error) {
+		c, err := s.Calculate(t)
+		if err != nil {
+			return GeoPoint{}, err
+		}
+
+		return GeoPoint{Lat: c.Lat, Lon: ref.Lon + math.Mod(c.Lon-ref.Lon+540, 360) - 180}, nil
+	}
+
+	for _, h := range crossoverRefineSteps {
+		a1, err := subSatellite(ta.Add(-h))
+		if err != nil {
+			return Crossover{}, err
+		}
+		a2, err := subSatellite(ta.Add(h))
+		if err != nil {
+			return Crossover{}, err
+		}
+		d1, err := subSatellite(td.Add(-h))
+		if err != nil {
+			return Crossover{}, err
+		}
+		d2, err := subSatellite(td.Add(h))
+		if err != nil {
+			return Crossover{}, err
+		}
+
+		pa, pd, ok := segmentIntersection(a1, a2, d1, d2)
+		if !ok {
+			break
+		}
+
+		ta = ta.Add(-h).Add(time.Duration(pa * float64(2*h)))
+		td = td.Add(-h).Add(time.Duration(pd * float64(2*h)))
+	}
+
+	p, err := s.Calculate(ta)
+	if err != nil {
+		return Crossover{}, err
+	}
+
+	return Crossover{
+		Lat:                 p.Lat,
+		Lon:                 p.Lon,
+		AscendingTime:       ta,
+		DescendingTime:      td,
+		TimeDifferenceHours: td.Sub(ta).Hours(),
+	}, nil
+}
+
+// segmentIntersection возвращает параметры точки пересечения прямых a1-a2 и b1-b2 в координатах
+// долгота/широта (0 - начало отрезка, 1 - конец). false - прямые параллельны.
+func segmentIntersection(a1, a2, b1, b2 GeoPoint) (float64, float64, bool) {
+	ax, ay := a2.Lon-a1.Lon, a2.Lat-a1.Lat
+	bx, by := b2.Lon-b1.Lon, b2.Lat-b1.Lat
+
+	det := ax*by - ay*bx
+	if math.Abs(det) < 1e-12 {
+		return 0, 0, false
+	}
+
+	dx, dy := b1.Lon-a1.Lon, b1.Lat-a1.Lat
+
+	return (dx*by - dy*bx) / det, (dx*ay - dy*ax) / det, true
+}
+
+// polygonBounds возвращает рамку многоугольника: широты и долготы, град
+func polygonBounds(polygon []GeoPoint) (minLat, maxLat, minLon, maxLon float64) {
+	minLat, minLon = math.Inf(1), math.Inf(1)
+	maxLat, maxLon = math.Inf(-1), math.Inf(-1)
+
+	for _, p := range polygon {
+		minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+		minLon, maxLon = math.Min(minLon, p.Lon), math.Max(maxLon, p.Lon)
+	}
+
+	return minLat, maxLat, minLon, maxLon
+}
@@ -0,0 +1,96 @@
+package satellite
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCrossoverSegmentSplit(t *testing.T) {
+	from := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		a, b     GeoPoint
+		lat      float64
+		edge     float64
+		fraction float64
+	}{
+		{name: "east to west", a: GeoPoint{Lat: 10, Lon: 179}, b: GeoPoint{Lat: 14, Lon: -179}, lat: 12, edge: 180, fraction: 0.5},
+		{name: "west to east", a: GeoPoint{Lat: -10, Lon: -179.5}, b: GeoPoint{Lat: -12, Lon: 178.5}, lat: -10.5, edge: -180, fraction: 0.25},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			seg := crossoverSegment{from: from, to: from.Add(crossoverStep), a: c.a, b: c.b}
+
+			parts := seg.split()
+			if len(parts) != 2 {
+				t.Fatalf("частей %d, ожидалось 2", len(parts))
+			}
+
+			mid := from.Add(time.Duration(c.fraction * float64(crossoverStep)))
+			if !parts[0].to.Equal(mid) || !parts[1].from.Equal(mid) {
+				t.Errorf("момент пересечения меридиана %s/%s, ожидался %s", parts[0].to, parts[1].from, mid)
+			}
+
+			if parts[0].b.Lon != c.edge || parts[1].a.Lon != -c.edge {
+				t.Errorf("долготы на меридиане %g/%g, ожидались %g/%g", parts[0].b.Lon, parts[1].a.Lon, c.edge, -c.edge)
+			}
+
+			if math.Abs(parts[0].b.Lat-c.lat) > 1e-9 || math.Abs(parts[1].a.Lat-c.lat) > 1e-9 {
+				t.Errorf("широта на меридиане %g/%g, ожидалась %g", parts[0].b.Lat, parts[1].a.Lat, c.lat)
+			}
+		})
+	}
+
+	seg := crossoverSegment{from: from, to: from.Add(crossoverStep), a: GeoPoint{Lat: 0, Lon: 170}, b: GeoPoint{Lat: 1, Lon: 172}}
+	if parts := seg.split(); len(parts) != 1 || parts[0] != seg {
+		t.Errorf("отрезок без пересечения меридиана разделен: %+v", parts)
+	}
+}
+
+func TestCrossoversAntimeridian(t *testing.T) {
+	sat := New(fitLine1, fitLine2)
+
+	from := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	region := []GeoPoint{{Lat: -50, Lon: 178}, {Lat: -50, Lon: 180}, {Lat: 50, Lon: 180}, {Lat: 50, Lon: 178}}
+
+	crossovers, err := sat.Crossovers(from, from.Add(72*time.Hour), region)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// отрезок трассы с шагом crossoverStep, на котором лежит момент t, пересекает меридиан 180°
+	crossesAntimeridian := func(t time.Time) bool {
+		a := from.Add(t.Sub(from).Truncate(crossoverStep))
+		ca, errA := sat.Calculate(a)
+		cb, errB := sat.Calculate(a.Add(crossoverStep))
+		return errA == nil && errB == nil && math.Abs(cb.Lon-ca.Lon) > 180
+	}
+
+	split := 0
+	for _, c := range crossovers {
+		asc, err := sat.Calculate(c.AscendingTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := sat.Calculate(c.DescendingTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if math.Abs(asc.Lat-desc.Lat) > 1e-3 || math.Abs(asc.Lon-desc.Lon) > 1e-3 {
+			t.Errorf("витки в пересечении %.4f, %.4f расходятся: %.4f, %.4f и %.4f, %.4f",
+				c.Lat, c.Lon, asc.Lat, asc.Lon, desc.Lat, desc.Lon)
+		}
+
+		if crossesAntimeridian(c.AscendingTime) || crossesAntimeridian(c.DescendingTime) {
+			split++
+		}
+	}
+
+	if split == 0 {
+		t.Errorf("из %d пересечений ни одно не лежит на отрезке трассы через меридиан 180°", len(crossovers))
+	}
+}
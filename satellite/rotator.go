@@ -0,0 +1,318 @@
+package satellite
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Режимы механики поворотного устройства (ротатора)
+const (
+	// обычный ротатор: азимут 0..360, угол места 0..90
+	RotatorModeNormal RotatorMode = "normal"
+	// ротатор с расширенным азимутом 0..450 (перекрытие через север)
+	RotatorModeAz450 RotatorMode = "az450"
+	// flip-ротатор: угол места 0..180, антенна может "перекинуться" через зенит
+	RotatorModeEl180 RotatorMode = "el180"
+)
+
+const (
+	// шаг таблицы наведения по умолчанию
+	defaultTrackingStep = time.Second
+	// допустимый выход угла места за пределы ротатора без пометки "ограничено", град
+	rotatorElTolerance = 0.5
+	// штраф за "перевернутое" положение антенны при выборе траектории, град
+	flipPenalty = 0.01
+)
+
+type RotatorMode string
+
+// RotatorProfile описывает механические ограничения ротатора.
+// Скорости поворота задаются в градусах в секунду, 0 - без ограничения.
+type RotatorProfile struct {
+	Mode       RotatorMode `json:"mode"`
+	MinAz      float64     `json:"minAz"`
+	MaxAz      float64     `json:"maxAz"`
+	MinEl      float64     `json:"minEl"`
+	MaxEl      float64     `json:"maxEl"`
+	AzSlewRate float64     `json:"azSlewRate"`
+	ElSlewRate float64     `json:"elSlewRate"`
+}
+
+// RotatorPoint - одна строка таблицы наведения
+type RotatorPoint struct {
+	Time    time.Time `json:"time"`
+	Az      float64   `json:"az"`      // команда на ротатор по азимуту, град
+	El      float64   `json:"el"`      // команда на ротатор по углу места, град
+	SatAz   float64   `json:"satAz"`   // геометрический азимут спутника, град
+	SatEl   float64   `json:"satEl"`   // геометрический угол места спутника, град
+	Flipped bool      `json:"flipped"` // команда дана в "перевернутом" положении (el > 90)
+	Limited bool      `json:"limited"` // команда ограничена пределами или скоростью ротатора
+	Error   float64   `json:"error"`   // угол между направлением антенны и спутником, град
+}
+
+// TrackingTable - таблица наведения ротатора на один пролет
+type TrackingTable struct {
+	Pass   TimeRange      `json:"pass"`
+	Mode   RotatorMode    `json:"mode"`
+	Points []RotatorPoint `json:"points"`
+}
+
+// WithDefaults заполняет незаданные пределы значениями, типичными для режима ротатора
+func (p RotatorProfile) WithDefaults() RotatorProfile {
+	if p.Mode == "" {
+		p.Mode = RotatorModeNormal
+	}
+
+	if p.MinAz == 0 && p.MaxAz == 0 {
+		p.MaxAz = 360
+		if p.Mode == RotatorModeAz450 {
+			p.MaxAz = 450
+		}
+	}
+
+	if p.MinEl == 0 && p.MaxEl == 0 {
+		p.MaxEl = 90
+		if p.Mode == RotatorModeEl180 {
+			p.MaxEl = 180
+		}
+	}
+
+	return p
+}
+
+// Valid проверяет, что режим известен и пределы не противоречат друг другу
+func (p RotatorProfile) Valid() bool {
+	switch p.Mode {
+	case RotatorModeNormal, RotatorModeAz450, RotatorModeEl180:
+	default:
+		return false
+	}
+
+	return p.MinAz < p.MaxAz && p.MinEl < p.MaxEl && p.AzSlewRate >= 0 && p.ElSlewRate >= 0
+}
+
+// rotatorPosition - одно из возможных положений ротатора для заданного направления на спутник
+type rotatorPosition struct {
+	az, el  float64
+	flipped bool
+	limited bool
+}
+
+// TrackingTable строит таблицу наведения ротатора на пролет tr с шагом step.
+// Переход азимута через 0°/360° разворачивается так, чтобы ротатор не делал
+// полный оборот посреди пролета, если это позволяют его пределы (режим az450),
+// либо антенна перекидывается через зенит (режим el180).
+// Ограничения по скорости поворота моделируются: команда отстает от спутника,
+// а отставание возвращается в поле Error.
+func (s Satellite) TrackingTable(tr TimeRange, obsCoords ObserverCoords, step time.Duration, profile RotatorProfile) TrackingTable {
+	if step <= 0 {
+		step = defaultTrackingStep
+	}
+
+	profile = profile.WithDefaults()
+
+	var times []time.Time
+	for t := tr.From; t.Before(tr.To); t = t.Add(step) {
+		times = append(times, t)
+	}
+	times = append(times, tr.To)
+
+	angles := make([]LookAngles, len(times))
+	for i, t := range times {
+		angles[i] = s.LookAngles(t, obsCoords)
+	}
+
+	positions := planRotatorPositions(angles, profile)
+
+	points := make([]RotatorPoint, len(times))
+
+	cmdAz, cmdEl := positions[0].az, positions[0].el
+	for i, t := range times {
+		target := positions[i]
+		limited := target.limited
+
+		if i > 0 {
+			dt := times[i].Sub(times[i-1]).Seconds()
+
+			var azLimited, elLimited bool
+			cmdAz, azLimited = slew(cmdAz, target.az, profile.AzSlewRate*dt)
+			cmdEl, elLimited = slew(cmdEl, target.el, profile.ElSlewRate*dt)
+			limited = limited || azLimited || elLimited
+		}
+
+		points[i] = RotatorPoint{
+			Time:    t,
+			Az:      cmdAz,
+			El:      cmdEl,
+			SatAz:   angles[i].Az,
+			SatEl:   angles[i].El,
+			Flipped: cmdEl > 90,
+			Limited: limited,
+			Error:   PointingError(cmdAz, cmdEl, angles[i].Az, angles[i].El),
+		}
+	}
+
+	return TrackingTable{
+		Pass:   tr,
+		Mode:   profile.Mode,
+		Points: points,
+	}
+}
+
+// RotatorPosition переводит текущее направление на спутник la в команду ротатора на момент t.
+// Выбирается то же положение (сдвиг азимута на 360°, переворот через зенит), что и у ближайшей
+// по времени точки таблицы, чтобы при живом сопровождении ротатор шел по спланированной траектории.
+func (tt TrackingTable) RotatorPosition(t time.Time, la LookAngles, profile RotatorProfile) (float64, float64) {
+	candidates := rotatorCandidates(la, profile.WithDefaults())
+	if len(tt.Points) == 0 {
+		return candidates[0].az, candidates[0].el
+	}
+
+	i := sort.Search(len(tt.Points), func(i int) bool {
+		return !tt.Points[i].Time.Before(t)
+	})
+	if i == len(tt.Points) || (i > 0 && t.Sub(tt.Points[i-1].Time) < tt.Points[i].Time.Sub(t)) {
+		i--
+	}
+	planned := tt.Points[i]
+
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if math.Abs(c.az-planned.Az)+math.Abs(c.el-planned.El) < math.Abs(best.az-planned.Az)+math.Abs(best.el-planned.El) {
+			best = c
+		}
+	}
+
+	return best.az, best.el
+}
+
+// planRotatorPositions выбирает для каждой точки пролета одно из эквивалентных положений
+// ротатора (сдвиг азимута на 360°, переворот через зенит) так, чтобы суммарное
+// перемещение антенны было минимальным. Используется динамическое программирование.
+func planRotatorPositions(angles []LookAngles, profile RotatorProfile) []rotatorPosition {
+	candidates := make([][]rotatorPosition, len(angles))
+	for i, la := range angles {
+		candidates[i] = rotatorCandidates(la, profile)
+	}
+
+	cost := make([][]float64, len(angles))
+	prev := make([][]int, len(angles))
+
+	cost[0] = make([]float64, len(candidates[0]))
+	prev[0] = make([]int, len(candidates[0]))
+	for j, cur := range candidates[0] {
+		if cur.flipped {
+			cost[0][j] = flipPenalty
+		}
+	}
+
+	for i := 1; i < len(angles); i++ {
+		cost[i] = make([]float64, len(candidates[i]))
+		prev[i] = make([]int, len(candidates[i]))
+
+		for j, cur := range candidates[i] {
+			best := math.Inf(1)
+
+			for k, p := range candidates[i-1] {
+				c := cost[i-1][k] + math.Abs(cur.az-p.az) + math.Abs(cur.el-p.el)
+				if cur.flipped {
+					// при равном перемещении предпочитаем обычное положение антенны
+					c += flipPenalty
+				}
+				if c < best {
+					best = c
+					prev[i][j] = k
+				}
+			}
+
+			cost[i][j] = best
+		}
+	}
+
+	last := len(angles) - 1
+	bestIdx := 0
+	for j := range cost[last] {
+		if cost[last][j] < cost[last][bestIdx] {
+			bestIdx = j
+		}
+	}
+
+	res := make([]rotatorPosition, len(angles))
+	for i := last; i >= 0; i-- {
+		res[i] = candidates[i][bestIdx]
+		bestIdx = prev[i][bestIdx]
+	}
+
+	return res
+}
+
+// rotatorCandidates возвращает все допустимые по азимуту положения ротатора для направления la.
+// Угол места прижимается к пределам ротатора (в точках восхода и захода он может быть
+// чуть ниже горизонта). Если ни одно положение не попадает в пределы по азимуту,
+// возвращается ближайшее ограниченное.
+func rotatorCandidates(la LookAngles, profile RotatorProfile) []rotatorPosition {
+	var res []rotatorPosition
+
+	add := func(az, el float64, flipped bool) {
+		clamped := math.Min(math.Max(el, profile.MinEl), profile.MaxEl)
+		// восход/заход находятся с точностью до секунды, небольшой выход за горизонт - не ограничение
+		limited := math.Abs(clamped-el) > rotatorElTolerance
+
+		for k := -2; k <= 2; k++ {
+			shifted := az + float64(k)*360
+			if shifted >= profile.MinAz && shifted <= profile.MaxAz {
+				res = append(res, rotatorPosition{az: shifted, el: clamped, flipped: flipped, limited: limited})
+			}
+		}
+	}
+
+	add(la.Az, la.El, false)
+	if profile.Mode == RotatorModeEl180 {
+		add(math.Mod(la.Az+180, 360), 180-la.El, true)
+	}
+
+	if len(res) > 0 {
+		return res
+	}
+
+	az := math.Mod(la.Az, 360)
+	for az < profile.MinAz {
+		az += 360
+	}
+	if az > profile.MaxAz {
+		az = profile.MaxAz
+	}
+
+	return []rotatorPosition{{
+		az:      az,
+		el:      math.Min(math.Max(la.El, profile.MinEl), profile.MaxEl),
+		limited: true,
+	}}
+}
+
+// slew двигает текущее положение к целевому не более чем на maxStep градусов.
+// maxStep <= 0 означает отсутствие ограничения.
+func slew(current, target, maxStep float64) (float64, bool) {
+	diff := target - current
+	if maxStep <= 0 || math.Abs(diff) <= maxStep {
+		return target, false
+	}
+
+	return current + math.Copysign(maxStep, diff), true
+}
+
+// PointingError возвращает угол между направлением антенны (с учетом переворота) и спутником, град
+func PointingError(cmdAz, cmdEl, satAz, satEl float64) float64 {
+	if cmdEl > 90 {
+		cmdAz += 180
+		cmdEl = 180 - cmdEl
+	}
+
+	a1, e1 := cmdAz*math.Pi/180, cmdEl*math.Pi/180
+	a2, e2 := satAz*math.Pi/180, satEl*math.Pi/180
+
+	cos := math.Sin(e1)*math.Sin(e2) + math.Cos(e1)*math.Cos(e2)*math.Cos(a1-a2)
+
+	return math.Acos(math.Max(-1, math.Min(1, cos))) * 180 / math.Pi
+}
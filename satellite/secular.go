@@ -0,0 +1,70 @@
+package satellite
+
+import (
+	"math"
+	"time"
+)
+
+// параметры гравитационного поля WGS-84 для аналитических оценок и численного пропагатора
+const (
+	equatorialRadiusKm = 6378.137
+	earthMu            = 398600.5 // км³/с²
+	earthJ2            = 0.00108262998905
+)
+
+// SemiMajorAxis возвращает большую полуось орбиты по среднему движению, км
+func (e Elements) SemiMajorAxis() float64 {
+	n := e.MeanMotion * 2 * math.Pi / 86400 // рад/с
+
+	return math.Cbrt(earthMu / (n * n))
+}
+
+// Period возвращает период обращения
+func (e Elements) Period() time.Duration {
+	return time.Duration(float64(24*time.Hour) / e.MeanMotion)
+}
+
+// RAANRate возвращает вековой дрейф долготы восходящего узла из-за сжатия Земли (J2), град/сут
+func (e Elements) RAANRate() float64 {
+	a := e.SemiMajorAxis()
+	p := a * (1 - e.Eccentricity*e.Eccentricity)
+	n := e.MeanMotion * 360 // град/сут
+
+	return -1.5 * n * earthJ2 * (equatorialRadiusKm / p) * (equatorialRadiusKm / p) * math.Cos(e.Inclination*math.Pi/180)
+}
+
+// RAANAt возвращает долготу восходящего узла на момент t с учетом векового дрейфа J2, град [0, 360)
+func (e Elements) RAANAt(t time.Time) float64 {
+	days := t.Sub(e.Epoch).Hours() / 24
+
+	return normalizeDegrees(e.RAAN + e.RAANRate()*days)
+}
+
+// RevNumber возвращает номер витка на момент t. Как и в TLE, новый виток начинается в восходящем узле:
+// номер на эпоху увеличивается, когда аргумент широты проходит через 0. Средняя аномалия восходящего
+// узла находится из уравнения Кеплера, узловой период учитывает дрейф перигея и торможение.
+func (e Elements) RevNumber(t time.Time) int {
+	days := t.Sub(e.Epoch).Hours() / 24
+
+	// истинная аномалия восходящего узла -ω переводится в среднюю
+	nu := -e.ArgOfPericenter * math.Pi / 180
+	ecc := 2 * math.Atan2(math.Sqrt(1-e.Eccentricity)*math.Sin(nu/2), math.Sqrt(1+e.Eccentricity)*math.Cos(nu/2))
+	nodeMeanAnomaly := (ecc - e.Eccentricity*math.Sin(ecc)) * 180 / math.Pi
+
+	// доля витка, пройденная от восходящего узла к эпохе
+	phase := normalizeDegrees(e.MeanAnomaly-nodeMeanAnomaly) / 360
+
+	revs := phase + (e.MeanMotion+e.ArgOfPerigeeRate()/360)*days + e.MeanMotionDot*days*days
+
+	return e.RevAtEpoch + int(math.Floor(revs))
+}
+
+// RevNumber возвращает номер витка по TLE спутника; false, если TLE не разбирается
+func (s Satellite) RevNumber(t time.Time) (int, bool) {
+	elements, err := ParseTLE(s.line1, s.line2)
+	if err != nil || elements.MeanMotion <= 0 {
+		return 0, false
+	}
+
+	return elements.RevNumber(t), true
+}
@@ -0,0 +1,49 @@
+package satellite
+
+import (
+	"math"
+	"time"
+)
+
+// астрономическая единица, км
+const astronomicalUnitKm = 149597870.7
+
+// julianDate возвращает юлианскую дату момента t (шкала UTC)
+func julianDate(t time.Time) float64 {
+	return float64(t.UnixNano())/float64(24*time.Hour) + 2440587.5
+}
+
+// SunPosition возвращает положение Солнца в инерциальной системе (экватор и равноденствие даты), км.
+// Упрощенная модель из Astronomical Almanac, точность около 0.01°.
+func SunPosition(t time.Time) (float64, float64, float64) {
+	// юлианские столетия от J2000
+	T := (julianDate(t) - 2451545.0) / 36525
+
+	meanLon := 280.460 + 36000.771*T
+	meanAnomaly := (357.5291092 + 35999.05034*T) * math.Pi / 180
+
+	eclipticLon := (meanLon + 1.914666471*math.Sin(meanAnomaly) + 0.019994643*math.Sin(2*meanAnomaly)) * math.Pi / 180
+	obliquity := (23.439291 - 0.0130042*T) * math.Pi / 180
+
+	r := (1.000140612 - 0.016708617*math.Cos(meanAnomaly) - 0.000139589*math.Cos(2*meanAnomaly)) * astronomicalUnitKm
+
+	sinLon, cosLon := math.Sincos(eclipticLon)
+	sinEps, cosEps := math.Sincos(obliquity)
+
+	return r * cosLon, r * cosEps * sinLon, r * sinEps * sinLon
+}
+
+// greenwichSiderealTime возвращает среднее гринвичское звездное время (IAU 1982), рад
+func greenwichSiderealTime(t time.Time) float64 {
+	T := (julianDate(t) - 2451545.0) / 36525
+
+	// секунды звездного времени
+	sec := -6.2e-6*T*T*T + 0.093104*T*T + (876600.0*3600+8640184.812866)*T + 67310.54841
+
+	gst := math.Mod(sec*math.Pi/180/240, 2*math.Pi)
+	if gst < 0 {
+		gst += 2 * math.Pi
+	}
+
+	return gst
+}
@@ -0,0 +1,411 @@
+package satellite
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// Светила, по диску которых ищутся прохождения
+const (
+	TransitSun  TransitBody = "sun"
+	TransitMoon TransitBody = "moon"
+)
+
+const (
+	// радиус Солнца, км
+	sunRadiusKm = 695700.0
+
+	// шаг поиска прохождений: тень спутника бежит по Земле со скоростью порядка 10 км/с
+	transitScanStep = time.Second
+	// насколько окно поиска шире пролета над местом: спутник может быть виден из точек в радиусе поиска
+	// раньше и позже, чем из самого места
+	transitPassMargin = 2 * time.Minute
+	// точность моментов прохождения
+	transitPrecision = time.Millisecond
+	// наибольшая длительность прохождения (медленные высокие спутники)
+	maxTransitDuration = time.Hour
+	// наибольшее количество точек центральной линии одного прохождения и шаг между ними
+	maxCenterlinePoints = 200
+	minCenterlineStep   = 500 * time.Millisecond
+	// смещение поперек центральной линии, по которому оценивается ширина полосы, км
+	transitWidthProbe = 1.0
+)
+
+type TransitBody string
+
+// CenterlinePoint - точка центральной линии: отсюда спутник проходит через центр диска
+type CenterlinePoint struct {
+	Time time.Time `json:"time"`
+	Lat  float64   `json:"lat"`
+	Lon  float64   `json:"lon"`
+}
+
+// LocalTransit - прохождение, видимое из самого места наблюдения
+type LocalTransit struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Duration float64   `json:"duration"` // с
+	// наименьшее угловое расстояние спутника от центра диска, угл. с
+	MinSeparation float64 `json:"minSeparation"`
+}
+
+// Transit - прохождение спутника по диску Солнца или Луны рядом с местом наблюдения
+type Transit struct {
+	Body TransitBody `json:"body"`
+	// момент, когда центральная линия ближе всего к месту наблюдения
+	Time time.Time `json:"time"`
+	// ближайшая к месту точка центральной линии и расстояние до нее, км
+	ClosestPoint GeoPoint `json:"closestPoint"`
+	Distance     float64  `json:"distance"`
+	// направление на спутник и светило из ClosestPoint (без рефракции: она одинаково сдвигает оба), град
+	Az float64 `json:"az"`
+	El float64 `json:"el"`
+	// расстояние до спутника из ClosestPoint, км
+	Range float64 `json:"range"`
+	// полуширина полосы, из которой прохождение видно, поперек центральной линии, км
+	PathHalfWidth float64 `json:"pathHalfWidth"`
+	// длительность прохождения на центральной линии, с
+	Duration float64 `json:"duration"`
+	// угловой размер спутника и диаметр диска светила, угл. с
+	SatelliteAngularSize float64 `json:"satelliteAngularSize"`
+	BodyAngularDiameter  float64 `json:"bodyAngularDiameter"`
+	// прохождение из самого места наблюдения; nil, если место вне полосы
+	Local      *LocalTransit     `json:"local"`
+	Centerline []CenterlinePoint `json:"centerline"`
+}
+
+// Transits ищет прохождения спутника по дискам светил bodies от from до to, центральная линия которых
+// проходит не дальше radius (км) от места наблюдения obsCoords. size - размер спутника, м.
+func (s Satellite) Transits(from, to time.Time, obsCoords ObserverCoords, radius, size float64, bodies []TransitBody) ([]Transit, error) {
+	if !to.After(from) {
+		return nil, errors.New("конец интервала должен быть позже начала")
+	}
+	if radius <= 0 {
+		return nil, errors.New("радиус поиска должен быть положительным")
+	}
+	for _, b := range bodies {
+		if b != TransitSun && b != TransitMoon {
+			return nil, errors.New("неизвестное светило: " + string(b))
+		}
+	}
+
+	site := geodeticToECEF(obsCoords.Lat, obsCoords.Lon, obsCoords.Alt)
+
+	res := []Transit{}
+
+	for _, tr := range s.PassesBetween(from.Add(-transitPassMargin), to.Add(transitPassMargin), obsCoords) {
+		winFrom := maxTime(tr.From.Add(-transitPassMargin), from)
+		winTo := minTime(tr.To.Add(transitPassMargin), to)
+		if !winTo.After(winFrom) {
+			continue
+		}
+
+		for _, body := range bodies {
+			res = append(res, s.passTransits(body, winFrom, winTo, site, radius, size)...)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Time.Before(res[j].Time) })
+
+	return res, nil
+}
+
+// passTransits ищет прохождения по диску body внутри одного пролета: местные минимумы расстояния
+// от центральной линии до места наблюдения, не превышающие radius
+func (s Satellite) passTransits(body TransitBody, from, to time.Time, site vector, radius, size float64) []Transit {
+	distance := func(t time.Time) float64 {
+		g, ok := s.transitCenter(body, t)
+		if !ok {
+			return math.Inf(1)
+		}
+
+		return g.sub(site).norm()
+	}
+
+	type sample struct {
+		t time.Time
+		d float64
+	}
+
+	var samples []sample
+	for t := from; !t.After(to); t = t.Add(transitScanStep) {
+		samples = append(samples, sample{t, distance(t)})
+	}
+
+	var res []Transit
+
+	for i := 1; i+1 < len(samples); i++ {
+		prev, curr, next := samples[i-1].d, samples[i].d, samples[i+1].d
+		if math.IsInf(curr, 1) || curr > prev || curr >= next {
+			continue
+		}
+
+		// между отсчетами расстояние могло опуститься ниже радиуса, даже если сами отсчеты выше
+		margin := math.Max(prev-curr, next-curr)
+		if math.IsInf(margin, 1) {
+			margin = 0
+		}
+		if curr-margin > radius {
+			continue
+		}
+
+		tc := ternaryMin(distance, samples[i-1].t, samples[i+1].t)
+		if d := distance(tc); d <= radius {
+			res = append(res, s.transit(body, tc, d, site, radius, size, distance))
+		}
+	}
+
+	return res
+}
+
+// transit описывает прохождение, центральная линия которого ближе всего к месту в момент tc
+func (s Satellite) transit(body TransitBody, tc time.Time, d float64, site vector, radius, size float64, distance func(time.Time) float64) Transit {
+	g, _ := s.transitCenter(body, tc)
+	lat, lon, _ := ecefToGeodetic(g)
+
+	res := Transit{
+		Body:         body,
+		Time:         tc,
+		ClosestPoint: GeoPoint{Lat: lat, Lon: lon},
+		Distance:     d,
+	}
+
+	sv, err := s.StateVector(tc)
+	if err != nil {
+		return res
+	}
+
+	gst := greenwichSiderealTime(tc)
+	la := sv.LookAngles(ObserverCoords{Lat: lat, Lon: lon}, Refraction{})
+
+	bodyPos := transitBodyPosition(body, tc)
+	alpha := math.Asin(transitBodyRadius(body) / temeToECEF(bodyPos, gst).sub(g).norm())
+
+	res.Az = la.Az
+	res.El = la.El
+	res.Range = la.Range
+	res.SatelliteAngularSize = size / 1000 / la.Range * 180 / math.Pi * 3600
+	res.BodyAngularDiameter = 2 * alpha * 180 / math.Pi * 3600
+
+	// окно, в котором центральная линия не дальше радиуса от места
+	winFrom := expandWhile(tc, -1, func(t time.Time) bool { return distance(t) <= radius })
+	winTo := expandWhile(tc, 1, func(t time.Time) bool { return distance(t) <= radius })
+
+	if from, to, _, ok := s.transitInterval(body, g, winFrom, winTo); ok {
+		res.Duration = to.Sub(from).Seconds()
+	}
+
+	if from, to, minSep, ok := s.transitInterval(body, site, winFrom, winTo); ok {
+		res.Local = &LocalTransit{
+			From:          from,
+			To:            to,
+			Duration:      to.Sub(from).Seconds(),
+			MinSeparation: minSep * 180 / math.Pi * 3600,
+		}
+	}
+
+	// полуширина полосы: угловое расстояние растет линейно при смещении поперек центральной линии
+	prevG, ok1 := s.transitCenter(body, tc.Add(-100*time.Millisecond))
+	nextG, ok2 := s.transitCenter(body, tc.Add(100*time.Millisecond))
+	if ok1 && ok2 {
+		across := geodeticUp(lat, lon).cross(nextG.sub(prevG)).unit()
+		if sep, _, ok := s.transitSeparation(body, g.add(across.scale(transitWidthProbe)), tc); ok && sep > 0 {
+			res.PathHalfWidth = alpha / sep * transitWidthProbe
+		}
+	}
+
+	step := max(minCenterlineStep, winTo.Sub(winFrom)/maxCenterlinePoints)
+	for t := winFrom; !t.After(winTo); t = t.Add(step) {
+		p, ok := s.transitCenter(body, t)
+		if !ok {
+			continue
+		}
+
+		lat, lon, _ := ecefToGeodetic(p)
+		res.Centerline = append(res.Centerline, CenterlinePoint{Time: t, Lat: lat, Lon: lon})
+	}
+
+	return res
+}
+
+// transitCenter возвращает точку центральной линии на момент t в ECEF: пересечение с эллипсоидом
+// прямой от центра светила через спутник. false - прямая не попадает в Землю или Земля закрывает
+// светило от спутника.
+func (s Satellite) transitCenter(body TransitBody, t time.Time) (vector, bool) {
+	sv, err := s.StateVector(t)
+	if err != nil {
+		return vector{}, false
+	}
+
+	gst := greenwichSiderealTime(t)
+	sat := temeToECEF(vector{sv.X, sv.Y, sv.Z}, gst)
+	dir := sat.sub(temeToECEF(transitBodyPosition(body, t), gst)).unit()
+
+	if _, ok := ellipsoidIntersection(sat, dir.scale(-1)); ok {
+		return vector{}, false
+	}
+
+	k, ok := ellipsoidIntersection(sat, dir)
+	if !ok {
+		return vector{}, false
+	}
+
+	return sat.add(dir.scale(k)), true
+}
+
+// transitSeparation возвращает угловое расстояние между спутником и центром светила и угловой радиус
+// светила для точки p (ECEF) на момент t, рад. false - спутник не над горизонтом точки.
+func (s Satellite) transitSeparation(body TransitBody, p vector, t time.Time) (float64, float64, bool) {
+	sv, err := s.StateVector(t)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	gst := greenwichSiderealTime(t)
+	toSat := temeToECEF(vector{sv.X, sv.Y, sv.Z}, gst).sub(p)
+	toBody := temeToECEF(transitBodyPosition(body, t), gst).sub(p)
+
+	if toSat.dot(p) < 0 {
+		return 0, 0, false
+	}
+
+	return angleBetween(toSat, toBody), math.Asin(transitBodyRadius(body) / toBody.norm()), true
+}
+
+// transitInterval находит, когда из точки p спутник виден на диске светила: наименьшее угловое
+// расстояние ищется внутри [from, to], границы - от него в обе стороны
+func (s Satellite) transitInterval(body TransitBody, p vector, from, to time.Time) (time.Time, time.Time, float64, bool) {
+	sep := func(t time.Time) float64 {
+		v, _, ok := s.transitSeparation(body, p, t)
+		if !ok {
+			return math.Pi
+		}
+
+		return v
+	}
+
+	// без запаса поиск не найдет минимум, если место прошло до начала окна центральной линии
+	span := max(to.Sub(from), 2*transitScanStep)
+	tm := ternaryMin(sep, from.Add(-span), to.Add(span))
+
+	minSep, alpha, ok := s.transitSeparation(body, p, tm)
+	if !ok || minSep >= alpha {
+		return time.Time{}, time.Time{}, minSep, false
+	}
+
+	inside := func(t time.Time) bool {
+		v, a, ok := s.transitSeparation(body, p, t)
+		return ok && v < a
+	}
+
+	return expandWhile(tm, -1, inside), expandWhile(tm, 1, inside), minSep, true
+}
+
+// transitBodyPosition возвращает положение светила в TEME, км
+func transitBodyPosition(body TransitBody, t time.Time) vector {
+	if body == TransitMoon {
+		x, y, z := MoonPosition(t)
+		return vector{x, y, z}
+	}
+
+	x, y, z := SunPosition(t)
+	return vector{x, y, z}
+}
+
+func transitBodyRadius(body TransitBody) float64 {
+	if body == TransitMoon {
+		return moonRadiusKm
+	}
+
+	return sunRadiusKm
+}
+
+// ellipsoidIntersection возвращает расстояние по лучу от точки p (вне Земли) в направлении dir
+// (единичный вектор, ECEF) до эллипсоида WGS-84
+func ellipsoidIntersection(p, dir vector) (float64, bool) {
+	// сжимаем эллипсоид в сферу радиуса экватора
+	scale := 1 / (1 - wgs84Flattening)
+	q := vector{p[0], p[1], p[2] * scale}
+	d := vector{dir[0], dir[1], dir[2] * scale}
+
+	a := d.dot(d)
+	b := 2 * q.dot(d)
+	c := q.dot(q) - equatorialRadiusKm*equatorialRadiusKm
+
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		return 0, false
+	}
+
+	k := (-b - math.Sqrt(disc)) / (2 * a)
+	if k <= 0 {
+		return 0, false
+	}
+
+	return k, true
+}
+
+// ternaryMin ищет момент наименьшего значения f на [from, to] тернарным поиском
+func ternaryMin(f func(time.Time) float64, from, to time.Time) time.Time {
+	for to.Sub(from) > transitPrecision {
+		m1 := from.Add(to.Sub(from) / 3)
+		m2 := to.Add(-to.Sub(from) / 3)
+
+		if f(m1) < f(m2) {
+			to = m2
+		} else {
+			from = m1
+		}
+	}
+
+	return from.Add(to.Sub(from) / 2)
+}
+
+// expandWhile идет от t в направлении dir (±1) с удваивающимся шагом, пока выполняется cond,
+// и уточняет границу делением пополам. Не дальше maxTransitDuration от t.
+func expandWhile(t time.Time, dir int, cond func(time.Time) bool) time.Time {
+	inside := t
+	step := 50 * time.Millisecond
+
+	for {
+		next := inside.Add(time.Duration(dir) * step)
+		if absDuration(next.Sub(t)) > maxTransitDuration {
+			return inside
+		}
+		if !cond(next) {
+			// граница между inside и next
+			outside := next
+			for absDuration(outside.Sub(inside)) > transitPrecision {
+				mid := inside.Add(outside.Sub(inside) / 2)
+				if cond(mid) {
+					inside = mid
+				} else {
+					outside = mid
+				}
+			}
+
+			return inside
+		}
+
+		inside = next
+		step *= 2
+	}
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
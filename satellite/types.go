@@ -11,6 +11,16 @@ type Satellite struct {
 	line2 string
 
 	sat *satellite.Satellite
+	// эпоха TLE без дробной части секунды - от нее go-satellite отсчитывает время
+	epoch time.Time
+	// дробная часть секунды эпохи TLE, которую go-satellite отбрасывает
+	epochFraction time.Duration
+	// профиль расчета SGP4: гравитационная модель и режим
+	profile Profile
+	// пропагатор, если отличается от SGP4 (см. WithPropagator)
+	propagator Propagator
+
+	refraction Refraction
 }
 
 // Getter: Line1
@@ -31,9 +41,10 @@ type SatelliteCoords struct {
 }
 
 type LookAngles struct {
-	Az    float64 `json:"az"`
-	El    float64 `json:"el"`
-	Range float64 `json:"range"`
+	Az        float64 `json:"az"`
+	El        float64 `json:"el"`
+	Range     float64 `json:"range"`
+	RangeRate float64 `json:"rangeRate"` // скорость изменения дальности, км/с (> 0 - спутник удаляется)
 }
 
 type ObserverCoords struct {
@@ -46,4 +57,11 @@ type TimeRange struct {
 	From       time.Time `json:"from"`
 	To         time.Time `json:"to"`
 	Difference string    `json:"difference"`
+	// пролет уже шел в момент начала поиска (From - этот момент) или не закончился до конца поиска
+	// (To - конец поиска). Так бывает с долгими пролетами на высоких орбитах.
+	RisesBefore bool `json:"risesBefore,omitempty"`
+	SetsAfter   bool `json:"setsAfter,omitempty"`
+	// местные максимумы угла места; на высокоэллиптических орбитах их может быть несколько
+	Culminations []Culmination `json:"culminations,omitempty"`
+	Regime       *RegimeInfo   `json:"regime,omitempty"`
 }
@@ -0,0 +1,103 @@
+package satellite
+
+import "math"
+
+// vector - трехмерный вектор для геометрических расчетов
+type vector [3]float64
+
+func (a vector) add(b vector) vector {
+	return vector{a[0] + b[0], a[1] + b[1], a[2] + b[2]}
+}
+
+func (a vector) sub(b vector) vector {
+	return vector{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func (a vector) scale(k float64) vector {
+	return vector{a[0] * k, a[1] * k, a[2] * k}
+}
+
+func (a vector) dot(b vector) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func (a vector) cross(b vector) vector {
+	return vector{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func (a vector) norm() float64 {
+	return math.Sqrt(a.dot(a))
+}
+
+func (a vector) unit() vector {
+	n := a.norm()
+	if n == 0 {
+		return a
+	}
+
+	return a.scale(1 / n)
+}
+
+// angleBetween возвращает угол между векторами, рад
+func angleBetween(a, b vector) float64 {
+	return math.Atan2(a.cross(b).norm(), a.dot(b))
+}
+
+// параметры эллипсоида WGS-84
+const wgs84Flattening = 1 / 298.257223563
+
+// geodeticToECEF переводит геодезические координаты (град, км) в ECEF, км
+func geodeticToECEF(lat, lon, alt float64) vector {
+	sinLat, cosLat := math.Sincos(lat * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(lon * math.Pi / 180)
+
+	e2 := wgs84Flattening * (2 - wgs84Flattening)
+	n := equatorialRadiusKm / math.Sqrt(1-e2*sinLat*sinLat)
+
+	return vector{
+		(n + alt) * cosLat * cosLon,
+		(n + alt) * cosLat * sinLon,
+		(n*(1-e2) + alt) * sinLat,
+	}
+}
+
+// ecefToGeodetic переводит ECEF (км) в геодезические координаты (град, км) итерациями по широте
+func ecefToGeodetic(p vector) (lat, lon, alt float64) {
+	e2 := wgs84Flattening * (2 - wgs84Flattening)
+	rxy := math.Hypot(p[0], p[1])
+
+	phi := math.Atan2(p[2], rxy*(1-e2))
+	for i := 0; i < 5; i++ {
+		sinPhi, cosPhi := math.Sincos(phi)
+		n := equatorialRadiusKm / math.Sqrt(1-e2*sinPhi*sinPhi)
+
+		if math.Abs(cosPhi) > 1e-9 {
+			alt = rxy/cosPhi - n
+		} else {
+			alt = math.Abs(p[2]) - n*(1-e2)
+		}
+
+		phi = math.Atan2(p[2], rxy*(1-e2*n/(n+alt)))
+	}
+
+	return phi * 180 / math.Pi, math.Atan2(p[1], p[0]) * 180 / math.Pi, alt
+}
+
+// geodeticUp возвращает единичную нормаль к эллипсоиду в точке (град)
+func geodeticUp(lat, lon float64) vector {
+	sinLat, cosLat := math.Sincos(lat * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(lon * math.Pi / 180)
+
+	return vector{cosLat * cosLon, cosLat * sinLon, sinLat}
+}
+
+// temeToECEF поворачивает вектор из TEME в ECEF на звездное время момента t
+func temeToECEF(v vector, gst float64) vector {
+	sin, cos := math.Sincos(gst)
+
+	return vector{cos*v[0] + sin*v[1], -sin*v[0] + cos*v[1], v[2]}
+}
@@ -0,0 +1,281 @@
+package satellite
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/joshuaferrara/go-satellite"
+)
+
+// SGP4Case - контрольный вектор состояния из SGP4-VER (Vallado, tcppver.out, гравитационная модель WGS-72)
+type SGP4Case struct {
+	Line1    string
+	Line2    string
+	TSince   float64    // минуты от эпохи TLE
+	Position [3]float64 // TEME, км
+	Velocity [3]float64 // TEME, км/с
+}
+
+// PassCase - контрольный пролет над станцией без учета рефракции. Восход, заход, максимальный угол
+// места и азимуты восхода и захода первого пролета после From сверяются с ожидаемыми.
+//
+// Ожидаемые значения рассчитаны отдельной программой, не использующей код пакета: положения SGP4 в TEME
+// (сверены с SGP4-VER) с шагом 1 с переведены во вращающуюся систему по звездному времени IAU-82,
+// направление - в топоцентрическом базисе станции на эллипсоиде WGS-84; восход и заход найдены
+// перебором и бисекцией, кульминация - методом золотого сечения.
+type PassCase struct {
+	Name     string
+	Line1    string
+	Line2    string
+	Observer ObserverCoords
+	From     time.Time // момент, с которого ищется пролет
+
+	AOS   time.Time
+	LOS   time.Time
+	MaxEl float64 // град
+	AOSAz float64 // град
+	LOSAz float64 // град
+}
+
+// LLACase - контрольное преобразование положения в ECEF в геодезические координаты
+type LLACase struct {
+	Name     string
+	Position [3]float64 // ECEF, км
+	Lat      float64    // град
+	Lon      float64    // град
+	Alt      float64    // км
+}
+
+// Check - результат одной проверки
+type Check struct {
+	Name      string  `json:"name"`
+	Quantity  string  `json:"quantity"`
+	Unit      string  `json:"unit"`
+	Delta     float64 `json:"delta"`
+	Tolerance float64 `json:"tolerance"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Passed проверяет, что расхождение в пределах допуска
+func (c Check) Passed() bool {
+	return c.Error == "" && c.Delta <= c.Tolerance
+}
+
+// допуски проверок
+const (
+	sgp4PositionTolerance = 1e-4 // км
+	sgp4VelocityTolerance = 1e-7 // км/с
+	// точность поиска восхода и захода - секунда; за нее азимут у горизонта меняется меньше чем на 0.1°
+	passTimeTolerance      = 1.0  // с
+	passElevationTolerance = 0.01 // град
+	passAzimuthTolerance   = 0.1  // град
+	llaAngleTolerance      = 1e-4 // град
+	llaAltTolerance        = 0.01 // км, с такой точностью дан пример Vallado
+)
+
+const (
+	sgp4VerCase00005Line1 = "1 00005U 58002B   00179.78495062  .00000023  00000-0  28098-4 0  4753"
+	sgp4VerCase00005Line2 = "2 00005  34.2682 348.7242 1859667 331.7664  19.3264 10.82419157413667"
+	sgp4VerCase06251Line1 = "1 06251U 62025E   06176.82412014  .00008885  00000-0  12808-3 0  3985"
+	sgp4VerCase06251Line2 = "2 06251  58.0579  54.0425 0030035 139.1568 221.1854 15.56387291  6774"
+)
+
+// SGP4Cases - контрольные векторы SGP4-VER (околоземные орбиты): 00005 на все шаги до 4320 минут,
+// 06251 на все шаги до 2880 минут и начальный вектор 28057
+var SGP4Cases = []SGP4Case{
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 0, [3]float64{7022.46529266, -1400.08296755, 0.03995155}, [3]float64{1.893841015, 6.405893759, 4.534807250}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 360, [3]float64{-7154.03120202, -3783.17682504, -3536.19412294}, [3]float64{4.741887409, -4.151817765, -2.093935425}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 720, [3]float64{-7134.59340119, 6531.68641334, 3260.27186483}, [3]float64{-4.113793027, -2.911922039, -2.557327851}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 1080, [3]float64{5568.53901181, 4492.06992591, 3863.87641983}, [3]float64{-4.209106476, 5.159719888, 2.744852980}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 1440, [3]float64{-938.55923943, -6268.18748831, -4294.02924751}, [3]float64{7.536105209, -0.427127707, 0.989878080}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 1800, [3]float64{-9680.56121728, 2802.47771354, 124.10688038}, [3]float64{-0.905874102, -4.659467970, -3.227347517}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 2160, [3]float64{190.19796988, 7746.96653614, 5110.00675412}, [3]float64{-6.112325142, 1.527008184, -0.139152358}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 2520, [3]float64{5579.55640116, -3995.61396789, -1518.82108966}, [3]float64{4.767927483, 5.123185301, 4.276837355}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 2880, [3]float64{-8650.73082219, -1914.93811525, -3007.03603443}, [3]float64{3.067165127, -4.828384068, -2.515322836}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 3240, [3]float64{-5429.79204164, 7574.36493792, 3747.39305236}, [3]float64{-4.999442110, -1.800561422, -2.229392830}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 3600, [3]float64{6759.04583722, 2001.58198220, 2783.55192533}, [3]float64{-2.180993947, 6.402085603, 3.644723952}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 3960, [3]float64{-3791.44531559, -5712.95617894, -4533.48630714}, [3]float64{6.668817493, -2.516382327, -0.082384354}},
+	{sgp4VerCase00005Line1, sgp4VerCase00005Line2, 4320, [3]float64{-9060.47373569, 4658.70952502, 813.68673153}, [3]float64{-2.232832783, -4.110453490, -3.157345433}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 0, [3]float64{3988.31022699, 5498.96657235, 0.90055879}, [3]float64{-3.290032738, 2.357652820, 6.496623475}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 120, [3]float64{-3935.69800083, 409.10980837, 5471.33577327}, [3]float64{-3.374784183, -6.635211043, -1.942056221}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 240, [3]float64{-1675.12766915, -5683.30432352, -3286.21510937}, [3]float64{5.282496925, 1.508674259, -5.354872978}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 360, [3]float64{4993.62642836, 2890.54969900, -3600.40145627}, [3]float64{0.347333429, 5.707031557, 5.070699638}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 480, [3]float64{-1115.07959514, 4015.11691491, 5326.99727718}, [3]float64{-5.524279443, -4.765738774, 2.402255961}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 600, [3]float64{-4329.10008198, -5176.70287935, 409.65313857}, [3]float64{2.858408303, -2.933091792, -6.509690397}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 720, [3]float64{3692.60030028, -976.24265255, -5623.36447493}, [3]float64{3.897257243, 6.415554948, 1.429112190}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 840, [3]float64{2301.83510037, 5723.92394553, 2814.61514580}, [3]float64{-5.110924966, -0.764510559, 5.662120145}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 960, [3]float64{-4990.91637950, -2303.42547880, 3920.86335598}, [3]float64{-0.993439372, -5.967458360, -4.759110856}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 1080, [3]float64{642.27769977, -4332.89821901, -5183.31523910}, [3]float64{5.720542579, 4.216573838, -2.846576139}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 1200, [3]float64{4719.78335752, 4798.06938996, -943.58851062}, [3]float64{-2.294860662, 3.492499389, 6.408334723}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 1320, [3]float64{-3299.16993602, 1576.83168320, 5678.67840638}, [3]float64{-4.460347074, -6.202025196, -0.885874586}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 1440, [3]float64{-2777.14682335, -5663.16031708, -2462.54889123}, [3]float64{4.915493146, 0.123328992, -5.896495091}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 1560, [3]float64{4992.31573893, 1716.62356770, -4287.86065581}, [3]float64{1.640717189, 6.071570434, 4.338797931}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 1680, [3]float64{-8.22384755, 4662.21521668, 4905.66411857}, [3]float64{-5.891011274, -3.593173872, 3.365100460}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 1800, [3]float64{-4966.20137963, -4379.59155037, 1349.33347502}, [3]float64{1.763172581, -3.981456387, -6.343279443}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 1920, [3]float64{2954.49390331, -2080.65984650, -5754.75038057}, [3]float64{4.895893306, 5.858184322, 0.375474825}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 2040, [3]float64{3363.28794321, 5559.55841180, 1956.05542266}, [3]float64{-4.587378863, 0.591943403, 6.107838605}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 2160, [3]float64{-4856.66780070, -1107.03450192, 4557.21258241}, [3]float64{-2.304158557, -6.186437070, -3.956549542}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 2280, [3]float64{-497.84480071, -4863.46005312, -4700.81211217}, [3]float64{5.960065407, 2.996683369, -3.767123329}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 2400, [3]float64{5241.61936096, 3910.75960683, -1857.93473952}, [3]float64{-1.124834806, 4.406213160, 6.148161299}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 2520, [3]float64{-2451.38045953, 2610.60463261, 5729.79022069}, [3]float64{-5.366560525, -5.500855666, 0.187958716}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 2640, [3]float64{-3791.87520638, -5378.82851382, -1575.82737930}, [3]float64{4.266273592, -1.199162551, -6.276154080}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 2760, [3]float64{4730.53958356, 524.05006433, -4857.29369725}, [3]float64{2.918056288, 6.135412849, 3.495115636}},
+	{sgp4VerCase06251Line1, sgp4VerCase06251Line2, 2880, [3]float64{1159.27802897, 5056.60175495, 4353.49418579}, [3]float64{-5.968060341, -2.314790406, 4.230722669}},
+	{
+		"1 28057U 03049A   06177.78615833  .00000060  00000-0  35940-4 0  1836",
+		"2 28057  98.4283 247.6961 0000884  88.1964 271.9322 14.35478080140550",
+		0, [3]float64{-2715.28237486, -6619.26436889, -0.01341443}, [3]float64{-1.008587273, 0.422782003, 7.385272942},
+	},
+}
+
+// LLACases - контрольные преобразования координат
+var LLACases = []LLACase{
+	// Vallado, Fundamentals of Astrodynamics and Applications, пример 3-3
+	{"vallado-3-3", [3]float64{6524.834, 6862.875, 6448.296}, 34.352496, 46.4464, 5085.22},
+}
+
+const (
+	passCaseUmka1Line1 = "1 57172U 23091G   24263.53334166  .00009425  00000-0  59089-3 0  9999"
+	passCaseUmka1Line2 = "2 57172  97.6018 314.6827 0017222 154.9337 205.2732 15.09427738 67710"
+)
+
+// PassCases - контрольные пролеты: низкий, почти касательный к горизонту, через зенит и в высоких широтах
+var PassCases = []PassCase{
+	{
+		Name:     "umka-1-moscow-low",
+		Line1:    passCaseUmka1Line1,
+		Line2:    passCaseUmka1Line2,
+		Observer: ObserverCoords{Lat: 55.7558, Lon: 37.6173, Alt: 0.15},
+		From:     time.Date(2024, 9, 19, 14, 0, 0, 0, time.UTC),
+		AOS:      time.Date(2024, 9, 19, 14, 40, 8, 90e6, time.UTC),
+		LOS:      time.Date(2024, 9, 19, 14, 44, 42, 211e6, time.UTC),
+		MaxEl:    1.772,
+		AOSAz:    56.388,
+		LOSAz:    10.844,
+	},
+	{
+		Name:     "umka-1-moscow-zenith",
+		Line1:    passCaseUmka1Line1,
+		Line2:    passCaseUmka1Line2,
+		Observer: ObserverCoords{Lat: 55.7558, Lon: 37.6173, Alt: 0.15},
+		From:     time.Date(2024, 9, 19, 17, 30, 0, 0, time.UTC),
+		AOS:      time.Date(2024, 9, 19, 17, 43, 17, 846e6, time.UTC),
+		LOS:      time.Date(2024, 9, 19, 17, 55, 21, 971e6, time.UTC),
+		MaxEl:    87.041,
+		AOSAz:    164.153,
+		LOSAz:    345.542,
+	},
+	{
+		Name:     "umka-1-murmansk",
+		Line1:    passCaseUmka1Line1,
+		Line2:    passCaseUmka1Line2,
+		Observer: ObserverCoords{Lat: 68.9585, Lon: 33.0827, Alt: 0.05},
+		From:     time.Date(2024, 9, 20, 0, 0, 0, 0, time.UTC),
+		AOS:      time.Date(2024, 9, 20, 5, 7, 20, 30e6, time.UTC),
+		LOS:      time.Date(2024, 9, 20, 5, 16, 14, 227e6, time.UTC),
+		MaxEl:    8.442,
+		AOSAz:    21.598,
+		LOSAz:    119.164,
+	},
+}
+
+// Verify выполняет все контрольные проверки
+func Verify() []Check {
+	var res []Check
+
+	for _, c := range SGP4Cases {
+		res = append(res, c.Verify()...)
+	}
+
+	for _, c := range LLACases {
+		res = append(res, c.Verify()...)
+	}
+
+	for _, c := range PassCases {
+		res = append(res, c.Verify()...)
+	}
+
+	return res
+}
+
+// Verify сверяет вектор состояния, рассчитанный через Satellite.StateVector, с контрольным
+func (c SGP4Case) Verify() []Check {
+	name := fmt.Sprintf("sgp4-ver %s t=%g", c.Line1[2:7], c.TSince)
+
+	pos := Check{Name: name, Quantity: "position", Unit: "km", Tolerance: sgp4PositionTolerance}
+	vel := Check{Name: name, Quantity: "velocity", Unit: "km/s", Tolerance: sgp4VelocityTolerance}
+
+	elements, err := ParseTLE(c.Line1, c.Line2)
+	if err != nil {
+		pos.Error, vel.Error = err.Error(), err.Error()
+		return []Check{pos, vel}
+	}
+
+	sat, err := NewWithProfile(c.Line1, c.Line2, Profile{Gravity: GravityWGS72, OpsMode: OpsModeImproved})
+	if err != nil {
+		pos.Error, vel.Error = err.Error(), err.Error()
+		return []Check{pos, vel}
+	}
+
+	sv, err := sat.StateVector(elements.Epoch.Add(time.Duration(c.TSince * float64(time.Minute))))
+	if err != nil {
+		pos.Error, vel.Error = err.Error(), err.Error()
+		return []Check{pos, vel}
+	}
+
+	pos.Delta = vector{sv.X, sv.Y, sv.Z}.sub(c.Position).norm()
+	vel.Delta = vector{sv.VX, sv.VY, sv.VZ}.sub(c.Velocity).norm()
+
+	return []Check{pos, vel}
+}
+
+// Verify сверяет геодезические координаты, рассчитанные как в Calculate, с контрольными
+func (c LLACase) Verify() []Check {
+	coords := coordsFromECI(satellite.Vector3{X: c.Position[0], Y: c.Position[1], Z: c.Position[2]}, 0)
+
+	return []Check{
+		{Name: c.Name, Quantity: "lat", Unit: "deg", Delta: math.Abs(coords.Lat - c.Lat), Tolerance: llaAngleTolerance},
+		{Name: c.Name, Quantity: "lon", Unit: "deg", Delta: math.Abs(coords.Lon - c.Lon), Tolerance: llaAngleTolerance},
+		{Name: c.Name, Quantity: "alt", Unit: "km", Delta: math.Abs(coords.Alt - c.Alt), Tolerance: llaAltTolerance},
+	}
+}
+
+// Verify сверяет первый пролет после From, найденный VisibleTimeRange и PassDetails, с ожидаемым
+func (c PassCase) Verify() []Check {
+	rise := Check{Name: c.Name, Quantity: "rise", Unit: "s", Tolerance: passTimeTolerance}
+	set := Check{Name: c.Name, Quantity: "set", Unit: "s", Tolerance: passTimeTolerance}
+	maxEl := Check{Name: c.Name, Quantity: "max elevation", Unit: "deg", Tolerance: passElevationTolerance}
+	aosAz := Check{Name: c.Name, Quantity: "rise azimuth", Unit: "deg", Tolerance: passAzimuthTolerance}
+	losAz := Check{Name: c.Name, Quantity: "set azimuth", Unit: "deg", Tolerance: passAzimuthTolerance}
+
+	// ожидаемые значения рассчитаны по положениям SGP4 с константами WGS-72
+	sat, err := NewWithProfile(c.Line1, c.Line2, Profile{Gravity: GravityWGS72, OpsMode: OpsModeImproved})
+	if err != nil {
+		for _, check := range []*Check{&rise, &set, &maxEl, &aosAz, &losAz} {
+			check.Error = err.Error()
+		}
+
+		return []Check{rise, set, maxEl, aosAz, losAz}
+	}
+
+	ranges := sat.VisibleTimeRange(c.From, c.Observer, 1)
+	if len(ranges) == 0 {
+		msg := fmt.Sprintf("пролет после %s не найден", c.From.Format(time.RFC3339))
+		for _, check := range []*Check{&rise, &set, &maxEl, &aosAz, &losAz} {
+			check.Error = msg
+		}
+
+		return []Check{rise, set, maxEl, aosAz, losAz}
+	}
+
+	pass := sat.PassDetails(ranges[0], c.Observer)
+
+	rise.Delta = math.Abs(pass.From.Sub(c.AOS).Seconds())
+	set.Delta = math.Abs(pass.To.Sub(c.LOS).Seconds())
+	maxEl.Delta = math.Abs(pass.MaxEl - c.MaxEl)
+	aosAz.Delta = math.Abs(math.Remainder(pass.AOSAz-c.AOSAz, 360))
+	losAz.Delta = math.Abs(math.Remainder(pass.LOSAz-c.LOSAz, 360))
+
+	return []Check{rise, set, maxEl, aosAz, losAz}
+}
@@ -0,0 +1,13 @@
+package satellite
+
+import "testing"
+
+func TestVerify(t *testing.T) {
+	for _, c := range Verify() {
+		t.Run(c.Name+" "+c.Quantity, func(t *testing.T) {
+			if !c.Passed() {
+				t.Errorf("расхождение %g %s, допуск %g %s %s", c.Delta, c.Unit, c.Tolerance, c.Unit, c.Error)
+			}
+		})
+	}
+}